@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// LatestStore keeps the latest reading of every location, so the dashboard
+// does not have to query the database for every tile.
+type LatestStore interface {
+	// Get reports false when the location is not cached or the entry expired.
+	Get(ctx context.Context, locationId uint) (models.Co2Data, bool, error)
+	Set(ctx context.Context, co2Data models.Co2Data) error
+	Delete(ctx context.Context, locationId uint) error
+	Close() error
+}
+
+var latest LatestStore
+
+var errNoLocation = errors.New("co2 data has no location")
+
+// Setup creates the global latest reading store of the configured backend.
+// With the backend none no store is created and the readings are always
+// read from the database.
+func Setup(cacheConfig config.CacheConfig) error {
+	switch cacheConfig.Backend {
+	case config.CacheNone:
+		latest = nil
+	case config.CacheMemory:
+		latest = NewMemoryLatestStore(cacheConfig.TTL)
+	case config.CacheRedis:
+		options, err := redis.ParseURL(cacheConfig.RedisURL)
+		if err != nil {
+			return fmt.Errorf("invalid redis url: %w", err)
+		}
+		client := redis.NewClient(options)
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			client.Close()
+			return fmt.Errorf("could not connect to redis: %w", err)
+		}
+		latest = NewRedisLatestStore(client, cacheConfig.TTL)
+	default:
+		return fmt.Errorf("unknown cache backend %q", cacheConfig.Backend)
+	}
+
+	log.Infof(`Caching latest readings. Backend: <%s> TTL: <%s>`, cacheConfig.Backend, cacheConfig.TTL)
+
+	return nil
+}
+
+func GetLatestStore() LatestStore {
+	return latest
+}
+
+// Close releases the connection of the global store.
+func Close(ctx context.Context) error {
+	if latest == nil {
+		return nil
+	}
+
+	return latest.Close()
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+type memoryEntry struct {
+	co2Data   models.Co2Data
+	expiresAt time.Time
+}
+
+// MemoryLatestStore keeps the latest readings in the process. Every instance
+// of the api has its own copy, use the redis store when running several.
+type MemoryLatestStore struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[uint]memoryEntry
+	now     func() time.Time
+}
+
+// NewMemoryLatestStore creates a store whose entries expire after ttl. A ttl
+// of zero keeps the entries until they are replaced or deleted.
+func NewMemoryLatestStore(ttl time.Duration) *MemoryLatestStore {
+	return &MemoryLatestStore{
+		ttl:     ttl,
+		entries: map[uint]memoryEntry{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryLatestStore) Get(ctx context.Context, locationId uint) (models.Co2Data, bool, error) {
+	s.mu.RLock()
+	entry, ok := s.entries[locationId]
+	s.mu.RUnlock()
+
+	if !ok || s.expired(entry) {
+		return models.Co2Data{}, false, nil
+	}
+
+	return entry.co2Data, true, nil
+}
+
+func (s *MemoryLatestStore) Set(ctx context.Context, co2Data models.Co2Data) error {
+	if co2Data.LocationID <= 0 {
+		return errNoLocation
+	}
+
+	entry := memoryEntry{co2Data: co2Data}
+	if s.ttl > 0 {
+		entry.expiresAt = s.now().Add(s.ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[uint(co2Data.LocationID)] = entry
+
+	return nil
+}
+
+func (s *MemoryLatestStore) Delete(ctx context.Context, locationId uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, locationId)
+
+	return nil
+}
+
+func (s *MemoryLatestStore) Close() error {
+	return nil
+}
+
+// SetClock replaces the clock used for the expiry, so tests do not have to sleep.
+func (s *MemoryLatestStore) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+func (s *MemoryLatestStore) expired(entry memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt)
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "co2monitor:latest:"
+
+// RedisLatestStore shares the latest readings between all instances of the
+// api. Any server speaking the redis protocol can be used.
+type RedisLatestStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisLatestStore creates a store whose entries expire after ttl. A ttl
+// of zero keeps the entries until they are replaced or deleted.
+func NewRedisLatestStore(client *redis.Client, ttl time.Duration) *RedisLatestStore {
+	return &RedisLatestStore{client: client, ttl: ttl}
+}
+
+func (s *RedisLatestStore) Get(ctx context.Context, locationId uint) (models.Co2Data, bool, error) {
+	value, err := s.client.Get(ctx, redisKey(locationId)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.Co2Data{}, false, nil
+	}
+	if err != nil {
+		return models.Co2Data{}, false, err
+	}
+
+	var co2Data models.Co2Data
+	if err := json.Unmarshal(value, &co2Data); err != nil {
+		return models.Co2Data{}, false, err
+	}
+
+	return co2Data, true, nil
+}
+
+func (s *RedisLatestStore) Set(ctx context.Context, co2Data models.Co2Data) error {
+	if co2Data.LocationID <= 0 {
+		return errNoLocation
+	}
+
+	value, err := json.Marshal(co2Data)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, redisKey(uint(co2Data.LocationID)), value, s.ttl).Err()
+}
+
+func (s *RedisLatestStore) Delete(ctx context.Context, locationId uint) error {
+	return s.client.Del(ctx, redisKey(locationId)).Err()
+}
+
+func (s *RedisLatestStore) Close() error {
+	return s.client.Close()
+}
+
+func redisKey(locationId uint) string {
+	return fmt.Sprintf("%s%d", redisKeyPrefix, locationId)
+}
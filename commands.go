@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/db"
+	"github.com/fminister/co2monitor.api/db/migrations"
+)
+
+const migrateUsage = "Usage: co2monitor.api migrate up|down [steps]|status [flags]"
+
+// printConfig implements the "config print" command, it prints the resolved
+// configuration with secrets redacted and fails if it is invalid.
+func printConfig(args []string) {
+	cfg, err := config.Load(args)
+	if cfg != nil {
+		config.Print(os.Stdout, cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%s\n", err)
+		os.Exit(1)
+	}
+}
+
+// migrate implements the "migrate up", "migrate down [steps]" and
+// "migrate status" commands. down reverts one migration by default.
+func migrate(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	action, args := args[0], args[1:]
+	steps := 1
+	if action == "down" && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed < 1 {
+			fmt.Fprintf(os.Stderr, "Steps have to be a positive number, got %q.\n", args[0])
+			os.Exit(2)
+		}
+		steps, args = parsed, args[1:]
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatal("Invalid configuration. \n", err)
+	}
+	config.Set(cfg)
+	db.ConnectToDb()
+	defer db.CloseDb()
+
+	switch action {
+	case "up":
+		migrateUp()
+	case "down":
+		migrator := newMigrator()
+		reverted, err := migrator.Down(steps)
+		if err != nil {
+			log.Fatal("Failed to revert migrations. \n", err)
+		}
+		log.Infof(`Reverted <%d> migrations.`, len(reverted))
+	case "status":
+		printMigrationStatus(newMigrator())
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
+
+func migrateUp() {
+	applied, err := newMigrator().Up()
+	if err != nil {
+		log.Fatal("Failed to apply migrations. \n", err)
+	}
+	log.Infof(`Database is up to date. Applied migrations: <%d>`, len(applied))
+}
+
+func newMigrator() *migrations.Migrator {
+	migrator, err := migrations.New(db.GetDB())
+	if err != nil {
+		log.Fatal("Failed to load migrations. \n", err)
+	}
+
+	return migrator
+}
+
+func printMigrationStatus(migrator *migrations.Migrator) {
+	status, err := migrator.Status()
+	if err != nil {
+		log.Fatal("Failed to read migration status. \n", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, entry := range status {
+		appliedAt := "pending"
+		if entry.Applied {
+			appliedAt = entry.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", entry.Version, entry.Name, appliedAt)
+	}
+	w.Flush()
+}
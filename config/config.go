@@ -0,0 +1,319 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Config struct {
+	App       AppConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Auth      AuthConfig
+	Heartbeat HeartbeatConfig
+	Anomaly   AnomalyConfig
+	Log       LogConfig
+	Cache     CacheConfig
+	Query     QueryConfig
+	Tracing   TracingConfig
+	RateLimit RateLimitConfig
+	Ingest    IngestConfig
+	Readings  ReadingsConfig
+}
+
+type AppConfig struct {
+	Env  string
+	Host string
+}
+
+type ServerConfig struct {
+	Addr              string
+	TLSCertFile       string
+	TLSKeyFile        string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	MaxHeaderBytes    int
+}
+
+type DatabaseConfig struct {
+	Driver      string
+	URL         string
+	DevURL      string
+	Schema      string
+	AutoMigrate bool
+	SqlitePath  string
+}
+
+type AuthConfig struct {
+	APIKey      string
+	AdminAPIKey string
+}
+
+type HeartbeatConfig struct {
+	SilenceWindow time.Duration
+	CheckInterval time.Duration
+}
+
+type AnomalyConfig struct {
+	OutdoorBaseline int
+}
+
+type LogConfig struct {
+	File           string
+	Level          string
+	Format         string
+	MaxSizeMB      int
+	RotateInterval time.Duration
+	MaxBackups     int
+}
+
+type CacheConfig struct {
+	Backend            string
+	TTL                time.Duration
+	RedisURL           string
+	ClosedWindowMaxAge time.Duration
+}
+
+type QueryConfig struct {
+	MaxPeriod time.Duration
+}
+
+// IngestConfig limits the bodies posted to create co2 data and locations.
+type IngestConfig struct {
+	MaxBodyBytes int
+	MaxBatch     int
+}
+
+// ReadingsConfig holds the physical ranges of the values of a reading. A
+// reading with a value out of range is rejected or quarantined.
+type ReadingsConfig struct {
+	CO2Min     int
+	CO2Max     int
+	TempMin    float64
+	TempMax    float64
+	OutOfRange string
+}
+
+// RateLimitConfig limits the requests of every client per route group. A
+// client is identified by its api key or its ip.
+type RateLimitConfig struct {
+	Backend     string
+	IdentifyBy  string
+	Co2Data     Rate
+	Location    Rate
+	Calibration Rate
+	Quarantine  Rate
+}
+
+// Rate allows Requests per Period with bursts of up to Requests. The zero
+// rate does not limit at all.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+type TracingConfig struct {
+	Enabled     bool
+	ServiceName string
+	Endpoint    string
+}
+
+const (
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+const (
+	CacheNone   = "none"
+	CacheMemory = "memory"
+	CacheRedis  = "redis"
+)
+
+const (
+	RateLimitNone   = "none"
+	RateLimitMemory = "memory"
+	RateLimitRedis  = "redis"
+)
+
+const (
+	IdentifyByKey = "key"
+	IdentifyByIP  = "ip"
+)
+
+const (
+	OutOfRangeReject     = "reject"
+	OutOfRangeQuarantine = "quarantine"
+)
+
+const redacted = "********"
+
+var current atomic.Pointer[Config]
+
+// Default returns the configuration that is used for every value that is
+// neither set in the config file, the environment nor the flags.
+func Default() *Config {
+	c := &Config{}
+	for _, s := range settings {
+		if err := s.set(c, s.def); err != nil {
+			panic(fmt.Sprintf("invalid default for %s: %s", s.key, err))
+		}
+	}
+
+	return c
+}
+
+// Get returns the loaded configuration, or the defaults if nothing was loaded yet.
+func Get() *Config {
+	if c := current.Load(); c != nil {
+		return c
+	}
+
+	current.CompareAndSwap(nil, Default())
+	return current.Load()
+}
+
+func Set(c *Config) {
+	current.Store(c)
+}
+
+func (c *Config) Development() bool {
+	return c.App.Env == "development"
+}
+
+// DatabaseURL is the dev url in development and the regular url otherwise.
+func (c *Config) DatabaseURL() string {
+	if c.Development() && c.Database.DevURL != "" {
+		return c.Database.DevURL
+	}
+
+	return c.Database.URL
+}
+
+// Unlimited tells whether the rate does not limit the requests.
+func (r Rate) Unlimited() bool {
+	return r.Requests <= 0 || r.Period <= 0
+}
+
+func (c ServerConfig) TLS() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+func (c *Config) Validate() error {
+	errs := []error{}
+
+	switch c.Database.Driver {
+	case DriverPostgres:
+		if c.DatabaseURL() == "" {
+			errs = append(errs, errors.New("database.url (DATABASE_URL) is required"))
+		}
+		if c.Database.Schema == "" {
+			errs = append(errs, errors.New("database.schema (POSTGRES_DB) is required"))
+		}
+	case DriverSqlite:
+		if c.Database.SqlitePath == "" {
+			errs = append(errs, errors.New("database.sqlite_path (SQLITE_PATH) is required"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("database.driver (DB_DRIVER) has to be %s or %s, got %q", DriverPostgres, DriverSqlite, c.Database.Driver))
+	}
+	if c.Auth.APIKey == "" || c.Auth.AdminAPIKey == "" {
+		errs = append(errs, errors.New("auth.api_key (X_API_KEY) and auth.admin_api_key (X_API_KEY_ADMIN) are required"))
+	}
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr (APP_ADDR) is required"))
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("server.tls_cert_file (TLS_CERT_FILE) and server.tls_key_file (TLS_KEY_FILE) have to be set together"))
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		errs = append(errs, errors.New("server.max_header_bytes (SERVER_MAX_HEADER_BYTES) has to be positive"))
+	}
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("log.level (LOG_LEVEL) has to be debug, info, warn or error, got %q", c.Log.Level))
+	}
+	if c.Log.Format != LogFormatJSON && c.Log.Format != LogFormatText {
+		errs = append(errs, fmt.Errorf("log.format (LOG_FORMAT) has to be %s or %s, got %q", LogFormatJSON, LogFormatText, c.Log.Format))
+	}
+	if c.Log.MaxSizeMB <= 0 {
+		errs = append(errs, errors.New("log.max_size_mb (LOG_MAX_SIZE_MB) has to be positive"))
+	}
+	if c.Log.MaxBackups < 0 {
+		errs = append(errs, errors.New("log.max_backups (LOG_MAX_BACKUPS) must not be negative"))
+	}
+	switch c.Cache.Backend {
+	case CacheNone, CacheMemory:
+	case CacheRedis:
+		if c.Cache.RedisURL == "" {
+			errs = append(errs, errors.New("cache.redis_url (REDIS_URL) is required for the redis cache"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("cache.backend (CACHE_BACKEND) has to be %s, %s or %s, got %q", CacheNone, CacheMemory, CacheRedis, c.Cache.Backend))
+	}
+	if c.Cache.TTL < 0 {
+		errs = append(errs, errors.New("cache.ttl (CACHE_TTL) must not be negative"))
+	}
+	if c.Cache.ClosedWindowMaxAge < 0 {
+		errs = append(errs, errors.New("cache.closed_window_max_age (CACHE_CLOSED_WINDOW_MAX_AGE) must not be negative"))
+	}
+	if c.Cache.ClosedWindowMaxAge > 10*time.Minute {
+		errs = append(errs, errors.New("cache.closed_window_max_age (CACHE_CLOSED_WINDOW_MAX_AGE) must not be longer than 10m, calibrations change past values"))
+	}
+	if c.Ingest.MaxBodyBytes <= 0 {
+		errs = append(errs, errors.New("ingest.max_body_bytes (INGEST_MAX_BODY_BYTES) has to be positive"))
+	}
+	if c.Ingest.MaxBatch <= 0 {
+		errs = append(errs, errors.New("ingest.max_batch (INGEST_MAX_BATCH) has to be positive"))
+	}
+	if c.Readings.CO2Min >= c.Readings.CO2Max {
+		errs = append(errs, errors.New("readings.co2_min (READINGS_CO2_MIN) has to be lower than readings.co2_max (READINGS_CO2_MAX)"))
+	}
+	if c.Readings.TempMin >= c.Readings.TempMax {
+		errs = append(errs, errors.New("readings.temp_min (READINGS_TEMP_MIN) has to be lower than readings.temp_max (READINGS_TEMP_MAX)"))
+	}
+	if c.Readings.OutOfRange != OutOfRangeReject && c.Readings.OutOfRange != OutOfRangeQuarantine {
+		errs = append(errs, fmt.Errorf("readings.out_of_range (READINGS_OUT_OF_RANGE) has to be %s or %s, got %q", OutOfRangeReject, OutOfRangeQuarantine, c.Readings.OutOfRange))
+	}
+	switch c.RateLimit.Backend {
+	case RateLimitNone, RateLimitMemory:
+	case RateLimitRedis:
+		if c.Cache.RedisURL == "" {
+			errs = append(errs, errors.New("cache.redis_url (REDIS_URL) is required for the redis rate limit backend"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("rate_limit.backend (RATE_LIMIT_BACKEND) has to be %s, %s or %s, got %q", RateLimitNone, RateLimitMemory, RateLimitRedis, c.RateLimit.Backend))
+	}
+	if c.RateLimit.IdentifyBy != IdentifyByKey && c.RateLimit.IdentifyBy != IdentifyByIP {
+		errs = append(errs, fmt.Errorf("rate_limit.identify_by (RATE_LIMIT_IDENTIFY_BY) has to be %s or %s, got %q", IdentifyByKey, IdentifyByIP, c.RateLimit.IdentifyBy))
+	}
+	if c.Tracing.Enabled && c.Tracing.ServiceName == "" {
+		errs = append(errs, errors.New("tracing.service_name (OTEL_SERVICE_NAME) is required for tracing"))
+	}
+	if c.Anomaly.OutdoorBaseline <= 0 {
+		errs = append(errs, errors.New("anomaly.outdoor_baseline (CO2_OUTDOOR_BASELINE) has to be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// String lists all values with secrets redacted, so the configuration can be logged.
+func (c *Config) String() string {
+	values := make([]string, 0, len(settings))
+	for _, s := range settings {
+		values = append(values, fmt.Sprintf("%s=%s", s.key, s.display(c)))
+	}
+
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Load builds the configuration from the defaults, the config file, the
+// environment and the flags, each overriding the previous one. A .env file
+// in the working directory is loaded into the environment if it exists.
+// The config file is passed with -config or CONFIG_FILE and may be YAML or TOML.
+// The configuration is returned even if it is invalid, so it can be printed.
+func Load(args []string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("could not load .env file: %w", err)
+	}
+
+	flags := flag.NewFlagSet("co2monitor.api", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file (env CONFIG_FILE)")
+	for _, s := range settings {
+		flags.String(s.key, "", fmt.Sprintf("%s (env %s, default %q)", s.usage, s.env, s.def))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	c := Default()
+	errs := []error{}
+
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, apply(c, values)...)
+	}
+
+	errs = append(errs, apply(c, fromEnv())...)
+
+	values := map[string]string{}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			values[f.Name] = f.Value.String()
+		}
+	})
+	errs = append(errs, apply(c, values)...)
+
+	if err := c.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return c, errors.Join(errs...)
+}
+
+// Print writes the configuration as YAML with secrets redacted.
+func Print(w io.Writer, c *Config) error {
+	section := ""
+	for _, s := range settings {
+		parts := strings.SplitN(s.key, ".", 2)
+		if parts[0] != section {
+			section = parts[0]
+			if _, err := fmt.Fprintf(w, "%s:\n", section); err != nil {
+				return err
+			}
+		}
+
+		value := s.display(c)
+		if !s.plain {
+			quoted, err := yaml.Marshal(value)
+			if err != nil {
+				return err
+			}
+			value = strings.TrimSpace(string(quoted))
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s # %s\n", parts[1], value, s.env); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fromEnv() map[string]string {
+	values := map[string]string{}
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			values[s.key] = value
+		}
+	}
+
+	// gin listens on PORT, keep supporting it if no address is set
+	if _, ok := values["server.addr"]; !ok && os.Getenv("PORT") != "" {
+		values["server.addr"] = ":" + os.Getenv("PORT")
+	}
+
+	return values
+}
+
+func apply(c *Config, values map[string]string) []error {
+	errs := []error{}
+	for _, s := range settings {
+		if value, ok := values[s.key]; ok {
+			if err := s.set(c, value); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".toml":
+		err = toml.Unmarshal(content, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s has to be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", raw, values)
+
+	unknown := []string{}
+	for key := range values {
+		if !known(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown keys in config file %s: %s", path, strings.Join(unknown, ", "))
+	}
+
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]interface{}, values map[string]string) {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(key, nested, values)
+			continue
+		}
+		values[key] = fmt.Sprint(value)
+	}
+}
+
+func known(key string) bool {
+	for _, s := range settings {
+		if s.key == key {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting connects a value of the config with its key in the config file and
+// the flags, and with its environment variable.
+type setting struct {
+	key    string
+	env    string
+	def    string
+	usage  string
+	secret bool
+	// plain values are printed without YAML quoting
+	plain bool
+	set   func(c *Config, value string) error
+	get   func(c *Config) string
+}
+
+var settings = []setting{
+	stringSetting("app.env", "APP_ENV", "production", "environment of the app, development enables http in the swagger docs", false, func(c *Config) *string { return &c.App.Env }),
+	stringSetting("app.host", "APP_HOST", "", "host shown in the swagger docs", false, func(c *Config) *string { return &c.App.Host }),
+
+	stringSetting("server.addr", "APP_ADDR", ":8080", "address the server listens on", false, func(c *Config) *string { return &c.Server.Addr }),
+	stringSetting("server.tls_cert_file", "TLS_CERT_FILE", "", "certificate file, enables TLS together with the key file", false, func(c *Config) *string { return &c.Server.TLSCertFile }),
+	stringSetting("server.tls_key_file", "TLS_KEY_FILE", "", "key file, enables TLS together with the certificate file", false, func(c *Config) *string { return &c.Server.TLSKeyFile }),
+	durationSetting("server.read_timeout", "SERVER_READ_TIMEOUT", "15s", "maximum duration for reading a request", func(c *Config) *time.Duration { return &c.Server.ReadTimeout }),
+	durationSetting("server.read_header_timeout", "SERVER_READ_HEADER_TIMEOUT", "5s", "maximum duration for reading the request headers", func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout }),
+	durationSetting("server.write_timeout", "SERVER_WRITE_TIMEOUT", "30s", "maximum duration before timing out writes of the response", func(c *Config) *time.Duration { return &c.Server.WriteTimeout }),
+	durationSetting("server.idle_timeout", "SERVER_IDLE_TIMEOUT", "2m", "maximum duration to wait for the next request on keep-alive connections", func(c *Config) *time.Duration { return &c.Server.IdleTimeout }),
+	durationSetting("server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", "30s", "maximum duration to drain requests and stop workers on shutdown", func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+	intSetting("server.max_header_bytes", "SERVER_MAX_HEADER_BYTES", "1048576", "maximum size of the request headers", func(c *Config) *int { return &c.Server.MaxHeaderBytes }),
+
+	stringSetting("database.driver", "DB_DRIVER", DriverPostgres, "database driver, postgres or sqlite", false, func(c *Config) *string { return &c.Database.Driver }),
+	stringSetting("database.url", "DATABASE_URL", "", "postgres connection string", true, func(c *Config) *string { return &c.Database.URL }),
+	stringSetting("database.dev_url", "DATABASE_URL_DEV", "", "postgres connection string used in development", true, func(c *Config) *string { return &c.Database.DevURL }),
+	stringSetting("database.schema", "POSTGRES_DB", "co2monitor", "postgres schema the tables are created in", false, func(c *Config) *string { return &c.Database.Schema }),
+	boolSetting("database.auto_migrate", "DB_AUTO_MIGRATE", "true", "apply pending migrations on start", func(c *Config) *bool { return &c.Database.AutoMigrate }),
+	stringSetting("database.sqlite_path", "SQLITE_PATH", "data/co2monitor.db", "database file used with the sqlite driver", false, func(c *Config) *string { return &c.Database.SqlitePath }),
+
+	stringSetting("auth.api_key", "X_API_KEY", "", "api key for reading", true, func(c *Config) *string { return &c.Auth.APIKey }),
+	stringSetting("auth.admin_api_key", "X_API_KEY_ADMIN", "", "api key for reading and writing", true, func(c *Config) *string { return &c.Auth.AdminAPIKey }),
+
+	durationSetting("heartbeat.silence_window", "HEARTBEAT_SILENCE_WINDOW", "15m", "duration without readings after which a location is offline", func(c *Config) *time.Duration { return &c.Heartbeat.SilenceWindow }),
+	durationSetting("heartbeat.check_interval", "HEARTBEAT_CHECK_INTERVAL", "1m", "interval of the location status checks", func(c *Config) *time.Duration { return &c.Heartbeat.CheckInterval }),
+
+	intSetting("anomaly.outdoor_baseline", "CO2_OUTDOOR_BASELINE", "420", "outdoor co2 level in ppm used for the drift detection", func(c *Config) *int { return &c.Anomaly.OutdoorBaseline }),
+
+	stringSetting("log.file", "LOG_FILE", "logs/gin.log", "file the logs are appended to next to stdout, empty logs to stdout only", false, func(c *Config) *string { return &c.Log.File }),
+	stringSetting("log.level", "LOG_LEVEL", "info", "lowest level that is logged: debug, info, warn or error", false, func(c *Config) *string { return &c.Log.Level }),
+	stringSetting("log.format", "LOG_FORMAT", LogFormatJSON, "format of the log lines: json or text", false, func(c *Config) *string { return &c.Log.Format }),
+	intSetting("log.max_size_mb", "LOG_MAX_SIZE_MB", "100", "size in megabytes after which the log file is rotated", func(c *Config) *int { return &c.Log.MaxSizeMB }),
+	durationSetting("log.rotate_interval", "LOG_ROTATE_INTERVAL", "24h", "age after which the log file is rotated", func(c *Config) *time.Duration { return &c.Log.RotateInterval }),
+	intSetting("log.max_backups", "LOG_MAX_BACKUPS", "7", "number of rotated log files that are kept, 0 keeps all", func(c *Config) *int { return &c.Log.MaxBackups }),
+
+	stringSetting("cache.backend", "CACHE_BACKEND", CacheMemory, "cache for the latest readings: none, memory or redis", false, func(c *Config) *string { return &c.Cache.Backend }),
+	anyDurationSetting("cache.ttl", "CACHE_TTL", "5m", "time after which a cached latest reading is read again, 0 keeps it until the next reading", func(c *Config) *time.Duration { return &c.Cache.TTL }),
+	stringSetting("cache.redis_url", "REDIS_URL", "", "redis url used with the redis cache and rate limit backend, e.g. redis://localhost:6379/0", true, func(c *Config) *string { return &c.Cache.RedisURL }),
+	anyDurationSetting("cache.closed_window_max_age", "CACHE_CLOSED_WINDOW_MAX_AGE", "1m", "max age clients may cache co2 data of time windows in the past, at most 10m as calibrations change past values, 0 makes them revalidate", func(c *Config) *time.Duration { return &c.Cache.ClosedWindowMaxAge }),
+
+	durationSetting("query.max_period", "QUERY_MAX_PERIOD", "2160h", "longest period and forecast horizon a request may ask for, forecast horizons are limited to 24h as well", func(c *Config) *time.Duration { return &c.Query.MaxPeriod }),
+
+	intSetting("ingest.max_body_bytes", "INGEST_MAX_BODY_BYTES", "1048576", "maximum size of a body posted to create co2 data or locations", func(c *Config) *int { return &c.Ingest.MaxBodyBytes }),
+	intSetting("ingest.max_batch", "INGEST_MAX_BATCH", "1000", "maximum number of co2 data or locations posted at once", func(c *Config) *int { return &c.Ingest.MaxBatch }),
+
+	intSetting("readings.co2_min", "READINGS_CO2_MIN", "0", "lowest co2 value in ppm which is accepted", func(c *Config) *int { return &c.Readings.CO2Min }),
+	intSetting("readings.co2_max", "READINGS_CO2_MAX", "40000", "highest co2 value in ppm which is accepted", func(c *Config) *int { return &c.Readings.CO2Max }),
+	floatSetting("readings.temp_min", "READINGS_TEMP_MIN", "-40", "lowest temperature in °C which is accepted", func(c *Config) *float64 { return &c.Readings.TempMin }),
+	floatSetting("readings.temp_max", "READINGS_TEMP_MAX", "85", "highest temperature in °C which is accepted", func(c *Config) *float64 { return &c.Readings.TempMax }),
+	stringSetting("readings.out_of_range", "READINGS_OUT_OF_RANGE", OutOfRangeReject, "what happens to co2 data with values out of range: reject or quarantine, which stores the valid items and keeps the others in the quarantine", false, func(c *Config) *string { return &c.Readings.OutOfRange }),
+
+	stringSetting("rate_limit.backend", "RATE_LIMIT_BACKEND", RateLimitMemory, "store of the rate limits: none, memory or redis, which shares them between instances", false, func(c *Config) *string { return &c.RateLimit.Backend }),
+	stringSetting("rate_limit.identify_by", "RATE_LIMIT_IDENTIFY_BY", IdentifyByKey, "what a client is limited by: key, falling back to the ip without a known key, or ip", false, func(c *Config) *string { return &c.RateLimit.IdentifyBy }),
+	rateSetting("rate_limit.co2data", "RATE_LIMIT_CO2DATA", "600/m", "requests a client may send to /co2data, off does not limit them", func(c *Config) *Rate { return &c.RateLimit.Co2Data }),
+	rateSetting("rate_limit.location", "RATE_LIMIT_LOCATION", "300/m", "requests a client may send to /location, off does not limit them", func(c *Config) *Rate { return &c.RateLimit.Location }),
+	rateSetting("rate_limit.calibration", "RATE_LIMIT_CALIBRATION", "60/m", "requests a client may send to /calibration, off does not limit them", func(c *Config) *Rate { return &c.RateLimit.Calibration }),
+	rateSetting("rate_limit.quarantine", "RATE_LIMIT_QUARANTINE", "60/m", "requests a client may send to /quarantine, off does not limit them", func(c *Config) *Rate { return &c.RateLimit.Quarantine }),
+
+	boolSetting("tracing.enabled", "TRACING_ENABLED", "false", "export OpenTelemetry traces of requests, queries and background jobs", func(c *Config) *bool { return &c.Tracing.Enabled }),
+	stringSetting("tracing.service_name", "OTEL_SERVICE_NAME", "co2monitor.api", "service name of the exported traces", false, func(c *Config) *string { return &c.Tracing.ServiceName }),
+	stringSetting("tracing.endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", "", "url of the OTLP/HTTP collector, e.g. http://localhost:4318, empty uses the exporter default", false, func(c *Config) *string { return &c.Tracing.Endpoint }),
+}
+
+func (s setting) display(c *Config) string {
+	value := s.get(c)
+	if s.secret && value != "" {
+		return redacted
+	}
+
+	return value
+}
+
+func stringSetting(key, env, def, usage string, secret bool, field func(c *Config) *string) setting {
+	return setting{
+		key:    key,
+		env:    env,
+		def:    def,
+		usage:  usage,
+		secret: secret,
+		set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+		get: func(c *Config) string {
+			return *field(c)
+		},
+	}
+}
+
+func durationSetting(key, env, def, usage string, field func(c *Config) *time.Duration) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		def:   def,
+		usage: usage,
+		plain: true,
+		set: func(c *Config, value string) error {
+			duration, err := time.ParseDuration(value)
+			if err != nil || duration <= 0 {
+				return fmt.Errorf("%s has to be a positive duration like 30s or 5m, got %q", key, value)
+			}
+			*field(c) = duration
+			return nil
+		},
+		get: func(c *Config) string {
+			return field(c).String()
+		},
+	}
+}
+
+// anyDurationSetting accepts every duration, e.g. for settings where 0 has a
+// meaning. Validate checks their range.
+func anyDurationSetting(key, env, def, usage string, field func(c *Config) *time.Duration) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		def:   def,
+		usage: usage,
+		plain: true,
+		set: func(c *Config, value string) error {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s has to be a duration like 0, 30s or 5m, got %q", key, value)
+			}
+			*field(c) = duration
+			return nil
+		},
+		get: func(c *Config) string {
+			return field(c).String()
+		},
+	}
+}
+
+func intSetting(key, env, def, usage string, field func(c *Config) *int) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		def:   def,
+		usage: usage,
+		plain: true,
+		set: func(c *Config, value string) error {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s has to be a number, got %q", key, value)
+			}
+			*field(c) = number
+			return nil
+		},
+		get: func(c *Config) string {
+			return strconv.Itoa(*field(c))
+		},
+	}
+}
+
+func floatSetting(key, env, def, usage string, field func(c *Config) *float64) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		def:   def,
+		usage: usage,
+		plain: true,
+		set: func(c *Config, value string) error {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+				return fmt.Errorf("%s has to be a number, got %q", key, value)
+			}
+			*field(c) = number
+			return nil
+		},
+		get: func(c *Config) string {
+			return strconv.FormatFloat(*field(c), 'g', -1, 64)
+		},
+	}
+}
+
+func boolSetting(key, env, def, usage string, field func(c *Config) *bool) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		def:   def,
+		usage: usage,
+		plain: true,
+		set: func(c *Config, value string) error {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s has to be true or false, got %q", key, value)
+			}
+			*field(c) = enabled
+			return nil
+		},
+		get: func(c *Config) string {
+			return strconv.FormatBool(*field(c))
+		},
+	}
+}
+
+var errInvalidRate = errors.New("invalid rate")
+
+// rateUnits are the periods which can be written without a number, e.g. 60/m.
+var rateUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+func rateSetting(key, env, def, usage string, field func(c *Config) *Rate) setting {
+	return setting{
+		key:   key,
+		env:   env,
+		def:   def,
+		usage: usage,
+		set: func(c *Config, value string) error {
+			rate, err := parseRate(value)
+			if err != nil {
+				return fmt.Errorf("%s has to be a rate like 60/m or 100/10s or off, got %q", key, value)
+			}
+			*field(c) = rate
+			return nil
+		},
+		get: func(c *Config) string {
+			return field(c).String()
+		},
+	}
+}
+
+// rateOff is the unlimited rate.
+const rateOff = "off"
+
+// parseRate reads a rate written as requests/period or off.
+func parseRate(value string) (Rate, error) {
+	if value == rateOff {
+		return Rate{}, nil
+	}
+
+	count, unit, found := strings.Cut(value, "/")
+	requests, err := strconv.Atoi(count)
+	if !found || err != nil || requests <= 0 {
+		return Rate{}, errInvalidRate
+	}
+
+	period, ok := rateUnits[unit]
+	if !ok {
+		period, err = time.ParseDuration(unit)
+		if err != nil || period <= 0 {
+			return Rate{}, errInvalidRate
+		}
+	}
+
+	return Rate{Requests: requests, Period: period}, nil
+}
+
+func (r Rate) String() string {
+	if r.Unlimited() {
+		return rateOff
+	}
+
+	for unit, period := range rateUnits {
+		if r.Period == period {
+			return fmt.Sprintf("%d/%s", r.Requests, unit)
+		}
+	}
+
+	return fmt.Sprintf("%d/%s", r.Requests, r.Period)
+}
@@ -1,7 +1,36 @@
 package controllers
 
-import "gorm.io/gorm"
+import (
+	"github.com/fminister/co2monitor.api/health"
+	"github.com/fminister/co2monitor.api/repositories"
+	"github.com/fminister/co2monitor.api/workers"
+	"github.com/gin-gonic/gin"
+)
 
 type APIEnv struct {
-	DB *gorm.DB
+	Locations    repositories.LocationRepository
+	Co2Data      repositories.Co2Repository
+	Calibrations repositories.CalibrationRepository
+	Quarantine   repositories.QuarantineRepository
+	Heartbeat    *workers.HeartbeatWatcher
+	Health       *health.Checker
+}
+
+// The repositories bound to the context of the request, so their queries are
+// part of the trace of the request and stop with it.
+
+func (a *APIEnv) locations(c *gin.Context) repositories.LocationRepository {
+	return a.Locations.WithContext(c.Request.Context())
+}
+
+func (a *APIEnv) co2Data(c *gin.Context) repositories.Co2Repository {
+	return a.Co2Data.WithContext(c.Request.Context())
+}
+
+func (a *APIEnv) calibrations(c *gin.Context) repositories.CalibrationRepository {
+	return a.Calibrations.WithContext(c.Request.Context())
+}
+
+func (a *APIEnv) quarantine(c *gin.Context) repositories.QuarantineRepository {
+	return a.Quarantine.WithContext(c.Request.Context())
 }
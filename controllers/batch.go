@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/fminister/co2monitor.api/config"
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// decodeBatch streams the JSON array of the body within the configured
+// limits of the body size and the batch length.
+func decodeBatch[T any](c *gin.Context) ([]T, error) {
+	limits := config.Get().Ingest
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, int64(limits.MaxBodyBytes))
+
+	return ex.DecodeBatch[T](body, limits.MaxBatch)
+}
+
+// batchProblem turns an error of decodeBatch into a 413 if a limit was hit
+// and into a 400 with the detail otherwise.
+func batchProblem(err error, detail string) *problem.Problem {
+	var tooLarge *http.MaxBytesError
+	var tooLong *ex.BatchTooLongError
+	switch {
+	case errors.As(err, &tooLarge):
+		return problem.New(http.StatusRequestEntityTooLarge, problem.CodeBodyTooLarge, fmt.Sprintf("The body must not be larger than %d bytes.", tooLarge.Limit)).WithCause(err)
+	case errors.As(err, &tooLong):
+		return problem.New(http.StatusRequestEntityTooLarge, problem.CodeBatchTooLong, fmt.Sprintf("The body must not contain more than %d items.", tooLong.Max)).WithCause(err)
+	default:
+		return problem.BadRequest(problem.CodeInvalidBody, detail).WithCause(err)
+	}
+}
+
+// partial tells whether the client asked to store the valid items of a batch
+// even if others are invalid.
+func partial(c *gin.Context) bool {
+	return c.Query("partial") == "true"
+}
+
+// validateTags validates the items of a batch by the validator tags of their type.
+func validateTags[T any]() func(index int, item T) []models.FieldErrorDto {
+	var zero T
+	validator := ex.Validator(zero)
+
+	return func(index int, item T) []models.FieldErrorDto {
+		if result := validator.Validate(item); result != nil {
+			return ex.ValidationErrors(result)
+		}
+
+		return nil
+	}
+}
+
+// storePartially validates every item on its own and stores the valid ones
+// at once. validate returns the invalid fields of the item at an index in
+// the batch, nil validates by the validator tags. The optional check rejects
+// further items by their index in the valid ones, e.g. for unknown
+// references. If the store rejects them, e.g. for a duplicate name, every
+// item is stored on its own, so only the offending ones fail. A failing
+// database fails the whole batch, nothing is stored in that case.
+func storePartially[T any](items []T, validate func(int, T) []models.FieldErrorDto, check func([]T) (map[int]*problem.Problem, error), store func([]T) ([]T, error), detail string) ([]T, []models.ItemErrorDto, *problem.Problem) {
+	if validate == nil {
+		validate = validateTags[T]()
+	}
+
+	failed := []models.ItemErrorDto{}
+	valid := []T{}
+	indexes := []int{}
+	for i, item := range items {
+		if fieldErrors := validate(i, item); len(fieldErrors) > 0 {
+			failed = append(failed, itemError(i, problem.Validation("Some values are invalid.", pointInto(i, fieldErrors))))
+			continue
+		}
+		valid = append(valid, item)
+		indexes = append(indexes, i)
+	}
+
+	if check != nil && len(valid) > 0 {
+		problems, err := check(valid)
+		if err != nil {
+			return nil, nil, storeProblem(err, detail, nil)
+		}
+
+		checked := []T{}
+		checkedIndexes := []int{}
+		for i, item := range valid {
+			if p := problems[i]; p != nil {
+				p.Errors = pointInto(indexes[i], p.Errors)
+				failed = append(failed, itemError(indexes[i], p))
+				continue
+			}
+			checked = append(checked, item)
+			checkedIndexes = append(checkedIndexes, indexes[i])
+		}
+		valid, indexes = checked, checkedIndexes
+	}
+	if len(valid) == 0 {
+		return []T{}, sortFailed(failed), nil
+	}
+
+	created, err := store(valid)
+	if err == nil {
+		return created, sortFailed(failed), nil
+	}
+	if p := storeProblem(err, detail, nil); p.Status >= http.StatusInternalServerError {
+		return nil, nil, p
+	}
+
+	created = []T{}
+	for i, item := range valid {
+		stored, err := store([]T{item})
+		if err != nil {
+			failed = append(failed, itemError(indexes[i], storeProblem(err, detail, nil)))
+			continue
+		}
+		created = append(created, stored...)
+	}
+
+	return created, sortFailed(failed), nil
+}
+
+func sortFailed(failed []models.ItemErrorDto) []models.ItemErrorDto {
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failed[i].Index < failed[j].Index
+	})
+
+	return failed
+}
+
+func itemError(index int, p *problem.Problem) models.ItemErrorDto {
+	return models.ItemErrorDto{Index: index, Status: p.Status, Code: p.Code, Detail: p.Detail, Errors: p.Errors}
+}
+
+// pointInto prefixes the pointers of the field errors of an item with its
+// index in the posted array.
+func pointInto(index int, fieldErrors []models.FieldErrorDto) []models.FieldErrorDto {
+	for i := range fieldErrors {
+		fieldErrors[i].Pointer = fmt.Sprintf("/%d%s", index, fieldErrors[i].Pointer)
+	}
+
+	return fieldErrors
+}
+
+// pointOutOf removes the index of an item in the posted array from the
+// pointers of its field errors again.
+func pointOutOf(index int, fieldErrors []models.FieldErrorDto) []models.FieldErrorDto {
+	prefix := fmt.Sprintf("/%d", index)
+	relative := []models.FieldErrorDto{}
+	for _, fieldError := range fieldErrors {
+		fieldError.Pointer = strings.TrimPrefix(fieldError.Pointer, prefix)
+		relative = append(relative, fieldError)
+	}
+
+	return relative
+}
+
+// compactPayload removes the whitespace of a posted item.
+func compactPayload(item json.RawMessage) json.RawMessage {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, item); err != nil {
+		return item
+	}
+
+	return compacted.Bytes()
+}
+
+// batchStatus is 201 if every item was created and 207 if any item failed or
+// was held back.
+func batchStatus(failed ...[]models.ItemErrorDto) int {
+	for _, items := range failed {
+		if len(items) > 0 {
+			return http.StatusMultiStatus
+		}
+	}
+
+	return http.StatusCreated
+}
+
+// splitQuarantined separates the items which were held back from the failed ones.
+func splitQuarantined(items []models.ItemErrorDto) ([]models.ItemErrorDto, []models.ItemErrorDto) {
+	failed := []models.ItemErrorDto{}
+	quarantined := []models.ItemErrorDto{}
+	for _, item := range items {
+		if item.Code == problem.CodeQuarantined {
+			quarantined = append(quarantined, item)
+			continue
+		}
+		failed = append(failed, item)
+	}
+
+	return failed, quarantined
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dranikpg/dto-mapper"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api
+
+// GetCalibrations godoc
+//
+//	@Summary		Get all calibrations of a location
+//	@Description	Get all calibrations of a location by passing the location id as parameter, the newest first.
+//	@Tags			Calibrations
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	[]models.CalibrationDto
+//	@Header			200		{string}	ETag	"entity tag of the calibrations"
+//	@Success		304		"the calibrations did not change"
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/location/{id}/calibrations [get]
+//	@Param			id	path		int	 	true	"LocationId"
+//	@Param			If-None-Match	header		string	 	false	"ETag of a previous response"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) GetCalibrations(c *gin.Context) {
+	locationId := c.Param("id")
+
+	if _, err := a.locations(c).GetById(locationId); err != nil {
+		c.Error(storeProblem(err, "Could not get location.", problem.NotFound(problem.CodeLocationNotFound, fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId))))
+		return
+	}
+
+	calibrations, err := a.calibrations(c).GetByLocation(locationId)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get calibrations.", nil))
+		return
+	}
+
+	var calibrationDto []models.CalibrationDto
+	dto.Map(&calibrationDto, calibrations)
+
+	cachedJSON(c, calibrationDto, time.Time{}, ex.LiveCacheControl)
+}
+
+// CreateCalibration godoc
+//
+//	@Summary		Create calibrations
+//	@Description	Create calibrations by posting a list of calibration objects. Values are corrected on read with value * scale + offset while the calibration is valid. A missing scale means that the values are not scaled, a missing end means that the calibration is valid until further notice.
+//	@Tags			Calibrations
+//	@Accept			json
+//	@Produce		json
+//	@Success		201		{object}	[]models.CalibrationDto
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/calibration/new [post]
+//	@Param			calibration	body		[]models.CalibrationPostDto	 true	"New Calibration"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) CreateCalibration(c *gin.Context) {
+	var calibrations []models.Calibration
+	if err := c.ShouldBindJSON(&calibrations); err != nil {
+		c.Error(problem.BadRequest(problem.CodeInvalidBody, "Could not parse calibration from body.").WithCause(err))
+		return
+	}
+
+	if len(calibrations) == 0 {
+		c.Error(problem.BadRequest(problem.CodeInvalidBody, "Could not create calibration. The body contains no calibrations."))
+		return
+	}
+
+	if err := ex.Validator([]models.Calibration{}).Validate(calibrations); err != nil {
+		c.Error(problem.Validation("Some values in the body are invalid.", ex.ValidationErrors(err)))
+		return
+	}
+
+	for i, calibration := range calibrations {
+		if calibration.ValidTo != nil && !calibration.ValidTo.After(calibration.ValidFrom) {
+			c.Error(problem.Validation("Could not create calibration. valid_to has to be after valid_from.", []models.FieldErrorDto{
+				{Pointer: fmt.Sprintf("/%d/valid_to", i), Detail: "valid_to has to be after valid_from"},
+			}))
+			return
+		}
+	}
+
+	calibrations, err := a.calibrations(c).Create(calibrations)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not create calibration.", nil))
+		return
+	}
+
+	var calibrationDto []models.CalibrationDto
+	dto.Map(&calibrationDto, calibrations)
+
+	c.JSON(http.StatusCreated, calibrationDto)
+}
+
+// DeleteCalibration godoc
+//
+//	@Summary		Delete a calibration
+//	@Description	Delete a calibration by passing the calibration id as parameter.
+//	@Tags			Calibrations
+//	@Accept			json
+//	@Produce		json
+//	@Success		204 "Deleted successfully"
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/calibration/{id} [delete]
+//	@Param			id	path		int	 	true	"CalibrationId"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) DeleteCalibration(c *gin.Context) {
+	calibrationId := c.Param("id")
+
+	calibration, err := a.calibrations(c).GetById(calibrationId)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get calibration.", problem.NotFound(problem.CodeCalibrationNotFound, "Could not find calibration by id.")))
+		return
+	}
+
+	err = a.calibrations(c).Delete(calibration)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not delete calibration.", nil))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,14 +1,24 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/charmbracelet/log"
 	"github.com/dranikpg/dto-mapper"
-	"github.com/fminister/co2monitor.api/db/db_calls"
+	"github.com/fminister/co2monitor.api/config"
 	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/health"
+	"github.com/fminister/co2monitor.api/logging"
+	"github.com/fminister/co2monitor.api/metrics"
+	"github.com/fminister/co2monitor.api/middleware"
 	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,108 +27,656 @@ import (
 // GetCo2DataByTimeFrame godoc
 //
 //	@Summary		Get co2 data in a time frame
-//	@Description	Get co2 data by passing a location id as parameter and a time frame as query parameter. The time frame is from now minus [period] (1m, 1h, 1d).
+//	@Description	Get co2 data by passing a location id as parameter and a time frame as query parameter. The time frame is from [to] minus [period] (e.g. 30m, 6h, 1d12h, 2w or P7D, 6h by default) and [to] defaults to now. Readings flagged as anomalies can be left out. Values are calibrated unless raw values are requested. Time frames which ended more than five minutes ago may be cached by the client for a short time, others have to be revalidated with If-None-Match.
 //	@Tags			CO2 Data
 //	@Accept			json
 //	@Produce		json
 //	@Success		200		{object}	[]models.Co2DataDto
-//	@Failure		404	{object} string	"Something went wrong, please refer to the error message."
+//	@Header			200		{string}	ETag	"entity tag of the co2 data"
+//	@Header			200		{string}	Cache-Control	"private, no-cache for open and private, max-age for closed time frames"
+//	@Success		304		"the co2 data did not change"
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
 //	@Router			/co2data/{id}/search [get]
 //	@Param			id	path		int	 	true	"LocationId"
-//	@Param			period	query		string	 	true	"time frame" example(1m)
+//	@Param			period	query		string	 	false	"time frame" example(1m)
+//	@Param			to	query		string	 	false	"end of the time frame in RFC 3339" example(2023-08-01T12:00:00Z)
+//	@Param			exclude_anomalies	query		bool	 	false	"leave out readings flagged as anomalies"
+//	@Param			raw	query		bool	 	false	"return the values without calibration"
+//	@Param			If-None-Match	header		string	 	false	"ETag of a previous response"
 //
 // @Security ApiKeyAuth
 func (a *APIEnv) GetCo2DataByTimeFrame(c *gin.Context) {
 	locationId := c.Param("id")
-	period := c.Query("period")
+	excludeAnomalies := c.Query("exclude_anomalies") == "true"
+	raw := c.Query("raw") == "true"
 
-	if _, err := db_calls.GetLocationById(a.DB, locationId); err != nil {
-		log.Errorf(`Could not find any location with this id: <%s>. Error: <%s>`, locationId, err)
-		c.JSON(http.StatusNotFound, fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId))
+	duration, ok := queryPeriod(c, "period", ex.DefaultPeriod)
+	if !ok {
 		return
 	}
 
-	duration := ex.ValidateTimeDuration(period)
+	now := time.Now()
+	to := now
+	if c.Query("to") != "" {
+		parsed, err := time.Parse(time.RFC3339, c.Query("to"))
+		if err != nil {
+			c.Error(problem.BadRequest(problem.CodeInvalidParameter, fmt.Sprintf(`Invalid to: <%s>. Use RFC 3339, e.g. 2023-08-01T12:00:00Z.`, c.Query("to"))).WithCause(err))
+			return
+		}
+		to = parsed
+	}
+
+	if _, err := a.locations(c).GetById(locationId); err != nil {
+		c.Error(storeProblem(err, "Could not get location.", problem.NotFound(problem.CodeLocationNotFound, fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId))))
+		return
+	}
 
-	var co2Data []models.Co2Data
-	co2Data, err := db_calls.GetCo2DataByTimeFrame(a.DB, locationId, duration)
+	co2Data, err := a.co2Data(c).GetByTimeRange(locationId, to.Add(-duration), to, excludeAnomalies)
 	if err != nil {
-		log.Errorf(`Could not find any co2 data with this locationId: <%s>. Error: <%s>`, locationId, err)
-		c.JSON(http.StatusNotFound, fmt.Sprintf(`Could not find any co2 data with this locationId: <%s>.`, locationId))
+		c.Error(storeProblem(err, "Could not get co2 data.", nil))
 		return
 	}
 
+	if !raw {
+		if co2Data, _, err = a.calibrate(c, locationId, co2Data); err != nil {
+			c.Error(storeProblem(err, "Could not get calibrations.", nil))
+			return
+		}
+	}
+
 	var co2DataDto []models.Co2DataDto
 	dto.Map(&co2DataDto, co2Data)
 
-	c.JSON(http.StatusOK, co2DataDto)
+	cachedJSON(c, co2DataDto, time.Time{}, ex.WindowCacheControl(to, now))
 }
 
 // GetLatestCo2Data godoc
 //
 //	@Summary		Get latest co2 data for a location
-//	@Description	Get latest co2 data by passing a location id as parameter.
+//	@Description	Get latest co2 data by passing a location id as parameter. The values are calibrated unless raw values are requested. The latest readings are cached and the response carries an ETag, send it as If-None-Match to get 304 Not Modified as long as there is no new reading.
 //	@Tags			CO2 Data
 //	@Accept			json
 //	@Produce		json
 //	@Success		200		{object}	models.Co2DataDto
-//	@Failure		404	{object} string	"Something went wrong, please refer to the error message."
+//	@Header			200		{string}	ETag	"entity tag of the reading"
+//	@Header			200		{string}	Last-Modified	"latest update of the reading or calibrations"
+//	@Success		304		"the reading did not change"
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
 //	@Router			/co2data/{id}/latest [get]
 //	@Param			id	path		int	 	true	"LocationId"
+//	@Param			raw	query		bool	 	false	"return the values without calibration"
+//	@Param			If-None-Match	header		string	 	false	"ETag of a previous response"
+//	@Param			If-Modified-Since	header		string	 	false	"Last-Modified of a previous response"
 //
 // @Security ApiKeyAuth
 func (a *APIEnv) GetLatestCo2Data(c *gin.Context) {
 	locationId := c.Param("id")
+	raw := c.Query("raw") == "true"
 
-	co2Data, err := db_calls.GetLatestCo2Data(a.DB, locationId)
+	co2Data, err := a.co2Data(c).GetLatest(locationId, false)
 	if err != nil {
-		log.Errorf(`Could not find any co2 data with this locationId: <%s>. Error: <%s>`, locationId, err)
-		c.JSON(http.StatusNotFound, fmt.Sprintf(`Could not find any co2 data with this locationId: <%s>.`, locationId))
+		c.Error(storeProblem(err, "Could not get co2 data.", problem.NotFound(problem.CodeCo2DataNotFound, fmt.Sprintf(`Could not find any co2 data with this locationId: <%s>.`, locationId))))
 		return
 	}
 
+	lastModified := co2Data.UpdatedAt
+
+	if !raw {
+		calibrated, calibratedAt, err := a.calibrate(c, locationId, []models.Co2Data{co2Data})
+		if err != nil {
+			c.Error(storeProblem(err, "Could not get calibrations.", nil))
+			return
+		}
+		co2Data = calibrated[0]
+		if calibratedAt.After(lastModified) {
+			lastModified = calibratedAt
+		}
+	}
+
 	var co2DataDto models.Co2DataDto
 	dto.Map(&co2DataDto, co2Data)
 
-	c.JSON(http.StatusOK, co2DataDto)
+	cachedJSON(c, co2DataDto, lastModified, ex.LiveCacheControl)
 }
 
 // CreateCo2Data godoc
 //
 //	@Summary		Create co2 data for a location
-//	@Description	Create co2 data by posting a list of co2 data objects.
+//	@Description	Create co2 data by posting a list of co2 data objects. The location is referenced by location_id or location_name, all referenced locations are checked before anything is stored and the errors point to the items referencing unknown or deleted locations. co2 and temp are required, zero included, and have to be within the configured physical ranges. Depending on the config items with values out of range are rejected or quarantined, quarantined items are held back and listed with the status 207. Rejected and quarantined items are kept in the quarantine with the reason and the source, so an admin can fix and replay or discard them. The body and the number of items are limited. With partial=true the valid items are stored even if others are invalid, the response lists the created co2 data and the errors of the rejected items and has the status 207 if any item was rejected.
 //	@Tags			CO2 Data
 //	@Accept			json
 //	@Produce		json
 //	@Success		201		{object}	[]models.Co2DataDto
-//	@Failure		400	{object} string	"Something went wrong, please refer to the error message."
+//	@Success		207		{object}	models.Co2DataBatchDto
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		413	{object} models.ProblemDto	"The body is too large or contains too many items."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
 //	@Router			/co2data/new [post]
 //	@Param			co2data	body		[]models.Co2DataPostDto	 true	"New Co2Data"
+//	@Param			partial	query		bool	 	false	"store the valid items and report the invalid ones"
 //
 // @Security ApiKeyAuth
 func (a *APIEnv) CreateCo2Data(c *gin.Context) {
-	var co2Data []models.Co2Data
-	if err := c.ShouldBindJSON(&co2Data); err != nil {
-		log.Errorf(`Could not parse co2 data from body. Error: <%s>`, err)
-		c.JSON(http.StatusBadRequest, "Could not parse co2 data from body.")
+	raw, err := decodeBatch[json.RawMessage](c)
+	if err != nil {
+		c.Error(batchProblem(err, "Could not parse co2 data from body."))
 		return
 	}
+	co2Data, err := ex.UnmarshalItems[models.Co2Data](raw)
+	if err != nil {
+		c.Error(batchProblem(err, "Could not parse co2 data from body."))
+		return
+	}
+
+	metrics.IngestBatchSize.Observe(float64(len(co2Data)))
 
-	if err := ex.Validator([]models.Co2Data{}).Validate(co2Data); err != nil {
-		log.Errorf(`Missing values in JSON. Error: <%s>`, err)
-		c.JSON(http.StatusBadRequest, err)
+	if len(co2Data) == 0 {
+		c.Error(problem.BadRequest(problem.CodeInvalidBody, "Could not create co2 data. The body contains no co2 data."))
 		return
 	}
 
-	co2Data, err := db_calls.CreateCo2Data(a.DB, co2Data)
+	rules := ex.ReadingRulesFromConfig()
+	quarantine := config.Get().Readings.OutOfRange == config.OutOfRangeQuarantine
+	validate := validateCo2Data(raw, rules, quarantine)
+
+	if partial(c) {
+		created, failed, p := storePartially(co2Data, validate, a.checkCo2Data(c, rules, quarantine), a.storeCo2Data(c), "Could not create co2 data.")
+		if p != nil {
+			c.Error(p)
+			return
+		}
+		failed, quarantined := splitQuarantined(failed)
+		a.ingested(created)
+		a.quarantined(c, quarantined)
+		a.keepRejected(c, raw, failed, quarantined)
+
+		response := models.Co2DataBatchDto{Created: []models.Co2DataDto{}, Failed: failed, Quarantined: quarantined}
+		dto.Map(&response.Created, created)
+
+		c.JSON(batchStatus(failed, quarantined), response)
+		return
+	}
+
+	invalid := []models.FieldErrorDto{}
+	rejected := []models.ItemErrorDto{}
+	for i, data := range co2Data {
+		if fieldErrors := validate(i, data); len(fieldErrors) > 0 {
+			fieldErrors = pointInto(i, fieldErrors)
+			invalid = append(invalid, fieldErrors...)
+			rejected = append(rejected, itemError(i, problem.Validation("Some values are invalid.", fieldErrors)))
+		}
+	}
+	if len(invalid) > 0 {
+		a.keepRejected(c, raw, rejected)
+		c.Error(problem.Validation("Some values in the body are invalid.", invalid))
+		return
+	}
+
+	unknown, err := a.resolveLocations(c, co2Data)
 	if err != nil {
-		log.Errorf(`Could not create co2 data in db. Co2Data: <%#v> Error: <%s>`, co2Data, err)
-		c.JSON(http.StatusBadRequest, "Could not create co2 data.")
+		c.Error(storeProblem(err, "Could not create co2 data.", nil))
+		return
+	}
+	if len(unknown) > 0 {
+		p := problem.BadRequest(problem.CodeUnknownReference, "Could not create co2 data. Some locations do not exist.")
+		for i := range co2Data {
+			if fieldErrors, ok := unknown[i]; ok {
+				fieldErrors = pointInto(i, fieldErrors)
+				p.Errors = append(p.Errors, fieldErrors...)
+				rejected = append(rejected, itemError(i, unknownLocationProblem(fieldErrors)))
+			}
+		}
+		a.keepRejected(c, raw, rejected)
+		c.Error(p)
 		return
 	}
 
+	quarantined := []models.ItemErrorDto{}
+	if quarantine {
+		inRange := []models.Co2Data{}
+		for i, data := range co2Data {
+			if outOfRange := rules.Check(data); len(outOfRange) > 0 {
+				quarantined = append(quarantined, itemError(i, quarantineProblem(pointInto(i, outOfRange))))
+				continue
+			}
+			inRange = append(inRange, data)
+		}
+		co2Data = inRange
+	}
+
+	if len(co2Data) > 0 {
+		co2Data, err = a.storeCo2Data(c)(co2Data)
+		if err != nil {
+			c.Error(storeProblem(err, "Could not create co2 data.", nil))
+			return
+		}
+	}
+	a.ingested(co2Data)
+	a.quarantined(c, quarantined)
+	a.keepRejected(c, raw, quarantined)
+
 	var co2DataDto []models.Co2DataDto
 	dto.Map(&co2DataDto, co2Data)
 
+	if len(quarantined) > 0 {
+		c.JSON(http.StatusMultiStatus, models.Co2DataBatchDto{Created: co2DataDto, Failed: []models.ItemErrorDto{}, Quarantined: quarantined})
+		return
+	}
+
 	c.JSON(http.StatusCreated, co2DataDto)
 }
+
+// validateCo2Data checks the posted co2 data by the validator tags and
+// requires co2 and temp to be present in the body. Values out of range are
+// invalid unless they are quarantined.
+func validateCo2Data(raw []json.RawMessage, rules ex.ReadingRules, quarantine bool) func(index int, data models.Co2Data) []models.FieldErrorDto {
+	validateTags := validateTags[models.Co2Data]()
+
+	return func(index int, data models.Co2Data) []models.FieldErrorDto {
+		fieldErrors := validateTags(index, data)
+		missing := ex.MissingFields(raw[index], "co2", "temp")
+		fieldErrors = append(fieldErrors, missing...)
+		if !quarantine && len(missing) == 0 {
+			fieldErrors = append(fieldErrors, rules.Check(data)...)
+		}
+
+		sort.SliceStable(fieldErrors, func(i, j int) bool {
+			return fieldErrors[i].Pointer < fieldErrors[j].Pointer
+		})
+
+		return fieldErrors
+	}
+}
+
+// quarantineProblem holds back a reading with values out of range.
+func quarantineProblem(outOfRange []models.FieldErrorDto) *problem.Problem {
+	p := problem.New(http.StatusAccepted, problem.CodeQuarantined, "The co2 data was quarantined. Some values are out of range.")
+	p.Errors = outOfRange
+
+	return p
+}
+
+// quarantined counts and logs the held back readings.
+func (a *APIEnv) quarantined(c *gin.Context, items []models.ItemErrorDto) {
+	for _, item := range items {
+		metrics.QuarantinedReadingsTotal.Inc()
+		middleware.Logger(c).Warn("Quarantined co2 data.", "index", item.Index, "errors", item.Errors)
+	}
+}
+
+// keepRejected stores the rejected and quarantined items in the quarantine
+// with their payload as posted, the reason and where they came from, so they
+// can be fixed and replayed. The errors point into the item again. Failing
+// to keep them is logged and does not fail the request.
+func (a *APIEnv) keepRejected(c *gin.Context, raw []json.RawMessage, items ...[]models.ItemErrorDto) {
+	if a.Quarantine == nil {
+		return
+	}
+
+	source := models.QuarantinedCo2Data{
+		APIKey:    logging.RedactKey(c.GetHeader("X-API-KEY")),
+		ClientIP:  c.ClientIP(),
+		RequestID: middleware.GetRequestID(c),
+	}
+	quarantined := []models.QuarantinedCo2Data{}
+	for _, rejected := range items {
+		for _, item := range rejected {
+			if item.Status >= http.StatusInternalServerError {
+				continue
+			}
+			entry := source
+			entry.Payload = compactPayload(raw[item.Index])
+			entry.Code = item.Code
+			entry.Reason = item.Detail
+			entry.Errors = pointOutOf(item.Index, item.Errors)
+			quarantined = append(quarantined, entry)
+		}
+	}
+	if len(quarantined) == 0 {
+		return
+	}
+
+	if _, err := a.quarantine(c).Create(quarantined); err != nil {
+		middleware.Logger(c).Error("Could not keep the rejected co2 data in the quarantine.", "count", len(quarantined), "error", err)
+	}
+}
+
+// unknownLocationProblem rejects a reading referencing a location which does
+// not exist.
+func unknownLocationProblem(fieldErrors []models.FieldErrorDto) *problem.Problem {
+	p := problem.BadRequest(problem.CodeUnknownReference, "Could not create co2 data. The location does not exist.")
+	p.Errors = fieldErrors
+
+	return p
+}
+
+// resolveLocations looks up the referenced locations of all co2 data at once
+// and sets the location id of the co2 data posted by location name. It
+// returns the field errors of the items referencing a location which does
+// not exist or was deleted, by their index.
+func (a *APIEnv) resolveLocations(c *gin.Context, co2Data []models.Co2Data) (map[int][]models.FieldErrorDto, error) {
+	ids := []int{}
+	names := []string{}
+	for _, data := range co2Data {
+		if data.LocationID != 0 {
+			ids = append(ids, data.LocationID)
+		}
+		if data.LocationName != "" {
+			names = append(names, data.LocationName)
+		}
+	}
+
+	locations, err := a.locations(c).GetByIdsOrNames(ids, names)
+	if err != nil {
+		return nil, err
+	}
+	byId := map[int]models.Location{}
+	byName := map[string]models.Location{}
+	for _, location := range locations {
+		byId[int(location.ID)] = location
+		byName[location.Name] = location
+	}
+
+	unknown := map[int][]models.FieldErrorDto{}
+	for i := range co2Data {
+		data := &co2Data[i]
+		if _, ok := byId[data.LocationID]; data.LocationID != 0 && !ok {
+			unknown[i] = append(unknown[i], models.FieldErrorDto{Pointer: "/location_id", Detail: fmt.Sprintf("Location <%d> does not exist.", data.LocationID)})
+		}
+		if data.LocationName == "" {
+			continue
+		}
+
+		location, ok := byName[data.LocationName]
+		switch {
+		case !ok:
+			unknown[i] = append(unknown[i], models.FieldErrorDto{Pointer: "/location_name", Detail: fmt.Sprintf("Location <%s> does not exist.", data.LocationName)})
+		case data.LocationID == 0:
+			data.LocationID = int(location.ID)
+		case data.LocationID != int(location.ID):
+			unknown[i] = append(unknown[i], models.FieldErrorDto{Pointer: "/location_name", Detail: fmt.Sprintf("Location <%s> does not have the id <%d>.", data.LocationName, data.LocationID)})
+		}
+	}
+
+	return unknown, nil
+}
+
+// checkCo2Data rejects the co2 data of a partial ingest which references
+// unknown locations and holds back the one with values out of range if they
+// are quarantined.
+func (a *APIEnv) checkCo2Data(c *gin.Context, rules ex.ReadingRules, quarantine bool) func(co2Data []models.Co2Data) (map[int]*problem.Problem, error) {
+	return func(co2Data []models.Co2Data) (map[int]*problem.Problem, error) {
+		unknown, err := a.resolveLocations(c, co2Data)
+		if err != nil {
+			return nil, err
+		}
+
+		problems := map[int]*problem.Problem{}
+		for i, data := range co2Data {
+			if fieldErrors, ok := unknown[i]; ok {
+				problems[i] = unknownLocationProblem(fieldErrors)
+				continue
+			}
+			if outOfRange := rules.Check(data); quarantine && len(outOfRange) > 0 {
+				problems[i] = quarantineProblem(outOfRange)
+			}
+		}
+
+		return problems, nil
+	}
+}
+
+// storeCo2Data flags the anomalies of new readings before storing them and
+// records the attempt for the ingest health check. Errors caused by the
+// client, e.g. unknown references, are not recorded, so clients cannot make
+// the instance look unhealthy.
+func (a *APIEnv) storeCo2Data(c *gin.Context) func(co2Data []models.Co2Data) ([]models.Co2Data, error) {
+	return func(co2Data []models.Co2Data) ([]models.Co2Data, error) {
+		co2Data, err := a.co2Data(c).Create(a.detectAnomalies(c, co2Data))
+		if err == nil || storeProblem(err, "", nil).Status >= http.StatusInternalServerError {
+			health.Ingest.Record(err)
+		}
+
+		return co2Data, err
+	}
+}
+
+// ingested counts the stored readings and marks their locations as online.
+func (a *APIEnv) ingested(co2Data []models.Co2Data) {
+	for _, data := range co2Data {
+		metrics.IngestedReadingsTotal.WithLabelValues(data.Quality).Inc()
+		if a.Heartbeat != nil {
+			a.Heartbeat.Touch(uint(data.LocationID), data.CreatedAt)
+		}
+	}
+}
+
+// GetCo2DataForecast godoc
+//
+//	@Summary		Get a co2 forecast for a location
+//	@Description	Get predicted co2 values for a location by passing a location id as parameter and a horizon (1m, 1h, max. 24h) as query parameter. The model is fitted to the calibrated readings of the last hour and also estimates when the threshold will be crossed. A longer horizon, a threshold which is not a positive number or an unknown model is rejected.
+//	@Tags			CO2 Data
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	models.ForecastDto
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/co2data/{id}/forecast [get]
+//	@Param			id	path		int	 	true	"LocationId"
+//	@Param			horizon	query		string	 	false	"forecast horizon" example(60m)
+//	@Param			threshold	query		int	 	false	"co2 threshold in ppm" example(1000)
+//	@Param			model	query		string	 	false	"forecast model" Enums(holt, linear)
+//	@Param			exclude_anomalies	query		bool	 	false	"leave out readings flagged as anomalies"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) GetCo2DataForecast(c *gin.Context) {
+	locationId := c.Param("id")
+
+	maxHorizon := ex.MaxForecastHorizon
+	if maxPeriod := config.Get().Query.MaxPeriod; maxPeriod < maxHorizon {
+		maxHorizon = maxPeriod
+	}
+	horizon, ok := queryPeriodUpTo(c, "horizon", ex.DefaultForecastHorizon, maxHorizon)
+	if !ok {
+		return
+	}
+
+	threshold := ex.VentilationThreshold
+	if input := c.Query("threshold"); input != "" {
+		parsed, err := strconv.Atoi(input)
+		if err != nil || parsed <= 0 {
+			c.Error(problem.BadRequest(problem.CodeInvalidParameter, fmt.Sprintf(`Invalid threshold: <%s>. Use a positive number of ppm, e.g. 1000.`, input)))
+			return
+		}
+		threshold = parsed
+	}
+
+	model := c.DefaultQuery("model", ex.ForecastModels[0])
+	if !slices.Contains(ex.ForecastModels, model) {
+		detail := fmt.Sprintf(`Unknown model, use one of: %s.`, strings.Join(ex.ForecastModels, ", "))
+		p := problem.BadRequest(problem.CodeInvalidParameter, fmt.Sprintf(`Invalid model: <%s>. %s`, model, detail))
+		p.Errors = []models.FieldErrorDto{{Pointer: "model", Detail: detail}}
+		c.Error(p)
+		return
+	}
+
+	if _, err := a.locations(c).GetById(locationId); err != nil {
+		c.Error(storeProblem(err, "Could not get location.", problem.NotFound(problem.CodeLocationNotFound, fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId))))
+		return
+	}
+
+	co2Data, err := a.co2Data(c).GetByTimeFrame(locationId, ex.ForecastWindow, c.Query("exclude_anomalies") == "true")
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get co2 data.", nil))
+		return
+	}
+
+	if co2Data, _, err = a.calibrate(c, locationId, co2Data); err != nil {
+		c.Error(storeProblem(err, "Could not get calibrations.", nil))
+		return
+	}
+
+	forecast, ok := ex.ForecastCo2(co2Data, model, horizon, threshold)
+	if !ok {
+		c.Error(problem.NotFound(problem.CodeNotEnoughData, fmt.Sprintf(`Not enough co2 data to forecast for locationId: <%s>.`, locationId)))
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// GetCo2DataAnomalies godoc
+//
+//	@Summary		Get co2 data flagged as anomalies
+//	@Description	Get all readings of a location which were flagged as out of range, jump, stuck or drift by passing a location id as parameter and a time frame as query parameter. The time frame is from now minus [period] (e.g. 30m, 6h, 1d12h, 2w or P7D, 6h by default).
+//	@Tags			CO2 Data
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	[]models.Co2DataDto
+//	@Header			200		{string}	ETag	"entity tag of the anomalies"
+//	@Success		304		"the anomalies did not change"
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/co2data/{id}/anomalies [get]
+//	@Param			id	path		int	 	true	"LocationId"
+//	@Param			period	query		string	 	false	"time frame" example(7d)
+//	@Param			If-None-Match	header		string	 	false	"ETag of a previous response"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) GetCo2DataAnomalies(c *gin.Context) {
+	locationId := c.Param("id")
+
+	duration, ok := queryPeriod(c, "period", ex.DefaultPeriod)
+	if !ok {
+		return
+	}
+
+	if _, err := a.locations(c).GetById(locationId); err != nil {
+		c.Error(storeProblem(err, "Could not get location.", problem.NotFound(problem.CodeLocationNotFound, fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId))))
+		return
+	}
+
+	co2Data, err := a.co2Data(c).GetAnomalies(locationId, duration)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get anomalies.", nil))
+		return
+	}
+
+	var co2DataDto []models.Co2DataDto
+	dto.Map(&co2DataDto, co2Data)
+
+	cachedJSON(c, co2DataDto, time.Time{}, ex.LiveCacheControl)
+}
+
+// GetCo2DataAggregates godoc
+//
+//	@Summary		Get hourly or daily co2 data aggregates
+//	@Description	Get the average, minimum and maximum co2 and the average temperature per hour or day by passing a location id as parameter and a time frame as query parameter. The time frame is from now minus [period] (e.g. 30m, 6h, 1d12h, 2w or P7D, 6h by default) and starts with the whole hour or day. Readings flagged as anomalies can be left out. Values are calibrated unless raw values are requested.
+//	@Tags			CO2 Data
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	[]models.Co2AggregateDto
+//	@Header			200		{string}	ETag	"entity tag of the aggregates"
+//	@Success		304		"the aggregates did not change"
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/co2data/{id}/aggregate [get]
+//	@Param			id	path		int	 	true	"LocationId"
+//	@Param			interval	query		string	 	false	"hour or day" default(hour)
+//	@Param			period	query		string	 	false	"time frame" example(7d)
+//	@Param			exclude_anomalies	query		bool	 	false	"leave out readings flagged as anomalies"
+//	@Param			raw	query		bool	 	false	"return the values without calibration"
+//	@Param			If-None-Match	header		string	 	false	"ETag of a previous response"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) GetCo2DataAggregates(c *gin.Context) {
+	locationId := c.Param("id")
+	interval := c.DefaultQuery("interval", models.AggregateHour)
+	excludeAnomalies := c.Query("exclude_anomalies") == "true"
+	raw := c.Query("raw") == "true"
+
+	if interval != models.AggregateHour && interval != models.AggregateDay {
+		c.Error(problem.BadRequest(problem.CodeInvalidParameter, fmt.Sprintf(`Invalid interval: <%s>. Use %s or %s.`, interval, models.AggregateHour, models.AggregateDay)))
+		return
+	}
+
+	duration, ok := queryPeriod(c, "period", ex.DefaultPeriod)
+	if !ok {
+		return
+	}
+
+	if _, err := a.locations(c).GetById(locationId); err != nil {
+		c.Error(storeProblem(err, "Could not get location.", problem.NotFound(problem.CodeLocationNotFound, fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId))))
+		return
+	}
+
+	aggregates, err := a.co2Data(c).GetAggregates(locationId, interval, time.Now().Add(-duration), excludeAnomalies)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not aggregate co2 data.", nil))
+		return
+	}
+
+	if !raw {
+		calibrations, err := a.calibrations(c).GetByLocation(locationId)
+		if err != nil {
+			c.Error(storeProblem(err, "Could not get calibrations.", nil))
+			return
+		}
+		aggregates = ex.ApplyCalibrationsToAggregates(aggregates, calibrations)
+	}
+
+	var aggregateDto []models.Co2AggregateDto
+	dto.Map(&aggregateDto, aggregates)
+
+	cachedJSON(c, aggregateDto, time.Time{}, ex.LiveCacheControl)
+}
+
+// detectAnomalies flags the new readings by comparing them with the stored
+// readings of their locations. Readings without a timestamp are stamped with
+// the current time, so they can be compared.
+func (a *APIEnv) detectAnomalies(c *gin.Context, co2Data []models.Co2Data) []models.Co2Data {
+	rules := ex.AnomalyRulesFromConfig()
+	now := time.Now()
+	since := now
+
+	locationIds := []int{}
+	for i := range co2Data {
+		if co2Data[i].CreatedAt.IsZero() {
+			co2Data[i].CreatedAt = now
+		}
+		if co2Data[i].CreatedAt.Before(since) {
+			since = co2Data[i].CreatedAt
+		}
+		locationIds = append(locationIds, co2Data[i].LocationID)
+	}
+
+	history, err := a.co2Data(c).GetSince(locationIds, since.Add(-rules.HistoryWindow()))
+	if err != nil {
+		middleware.Logger(c).Error("Could not load previous co2 data for anomaly detection.", "error", err)
+	}
+
+	return ex.DetectAnomalies(history, co2Data, rules)
+}
+
+// calibrate corrects the readings of a location with its calibrations and
+// returns the latest update of the calibrations.
+func (a *APIEnv) calibrate(c *gin.Context, locationId string, co2Data []models.Co2Data) ([]models.Co2Data, time.Time, error) {
+	calibrations, err := a.calibrations(c).GetByLocation(locationId)
+	if err != nil {
+		return co2Data, time.Time{}, err
+	}
+
+	return ex.ApplyCalibrations(co2Data, calibrations), ex.LastModified(calibrations, calibrationUpdatedAt), nil
+}
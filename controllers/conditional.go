@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// cachedJSON responds with the value together with its ETag, Last-Modified
+// and Cache-Control headers or with 304 Not Modified when the conditional
+// headers of the request show that the client already has it. Lists pass
+// the zero time and only get the ETag, the latest update of their rows does
+// not change when rows are deleted or drop out of a time frame.
+func cachedJSON(c *gin.Context, value any, lastModified time.Time, cacheControl string) {
+	c.Header("Cache-Control", cacheControl)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	etag, err := ex.ETag(value)
+	if err != nil {
+		middleware.Logger(c).Error("Could not create the etag of the response.", "error", err)
+		c.JSON(http.StatusOK, value)
+		return
+	}
+
+	c.Header("ETag", etag)
+	if ex.NotModified(c.GetHeader("If-None-Match"), c.GetHeader("If-Modified-Since"), etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, value)
+}
+
+func calibrationUpdatedAt(calibration models.Calibration) time.Time {
+	return calibration.UpdatedAt
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/fminister/co2monitor.api/repositories"
+)
+
+// storeProblem classifies an error of a repository. A missing entity becomes
+// the notFound problem, a duplicate name a 409 and a reference to a missing
+// location a 400. Everything else is a failure of the database and becomes a
+// 500 with the detail, which never contains the database error.
+func storeProblem(err error, detail string, notFound *problem.Problem) *problem.Problem {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound) && notFound != nil:
+		return notFound.WithCause(err)
+	case errors.Is(err, repositories.ErrDuplicate):
+		return problem.Conflict(problem.CodeDuplicateName, detail+" Name already exists.").WithCause(err)
+	case errors.Is(err, repositories.ErrInvalidReference):
+		return problem.BadRequest(problem.CodeUnknownReference, detail+" The location does not exist.").WithCause(err)
+	default:
+		return problem.Internal(detail).WithCause(err)
+	}
+}
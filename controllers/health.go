@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/fminister/co2monitor.api/health"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetLiveness answers as long as the process is able to serve requests.
+// It is served outside of /api and does not need an api key.
+func (a *APIEnv) GetLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Liveness())
+}
+
+// GetReadiness checks the database, the migrations, the background workers
+// and the ingestion. It responds with 503 when one of the checks fails and
+// with 200 when the service is ok or only degraded.
+// It is served outside of /api and does not need an api key.
+func (a *APIEnv) GetReadiness(c *gin.Context) {
+	readiness := a.Health.Readiness(c.Request.Context(), middleware.Logger(c))
+
+	if readiness.Status == models.HealthUnavailable {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	c.JSON(http.StatusOK, readiness)
+}
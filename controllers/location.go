@@ -2,13 +2,13 @@ package controllers
 
 import (
 	"net/http"
+	"time"
 
-	"github.com/charmbracelet/log"
 	"github.com/dranikpg/dto-mapper"
 
-	"github.com/fminister/co2monitor.api/db/db_calls"
 	ex "github.com/fminister/co2monitor.api/extensions"
 	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,22 +22,47 @@ import (
 //	@Accept			json
 //	@Produce		json
 //	@Success		200		{object}	[]models.LocationDto
-//	@Failure		404	{object} string	"Something went wrong, please refer to the error message."
+//	@Header			200		{string}	ETag	"entity tag of the locations"
+//	@Success		304		"the locations did not change"
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
 //	@Router			/location [get]
+//	@Param			If-None-Match	header		string	 	false	"ETag of a previous response"
 //
 // @Security ApiKeyAuth
 func (a *APIEnv) GetLocations(c *gin.Context) {
-	locations, err := db_calls.GetLocation(a.DB)
+	locations, err := a.locations(c).GetAll()
 	if err != nil {
-		log.Errorf(`Could not find any locations. Error: <%s>`, err)
-		c.JSON(http.StatusNotFound, "Could not find any locations.")
+		c.Error(storeProblem(err, "Could not get locations.", nil))
 		return
 	}
 
 	var locationDto []models.LocationDto
 	dto.Map(&locationDto, locations)
 
-	c.JSON(http.StatusOK, locationDto)
+	cachedJSON(c, locationDto, time.Time{}, ex.LiveCacheControl)
+}
+
+// GetLocationStatus godoc
+//
+//	@Summary		Get the status of all locations
+//	@Description	Get whether each location is online or offline and when it reported its last reading. A location is offline if it has been silent for longer than the configured silence window.
+//	@Tags			Locations
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	[]models.LocationStatusDto
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		503	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/location/status [get]
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) GetLocationStatus(c *gin.Context) {
+	if a.Heartbeat == nil || !a.Heartbeat.Running() {
+		c.Error(problem.New(http.StatusServiceUnavailable, problem.CodeUnavailable, "Location status is not available."))
+		return
+	}
+
+	c.JSON(http.StatusOK, a.Heartbeat.Statuses())
 }
 
 // GetLocationBySearch godoc
@@ -48,60 +73,85 @@ func (a *APIEnv) GetLocations(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Success		200		{object}	[]models.LocationDto
-//	@Failure		404	{object} string	"Something went wrong, please refer to the error message."
+//	@Header			200		{string}	ETag	"entity tag of the locations"
+//	@Success		304		"the locations did not change"
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
 //	@Router			/location/search [get]
 //	@Param			id	query		string	 	false	"LocationId" example(1)
 //	@Param			name	query		string	 	false	"Name of location" example(Office)
+//	@Param			If-None-Match	header		string	 	false	"ETag of a previous response"
 //
 // @Security ApiKeyAuth
 func (a *APIEnv) GetLocationBySearch(c *gin.Context) {
 	id := c.Query("id")
 	name := c.Query("name")
 
-	locations, err := db_calls.GetLocationBySearch(a.DB, id, name)
+	locations, err := a.locations(c).Search(id, name)
 	if err != nil {
-		log.Errorf(`Could not find any locations by id or name. id: <%s>; name: <%s>; Error: <%s>`, id, name, err)
-		c.JSON(http.StatusNotFound, "Could not find any locations.")
+		c.Error(storeProblem(err, "Could not search locations.", nil))
 		return
 	}
 
 	var locationDto []models.LocationDto
 	dto.Map(&locationDto, locations)
 
-	c.JSON(http.StatusOK, locationDto)
+	cachedJSON(c, locationDto, time.Time{}, ex.LiveCacheControl)
 }
 
 // CreateLocation godoc
 //
 //	@Summary		Create a new location
-//	@Description	Create a new location by posting a list of location objects.
+//	@Description	Create a new location by posting a list of location objects. The body and the number of items are limited. With partial=true the valid locations are created even if others are invalid, the response lists the created locations and the errors of the rejected items and has the status 207 if any item was rejected.
 //	@Tags			Locations
 //	@Accept			json
 //	@Produce		json
 //	@Success		201		{object}	[]models.LocationDto
-//	@Failure		400	{object} string	"Something went wrong, please refer to the error message."
+//	@Success		207		{object}	models.LocationBatchDto
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		409	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		413	{object} models.ProblemDto	"The body is too large or contains too many items."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
 //	@Router			/location/new [post]
 //	@Param			location	body		[]models.LocationPostDto	 true	"New Location"
+//	@Param			partial	query		bool	 	false	"create the valid locations and report the invalid ones"
 //
 // @Security ApiKeyAuth
 func (a *APIEnv) CreateLocation(c *gin.Context) {
-	var locations []models.Location
-	if err := c.ShouldBindJSON(&locations); err != nil {
-		log.Errorf(`Could not parse location from body. Error: <%s>`, err)
-		c.JSON(http.StatusBadRequest, "Could not parse location from body.")
+	locations, err := decodeBatch[models.Location](c)
+	if err != nil {
+		c.Error(batchProblem(err, "Could not parse location from body."))
+		return
+	}
+
+	if len(locations) == 0 {
+		c.Error(problem.BadRequest(problem.CodeInvalidBody, "Could not create location. The body contains no locations."))
+		return
+	}
+
+	if partial(c) {
+		created, failed, p := storePartially(locations, nil, nil, a.locations(c).Create, "Could not create location.")
+		if p != nil {
+			c.Error(p)
+			return
+		}
+
+		response := models.LocationBatchDto{Created: []models.LocationDto{}, Failed: failed}
+		dto.Map(&response.Created, created)
+
+		c.JSON(batchStatus(failed), response)
 		return
 	}
 
 	if err := ex.Validator([]models.Location{}).Validate(locations); err != nil {
-		log.Errorf(`Missing values in JSON. Error: <%s>`, err)
-		c.JSON(http.StatusBadRequest, err)
+		c.Error(problem.Validation("Some values in the body are invalid.", ex.ValidationErrors(err)))
 		return
 	}
 
-	locations, err := db_calls.CreateLocation(a.DB, locations)
+	locations, err = a.locations(c).Create(locations)
 	if err != nil {
-		log.Errorf(`Could not create location in db. Locations: <%#v> Error: <%s>`, locations, err)
-		c.JSON(http.StatusBadRequest, "Could not create location. Name already exists.")
+		c.Error(storeProblem(err, "Could not create location.", nil))
 		return
 	}
 
@@ -114,13 +164,16 @@ func (a *APIEnv) CreateLocation(c *gin.Context) {
 // UpdateLocation godoc
 //
 //	@Summary		Update a location
-//	@Description	Update a location by posting a location object.
+//	@Description	Update a location by posting a location object. Fields missing in the body keep their values.
 //	@Tags			Locations
 //	@Accept			json
 //	@Produce		json
 //	@Success		200		{object}	models.LocationDto
-//	@Failure		400	{object} string	"Something went wrong, please refer to the error message."
-//	@Failure		404	{object} string	"Something went wrong, please refer to the error message."
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		409	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
 //	@Router			/location/{id} [patch]
 //	@Param			id	path		int	 	true	"LocationId"
 //	@Param			location	body		models.LocationPostDto	 true	"Update Location"
@@ -129,29 +182,32 @@ func (a *APIEnv) CreateLocation(c *gin.Context) {
 func (a *APIEnv) UpdateLocation(c *gin.Context) {
 	locationId := c.Param("id")
 
-	if _, err := db_calls.GetLocationById(a.DB, locationId); err != nil {
-		log.Errorf(`Could not find location by id. id: <%s>; Error: <%s>`, locationId, err)
-		c.JSON(http.StatusNotFound, "Could not find location by id.")
+	existing, err := a.locations(c).GetById(locationId)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get location.", problem.NotFound(problem.CodeLocationNotFound, "Could not find location by id.")))
 		return
 	}
 
-	var location models.Location
+	// the body is merged into the stored location, so omitted fields like
+	// the room volume are not reset
+	location := existing
 	if err := c.ShouldBindJSON(&location); err != nil {
-		log.Errorf(`Could not parse location details from body. Error: <%s>`, err)
-		c.JSON(http.StatusBadRequest, "Could not parse location details from body.")
+		c.Error(problem.BadRequest(problem.CodeInvalidBody, "Could not parse location details from body.").WithCause(err))
 		return
 	}
 
 	if err := ex.Validator(models.Location{}).Validate(location); err != nil {
-		log.Errorf(`Missing values in JSON. Error: <%s>`, err)
-		c.JSON(http.StatusBadRequest, err)
+		c.Error(problem.Validation("Some values in the body are invalid.", ex.ValidationErrors(err)))
 		return
 	}
 
-	location, err := db_calls.UpdateLocation(a.DB, location)
+	// The path names the location, an id in the body must not create or
+	// overwrite another one.
+	location.Model = existing.Model
+
+	location, err = a.locations(c).Update(location)
 	if err != nil {
-		log.Errorf(`Could not update location in db. Location: <%#v> Error: <%s>`, location, err)
-		c.JSON(http.StatusNotFound, "Could not update location.")
+		c.Error(storeProblem(err, "Could not update location.", nil))
 		return
 	}
 
@@ -169,7 +225,9 @@ func (a *APIEnv) UpdateLocation(c *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Success		204 "Deleted successfully"
-//	@Failure		404	{object} string	"Something went wrong, please refer to the error message."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
 //	@Router			/location/{id} [delete]
 //	@Param			id	path		int	 	true	"LocationId"
 //
@@ -177,19 +235,17 @@ func (a *APIEnv) UpdateLocation(c *gin.Context) {
 func (a *APIEnv) DeleteLocation(c *gin.Context) {
 	locationId := c.Param("id")
 
-	location, err := db_calls.GetLocationById(a.DB, locationId)
+	location, err := a.locations(c).GetById(locationId)
 	if err != nil {
-		log.Errorf(`Could not find location by id. id: <%s>; Error: <%s>`, locationId, err)
-		c.JSON(http.StatusNotFound, "Could not find location by id.")
+		c.Error(storeProblem(err, "Could not get location.", problem.NotFound(problem.CodeLocationNotFound, "Could not find location by id.")))
 		return
 	}
 
-	err = db_calls.DeleteLocation(a.DB, location)
+	err = a.locations(c).Delete(location)
 	if err != nil {
-		log.Errorf(`Could not delete location in db. Location: <%#v> Error: <%s>`, location, err)
-		c.JSON(http.StatusNotFound, "Could not delete location.")
+		c.Error(storeProblem(err, "Could not delete location.", nil))
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/dranikpg/dto-mapper"
+
+	"github.com/fminister/co2monitor.api/config"
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api
+
+// GetQuarantinedCo2Data godoc
+//
+//	@Summary		Get the quarantined co2 data
+//	@Description	Get all posted co2 data items which were rejected or held back, the newest first. Every entry contains the payload as posted, the reason and the errors, and the redacted api key, client ip and request id of the ingest. Requires the admin api key.
+//	@Tags			Quarantine
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	[]models.QuarantinedCo2DataDto
+//	@Failure		401	{object} models.ProblemDto	"The admin api key is missing."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/quarantine/ [get]
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) GetQuarantinedCo2Data(c *gin.Context) {
+	quarantined, err := a.quarantine(c).GetAll()
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get quarantined co2 data.", nil))
+		return
+	}
+
+	quarantinedDto := []models.QuarantinedCo2DataDto{}
+	dto.Map(&quarantinedDto, quarantined)
+
+	c.JSON(http.StatusOK, quarantinedDto)
+}
+
+// GetQuarantinedCo2DataById godoc
+//
+//	@Summary		Get quarantined co2 data by id
+//	@Description	Get a quarantined co2 data item by passing its id as parameter. Requires the admin api key.
+//	@Tags			Quarantine
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	models.QuarantinedCo2DataDto
+//	@Failure		401	{object} models.ProblemDto	"The admin api key is missing."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/quarantine/{id} [get]
+//	@Param			id	path		int	 	true	"QuarantineId"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) GetQuarantinedCo2DataById(c *gin.Context) {
+	quarantined, ok := a.getQuarantined(c)
+	if !ok {
+		return
+	}
+
+	var quarantinedDto models.QuarantinedCo2DataDto
+	dto.Map(&quarantinedDto, quarantined)
+
+	c.JSON(http.StatusOK, quarantinedDto)
+}
+
+// ReplayQuarantinedCo2Data godoc
+//
+//	@Summary		Replay quarantined co2 data
+//	@Description	Store a quarantined co2 data item by passing its id as parameter. The body may contain the fixed co2 data object, the payload as posted is replayed if the body is empty. The co2 data is validated like a new one, but values out of range are always rejected. It keeps the time it was posted at. The entry is removed from the quarantine before the co2 data is stored and put back if storing fails, so concurrent replays store it only once. Requires the admin api key.
+//	@Tags			Quarantine
+//	@Accept			json
+//	@Produce		json
+//	@Success		201		{object}	models.Co2DataDto
+//	@Failure		400	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		401	{object} models.ProblemDto	"The admin api key is missing."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		413	{object} models.ProblemDto	"The body is too large."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/quarantine/{id}/replay [post]
+//	@Param			id	path		int	 	true	"QuarantineId"
+//	@Param			co2data	body		models.Co2DataPostDto	 false	"Fixed Co2Data"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) ReplayQuarantinedCo2Data(c *gin.Context) {
+	quarantined, ok := a.getQuarantined(c)
+	if !ok {
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, int64(config.Get().Ingest.MaxBodyBytes)))
+	if err != nil {
+		c.Error(batchProblem(err, "Could not read co2 data from body."))
+		return
+	}
+	if len(bytes.TrimSpace(payload)) == 0 {
+		payload = quarantined.Payload
+	}
+
+	co2Data := []models.Co2Data{{}}
+	if err := json.Unmarshal(payload, &co2Data[0]); err != nil {
+		c.Error(problem.BadRequest(problem.CodeInvalidBody, "Could not parse co2 data from body.").WithCause(err))
+		return
+	}
+	// the reading was measured when it was posted, not when it is replayed
+	if co2Data[0].CreatedAt.IsZero() {
+		co2Data[0].CreatedAt = quarantined.CreatedAt
+	}
+
+	if invalid := validateCo2Data([]json.RawMessage{payload}, ex.ReadingRulesFromConfig(), false)(0, co2Data[0]); len(invalid) > 0 {
+		c.Error(problem.Validation("Some values are invalid.", invalid))
+		return
+	}
+
+	unknown, err := a.resolveLocations(c, co2Data)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not replay co2 data.", nil))
+		return
+	}
+	if fieldErrors, ok := unknown[0]; ok {
+		p := problem.BadRequest(problem.CodeUnknownReference, "Could not replay co2 data. The location does not exist.")
+		p.Errors = fieldErrors
+		c.Error(p)
+		return
+	}
+
+	// removing the entry first claims it, a second replay finds it gone
+	// instead of storing the co2 data twice
+	if err := a.quarantine(c).Delete(quarantined); err != nil {
+		c.Error(storeProblem(err, "Could not replay co2 data.", problem.NotFound(problem.CodeQuarantineNotFound, fmt.Sprintf(`Could not find any quarantined co2 data with this id: <%d>.`, quarantined.ID))))
+		return
+	}
+
+	created, err := a.storeCo2Data(c)(co2Data)
+	if err != nil {
+		if err := a.quarantine(c).Restore(quarantined); err != nil {
+			middleware.Logger(c).Error("Could not put the co2 data back into the quarantine.", "id", quarantined.ID, "error", err)
+		}
+		c.Error(storeProblem(err, "Could not replay co2 data.", nil))
+		return
+	}
+	a.ingested(created)
+
+	var co2DataDto models.Co2DataDto
+	dto.Map(&co2DataDto, created[0])
+
+	c.JSON(http.StatusCreated, co2DataDto)
+}
+
+// DiscardQuarantinedCo2Data godoc
+//
+//	@Summary		Discard quarantined co2 data
+//	@Description	Remove a quarantined co2 data item without storing it by passing its id as parameter. Requires the admin api key.
+//	@Tags			Quarantine
+//	@Accept			json
+//	@Produce		json
+//	@Success		204 "Discarded successfully"
+//	@Failure		401	{object} models.ProblemDto	"The admin api key is missing."
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/quarantine/{id} [delete]
+//	@Param			id	path		int	 	true	"QuarantineId"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) DiscardQuarantinedCo2Data(c *gin.Context) {
+	quarantined, ok := a.getQuarantined(c)
+	if !ok {
+		return
+	}
+
+	if err := a.quarantine(c).Delete(quarantined); err != nil {
+		c.Error(storeProblem(err, "Could not discard quarantined co2 data.", problem.NotFound(problem.CodeQuarantineNotFound, fmt.Sprintf(`Could not find any quarantined co2 data with this id: <%d>.`, quarantined.ID))))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getQuarantined looks up the quarantined co2 data of the id parameter and
+// reports a problem if there is none.
+func (a *APIEnv) getQuarantined(c *gin.Context) (models.QuarantinedCo2Data, bool) {
+	quarantineId := c.Param("id")
+
+	quarantined, err := a.quarantine(c).GetById(quarantineId)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get quarantined co2 data.", problem.NotFound(problem.CodeQuarantineNotFound, fmt.Sprintf(`Could not find any quarantined co2 data with this id: <%s>.`, quarantineId))))
+		return models.QuarantinedCo2Data{}, false
+	}
+
+	return quarantined, true
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// queryPeriod parses the period query parameter with the given name, or
+// returns the fallback if it is not set. An invalid period is added to the
+// context as 400 and ok is false.
+func queryPeriod(c *gin.Context, name string, fallback time.Duration) (period time.Duration, ok bool) {
+	return queryPeriodUpTo(c, name, fallback, config.Get().Query.MaxPeriod)
+}
+
+// queryPeriodUpTo parses the period like queryPeriod, but rejects periods
+// longer than max instead of the configured max period.
+func queryPeriodUpTo(c *gin.Context, name string, fallback time.Duration, max time.Duration) (period time.Duration, ok bool) {
+	input := c.Query(name)
+	if input == "" {
+		return fallback, true
+	}
+
+	period, err := ex.ParsePeriod(input, max)
+	if err != nil {
+		reason := err.Error()
+		var periodErr *ex.PeriodError
+		if errors.As(err, &periodErr) {
+			reason = periodErr.Reason
+		}
+		c.Error(problem.BadRequest(problem.CodeInvalidParameter, fmt.Sprintf(`Invalid %s: <%s>. %s`, name, input, reason)).WithCause(err))
+		return 0, false
+	}
+
+	return period, true
+}
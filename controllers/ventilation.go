@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api
+
+// GetVentilationRecommendation godoc
+//
+//	@Summary		Get a ventilation recommendation for a location
+//	@Description	Get a recommendation whether a location should be aired now, in a few minutes or not at all. It is based on the calibrated co2 slope of the last 30 minutes without anomalies or, if there are not enough readings, on the room volume and occupancy of the location. If there are no readings in the last 30 minutes the latest one is used, unless it is older than two hours.
+//	@Tags			Locations
+//	@Accept			json
+//	@Produce		json
+//	@Success		200		{object}	models.VentilationDto
+//	@Failure		404	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Failure		429	{object} models.ProblemDto	"Too many requests, retry after the seconds of the Retry-After header."
+//	@Failure		500	{object} models.ProblemDto	"Something went wrong, please refer to the error message."
+//	@Router			/location/{id}/ventilation [get]
+//	@Param			id	path		int	 	true	"LocationId"
+//
+// @Security ApiKeyAuth
+func (a *APIEnv) GetVentilationRecommendation(c *gin.Context) {
+	locationId := c.Param("id")
+
+	location, err := a.locations(c).GetById(locationId)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get location.", problem.NotFound(problem.CodeLocationNotFound, fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId))))
+		return
+	}
+
+	// a single faulty reading must not trigger the traffic light
+	co2Data, err := a.co2Data(c).GetByTimeFrame(locationId, ex.VentilationWindow, true)
+	if err != nil {
+		c.Error(storeProblem(err, "Could not get co2 data.", nil))
+		return
+	}
+
+	if len(co2Data) == 0 {
+		latest, err := a.co2Data(c).GetLatest(locationId, true)
+		if err != nil {
+			c.Error(storeProblem(err, "Could not get co2 data.", problem.NotFound(problem.CodeCo2DataNotFound, fmt.Sprintf(`Could not find any co2 data with this locationId: <%s>.`, locationId))))
+			return
+		}
+		if time.Since(latest.CreatedAt) > ex.VentilationMaxReadingAge {
+			c.Error(problem.NotFound(problem.CodeCo2DataNotFound, fmt.Sprintf(`Could not find any recent co2 data with this locationId: <%s>. The latest reading is older than %s.`, locationId, ex.VentilationMaxReadingAge)))
+			return
+		}
+		co2Data = []models.Co2Data{latest}
+	}
+
+	if co2Data, _, err = a.calibrate(c, locationId, co2Data); err != nil {
+		c.Error(storeProblem(err, "Could not get calibrations.", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, ex.RecommendVentilation(location, co2Data))
+}
@@ -3,56 +3,119 @@ package db
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 )
 
 type DbInstance struct {
 	Db *gorm.DB
+	// Timescale is true when the co2 data is a hypertable with continuous aggregates.
+	Timescale bool
 }
 
 var DB DbInstance
 
 func ConnectToDb() {
-	dsn := os.Getenv("DATABASE_URL_DEV")
+	var db *gorm.DB
+	var err error
 
-	if os.Getenv("APP_ENV") != "development" {
-		dsn = os.Getenv("DATABASE_URL")
+	switch config.Get().Database.Driver {
+	case config.DriverSqlite:
+		db, err = connectToSqlite(config.Get().Database.SqlitePath)
+	default:
+		db, err = connectToPostgres(config.Get().DatabaseURL(), config.Get().Database.Schema)
 	}
 
+	if err != nil {
+		log.Fatal("Failed to connect to database. \n", err)
+	}
+
+	if config.Get().Tracing.Enabled {
+		if err := db.Use(&TracingPlugin{}); err != nil {
+			log.Fatal("Failed to set up query tracing. \n", err)
+		}
+	}
+
+	log.Infof(`Connected to database. Driver: <%s>`, db.Dialector.Name())
+
+	DB = DbInstance{
+		Db: db,
+	}
+}
+
+func connectToPostgres(dsn string, dbSchema string) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dsn,
 		PreferSimpleProtocol: false, // disables implicit prepared statement usage
 	}), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger:         NewLogger(),
+		TranslateError: true,
 		NamingStrategy: schema.NamingStrategy{
-			TablePrefix:   "co2monitor.", // schema name
+			TablePrefix:   dbSchema + ".",
 			SingularTable: false,
 		},
 	})
-
 	if err != nil {
-		log.Fatal("Failed to connect to database. \n", err)
+		return nil, err
 	}
 
-	dbSchema := os.Getenv("POSTGRES_DB")
 	createSchemaCommand := fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", dbSchema)
-	result := db.Exec(createSchemaCommand)
-	if result.Error != nil {
-		log.Fatal("Failed to create schema. \n", result.Error)
+	if err := db.Exec(createSchemaCommand).Error; err != nil {
+		return nil, fmt.Errorf("failed to create schema: %w", err)
 	}
 
-	log.Info("Connected to database.")
+	return db, nil
+}
 
-	DB = DbInstance{
-		Db: db,
+// connectToSqlite opens the database file in WAL mode so readers do not block
+// the ingest. SQLite has no schemas, so the tables are not prefixed.
+func connectToSqlite(path string) (*gorm.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
 	}
+
+	dsn := path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=synchronous(NORMAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:         NewLogger(),
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := UseUTC(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// CloseDb closes the connection pool, it has to be called after the last query.
+func CloseDb() error {
+	if DB.Db == nil {
+		return nil
+	}
+
+	sqlDb, err := DB.Db.DB()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Closing database connection.")
+	return sqlDb.Close()
 }
 
 func GetDB() *gorm.DB {
 	return DB.Db
 }
+
+// UsesTimescale tells whether aggregates can be read from the continuous aggregates.
+func UsesTimescale() bool {
+	return DB.Timescale
+}
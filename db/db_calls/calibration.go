@@ -0,0 +1,48 @@
+package db_calls
+
+import (
+	"errors"
+
+	"github.com/fminister/co2monitor.api/models"
+	"gorm.io/gorm"
+)
+
+func GetCalibrations(db *gorm.DB, locationId string) ([]models.Calibration, error) {
+	var calibrations []models.Calibration
+
+	err := db.Order("valid_from desc").Where("location_id = ?", locationId).Find(&calibrations).Error
+
+	return calibrations, err
+}
+
+func GetCalibrationById(db *gorm.DB, id string) (models.Calibration, error) {
+	var calibration models.Calibration
+
+	err := db.First(&calibration, id).Error
+
+	return calibration, err
+}
+
+func CreateCalibration(db *gorm.DB, calibrations []models.Calibration) ([]models.Calibration, error) {
+	if len(calibrations) == 0 {
+		return calibrations, errors.New("Empty list of calibrations to insert")
+	}
+
+	err := db.Create(&calibrations).Error
+
+	return calibrations, err
+}
+
+func DeleteCalibration(db *gorm.DB, calibration models.Calibration) error {
+	err := db.Delete(&calibration).Error
+
+	return err
+}
+
+func GetAllCalibrations(db *gorm.DB) ([]models.Calibration, error) {
+	var calibrations []models.Calibration
+
+	err := db.Find(&calibrations).Error
+
+	return calibrations, err
+}
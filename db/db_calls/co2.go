@@ -11,7 +11,16 @@ import (
 func GetCo2DataByTimeFrame(db *gorm.DB, locationId string, hours time.Duration) ([]models.Co2Data, error) {
 	var co2Data []models.Co2Data
 
-	err := db.Where("location_id = ? AND created_at > ?", locationId, time.Now().Add(-hours)).Find(&co2Data).Error
+	err := db.Order("id").Where("location_id = ? AND created_at > ?", locationId, time.Now().Add(-hours).UTC()).Find(&co2Data).Error
+
+	return co2Data, err
+}
+
+// GetCo2DataByTimeRange returns the co2 data measured after from and up to and including to.
+func GetCo2DataByTimeRange(db *gorm.DB, locationId string, from time.Time, to time.Time) ([]models.Co2Data, error) {
+	var co2Data []models.Co2Data
+
+	err := db.Order("id").Where("location_id = ? AND created_at > ? AND created_at <= ?", locationId, from.UTC(), to.UTC()).Find(&co2Data).Error
 
 	return co2Data, err
 }
@@ -33,3 +42,36 @@ func CreateCo2Data(db *gorm.DB, co2Data []models.Co2Data) ([]models.Co2Data, err
 
 	return co2Data, err
 }
+
+// ExcludeAnomalies is a scope which skips co2 data flagged by the anomaly detection.
+func ExcludeAnomalies(db *gorm.DB) *gorm.DB {
+	return db.Where("quality = ?", models.QualityOk)
+}
+
+func GetCo2DataSince(db *gorm.DB, locationIds []int, since time.Time) ([]models.Co2Data, error) {
+	var co2Data []models.Co2Data
+
+	err := db.Where("location_id IN ? AND created_at > ?", locationIds, since.UTC()).Find(&co2Data).Error
+
+	return co2Data, err
+}
+
+func GetAnomalies(db *gorm.DB, locationId string, hours time.Duration) ([]models.Co2Data, error) {
+	var co2Data []models.Co2Data
+
+	err := db.Order("created_at desc").Where("location_id = ? AND created_at > ? AND quality <> ?", locationId, time.Now().Add(-hours).UTC(), models.QualityOk).Find(&co2Data).Error
+
+	return co2Data, err
+}
+
+// GetLatestCo2DataPerLocation returns the newest reading of every location which has reported at least once.
+// Readings of a batch share their time, the one stored last wins.
+func GetLatestCo2DataPerLocation(db *gorm.DB) ([]models.Co2Data, error) {
+	var co2Data []models.Co2Data
+
+	latest := db.Model(&models.Co2Data{}).Select("location_id, MAX(created_at)").Group("location_id")
+	ids := db.Model(&models.Co2Data{}).Select("MAX(id)").Where("(location_id, created_at) IN (?)", latest).Group("location_id")
+	err := db.Where("id IN (?)", ids).Order("location_id").Find(&co2Data).Error
+
+	return co2Data, err
+}
@@ -0,0 +1,110 @@
+package db_calls
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fminister/co2monitor.api/db"
+	"github.com/fminister/co2monitor.api/models"
+	"gorm.io/gorm"
+)
+
+type co2AggregateRow struct {
+	LocationID int
+	Bucket     string
+	AvgCo2     float64
+	MinCo2     float64
+	MaxCo2     float64
+	AvgTemp    float64
+	Count      int
+}
+
+var bucketLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02 15:04:05Z07:00"}
+
+// GetCo2DataAggregates returns the hourly or daily aggregates of a location
+// since the start of the bucket containing since. With TimescaleDB they are
+// read from the continuous aggregates, otherwise computed from the co2 data.
+// Scopes like ExcludeAnomalies work on both.
+func GetCo2DataAggregates(gormDb *gorm.DB, locationId string, interval string, since time.Time, timescale bool) ([]models.Co2Aggregate, error) {
+	if interval != models.AggregateHour && interval != models.AggregateDay {
+		return nil, fmt.Errorf("unknown aggregate interval %q", interval)
+	}
+	since = since.UTC().Truncate(bucketDuration(interval))
+
+	var query *gorm.DB
+	if timescale {
+		view, err := db.ContinuousAggregateView(gormDb, interval)
+		if err != nil {
+			return nil, err
+		}
+		query = gormDb.Table(view).
+			Select("location_id, bucket, SUM(sum_co2) / SUM(count) AS avg_co2, MIN(min_co2) AS min_co2, MAX(max_co2) AS max_co2, SUM(sum_temp) / SUM(count) AS avg_temp, SUM(count) AS count").
+			Where("location_id = ? AND bucket >= ?", locationId, since)
+	} else {
+		bucket, err := bucketExpression(gormDb, interval)
+		if err != nil {
+			return nil, err
+		}
+		query = gormDb.Model(&models.Co2Data{}).
+			Select(fmt.Sprintf("location_id, %s AS bucket, AVG(co2) AS avg_co2, MIN(co2) AS min_co2, MAX(co2) AS max_co2, AVG(temp) AS avg_temp, COUNT(*) AS count", bucket)).
+			Where("location_id = ? AND created_at >= ?", locationId, since)
+	}
+
+	var rows []co2AggregateRow
+	if err := query.Group("location_id, bucket").Order("bucket").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	aggregates := make([]models.Co2Aggregate, 0, len(rows))
+	for _, row := range rows {
+		bucket, err := parseBucket(row.Bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		aggregates = append(aggregates, models.Co2Aggregate{
+			LocationID: row.LocationID,
+			Bucket:     bucket,
+			AvgCO2:     row.AvgCo2,
+			MinCO2:     row.MinCo2,
+			MaxCO2:     row.MaxCo2,
+			AvgTemp:    row.AvgTemp,
+			Count:      row.Count,
+		})
+	}
+
+	return aggregates, nil
+}
+
+func bucketDuration(interval string) time.Duration {
+	if interval == models.AggregateDay {
+		return 24 * time.Hour
+	}
+
+	return time.Hour
+}
+
+// bucketExpression truncates created_at to the hour or day in UTC.
+func bucketExpression(gormDb *gorm.DB, interval string) (string, error) {
+	switch gormDb.Dialector.Name() {
+	case "postgres":
+		return fmt.Sprintf("date_trunc('%s', created_at AT TIME ZONE 'UTC')", interval), nil
+	case "sqlite":
+		if interval == models.AggregateDay {
+			return "strftime('%Y-%m-%d 00:00:00', created_at)", nil
+		}
+		return "strftime('%Y-%m-%d %H:00:00', created_at)", nil
+	default:
+		return "", fmt.Errorf("aggregates are not supported for %s", gormDb.Dialector.Name())
+	}
+}
+
+func parseBucket(value string) (time.Time, error) {
+	for _, layout := range bucketLayouts {
+		if bucket, err := time.Parse(layout, value); err == nil {
+			return bucket.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not parse aggregate bucket %q", value)
+}
@@ -22,7 +22,7 @@ func GetLocationBySearch(db *gorm.DB, id string, name string) ([]models.Location
 		id = "-1"
 	}
 
-	err := db.Where("ID = ?", id).Or("name = ?", name).Find(&locations).Error
+	err := db.Where("id = ?", id).Or("name = ?", name).Find(&locations).Error
 
 	return locations, err
 }
@@ -35,6 +35,17 @@ func GetLocationById(db *gorm.DB, id string) (models.Location, error) {
 	return location, err
 }
 
+func GetLocationsByIdsOrNames(db *gorm.DB, ids []int, names []string) ([]models.Location, error) {
+	locations := []models.Location{}
+	if len(ids) == 0 && len(names) == 0 {
+		return locations, nil
+	}
+
+	err := db.Where("id IN ?", ids).Or("name IN ?", names).Find(&locations).Error
+
+	return locations, err
+}
+
 func CreateLocation(db *gorm.DB, locations []models.Location) ([]models.Location, error) {
 	if len(locations) == 0 {
 		return locations, errors.New("Empty list of locations to insert")
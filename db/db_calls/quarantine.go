@@ -0,0 +1,51 @@
+package db_calls
+
+import (
+	"errors"
+
+	"github.com/fminister/co2monitor.api/models"
+	"gorm.io/gorm"
+)
+
+func GetQuarantinedCo2Data(db *gorm.DB) ([]models.QuarantinedCo2Data, error) {
+	var quarantined []models.QuarantinedCo2Data
+
+	err := db.Order("created_at desc").Order("id desc").Find(&quarantined).Error
+
+	return quarantined, err
+}
+
+func GetQuarantinedCo2DataById(db *gorm.DB, id string) (models.QuarantinedCo2Data, error) {
+	var quarantined models.QuarantinedCo2Data
+
+	err := db.First(&quarantined, id).Error
+
+	return quarantined, err
+}
+
+func CreateQuarantinedCo2Data(db *gorm.DB, quarantined []models.QuarantinedCo2Data) ([]models.QuarantinedCo2Data, error) {
+	if len(quarantined) == 0 {
+		return quarantined, errors.New("Empty list of quarantined co2 data to insert")
+	}
+
+	err := db.Create(&quarantined).Error
+
+	return quarantined, err
+}
+
+// DeleteQuarantinedCo2Data returns gorm.ErrRecordNotFound if the entry was
+// already deleted, e.g. by a concurrent replay.
+func DeleteQuarantinedCo2Data(db *gorm.DB, quarantined models.QuarantinedCo2Data) error {
+	result := db.Delete(&quarantined)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return result.Error
+}
+
+func RestoreQuarantinedCo2Data(db *gorm.DB, quarantined models.QuarantinedCo2Data) error {
+	err := db.Unscoped().Model(&models.QuarantinedCo2Data{}).Where("id = ?", quarantined.ID).Update("deleted_at", nil).Error
+
+	return err
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SlowQueryThreshold is the duration after which a query is logged as slow.
+const SlowQueryThreshold = 200 * time.Millisecond
+
+// Logger writes the GORM logs as structured lines of the application logger.
+// Every query is logged at the debug level, slow queries as warnings and
+// failed queries as errors. Missing records are no errors.
+type Logger struct {
+	level logger.LogLevel
+}
+
+// NewLogger logs every query with the debug log level and only slow and
+// failed queries otherwise.
+func NewLogger() *Logger {
+	if config.Get().Log.Level == log.DebugLevel.String() {
+		return &Logger{level: logger.Info}
+	}
+
+	return &Logger{level: logger.Warn}
+}
+
+func (l *Logger) LogMode(level logger.LogLevel) logger.Interface {
+	return &Logger{level: level}
+}
+
+func (l *Logger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		log.Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *Logger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		log.Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *Logger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		log.Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error
+	slow := elapsed > SlowQueryThreshold && l.level >= logger.Warn
+	if !failed && !slow && l.level < logger.Info {
+		return
+	}
+
+	sql, rows := fc()
+	keyvals := []interface{}{"sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds()) / 1000}
+	switch {
+	case failed:
+		log.Error("Query failed.", append(keyvals, "error", err)...)
+	case slow:
+		log.Warn("Slow query.", keyvals...)
+	default:
+		log.Debug("Query.", keyvals...)
+	}
+}
@@ -0,0 +1,323 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// migrationLockId serializes migrations of several instances on postgres.
+const migrationLockId = 4242001
+
+// Migration is a versioned schema change read from <version>_<name>.up.sql
+// and <version>_<name>.down.sql in the directory of the dialect.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	dialect    string
+	schema     string
+	migrations []Migration
+}
+
+func New(db *gorm.DB) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+	migrations, err := load(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	dbSchema := ""
+	if namer, ok := db.NamingStrategy.(schema.NamingStrategy); ok {
+		dbSchema = strings.TrimSuffix(namer.TablePrefix, ".")
+	}
+
+	return &Migrator{db: db, dialect: dialect, schema: dbSchema, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations in order, each in its own transaction.
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.createTable(); err != nil {
+		return nil, err
+	}
+
+	applied := []Migration{}
+	for _, migration := range m.migrations {
+		done := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := m.prepare(tx); err != nil {
+				return err
+			}
+
+			var count int64
+			if err := tx.Table(m.table()).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+
+			if err := execute(tx, migration.Up); err != nil {
+				return err
+			}
+			done = true
+			return tx.Exec(fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)", m.table()), migration.Version, migration.Name, time.Now().UTC()).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if done {
+			log.Infof(`Applied migration. Version: <%04d>; Name: <%s>`, migration.Version, migration.Name)
+			applied = append(applied, migration)
+		}
+	}
+
+	return applied, nil
+}
+
+// Down reverts the given number of applied migrations, newest first.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if err := m.createTable(); err != nil {
+		return nil, err
+	}
+
+	reverted := []Migration{}
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := m.migrations[i]
+		done := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := m.prepare(tx); err != nil {
+				return err
+			}
+
+			var count int64
+			if err := tx.Table(m.table()).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return nil
+			}
+
+			if err := execute(tx, migration.Down); err != nil {
+				return err
+			}
+			done = true
+			return tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE version = ?", m.table()), migration.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("reverting migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if done {
+			log.Infof(`Reverted migration. Version: <%04d>; Name: <%s>`, migration.Version, migration.Name)
+			reverted = append(reverted, migration)
+		}
+	}
+
+	return reverted, nil
+}
+
+// Status lists all known migrations and whether they are applied. It only
+// reads, so the readiness check can call it, and treats a database without
+// the migrations table as one without applied migrations.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	exists, err := m.tableExists()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []appliedMigration
+	if exists {
+		if err := m.db.Table(m.table()).Order("version").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	applied := map[int]appliedMigration{}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		entry := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			entry.Applied = true
+			entry.AppliedAt = &appliedAt
+		}
+		status = append(status, entry)
+	}
+
+	return status, nil
+}
+
+// Pending returns the migrations which are not applied yet.
+func (m *Migrator) Pending() ([]Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Migration{}
+	for i, entry := range status {
+		if !entry.Applied {
+			pending = append(pending, m.migrations[i])
+		}
+	}
+
+	return pending, nil
+}
+
+func (m *Migrator) table() string {
+	if m.schema == "" {
+		return "schema_migrations"
+	}
+
+	return m.schema + ".schema_migrations"
+}
+
+// tableExists looks the migrations table up in the catalog of the database.
+// Unlike the gorm migrator it reports the errors of an unreachable database.
+func (m *Migrator) tableExists() (bool, error) {
+	var count int64
+	var err error
+	if m.dialect == "postgres" {
+		err = m.db.Raw("SELECT count(*) FROM information_schema.tables WHERE table_schema = COALESCE(NULLIF(?, ''), CURRENT_SCHEMA()) AND table_name = 'schema_migrations'", m.schema).Scan(&count).Error
+	} else {
+		err = m.db.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&count).Error
+	}
+
+	return count > 0, err
+}
+
+func (m *Migrator) createTable() error {
+	return m.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version BIGINT PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMP NOT NULL)", m.table())).Error
+}
+
+// prepare locks the migrations and points postgres to the schema of the app,
+// so the sql files do not need to know it.
+func (m *Migrator) prepare(tx *gorm.DB) error {
+	if m.dialect != "postgres" {
+		return nil
+	}
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockId).Error; err != nil {
+		return err
+	}
+	if m.schema == "" {
+		return nil
+	}
+
+	return tx.Exec(fmt.Sprintf("SET LOCAL search_path TO %s", m.schema)).Error
+}
+
+// execute runs the statements of a migration one after another.
+func execute(tx *gorm.DB, sql string) error {
+	for _, statement := range strings.Split(sql, ";\n") {
+		if strings.TrimSpace(stripComments(statement)) == "" {
+			continue
+		}
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func stripComments(statement string) string {
+	lines := []string{}
+	for _, line := range strings.Split(statement, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("there are no migrations for %s", dialect)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		direction := ""
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		versionPart, migrationName, found := strings.Cut(strings.TrimSuffix(name, "."+direction+".sql"), "_")
+		version, err := strconv.Atoi(versionPart)
+		if !found || err != nil {
+			return nil, fmt.Errorf("migration %s has to be named <version>_<name>.%s.sql", name, direction)
+		}
+
+		content, err := fs.ReadFile(files, path.Join(dialect, name))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: migrationName}
+			byVersion[version] = migration
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
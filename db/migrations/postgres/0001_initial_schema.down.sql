@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS co2_data;
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,24 @@
+-- Tables as they were created by AutoMigrate before versioned migrations.
+-- IF NOT EXISTS keeps existing databases untouched.
+CREATE TABLE IF NOT EXISTS locations (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_locations_deleted_at ON locations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS co2_data (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    co2 bigint NOT NULL,
+    temp decimal NOT NULL,
+    location_id bigint NOT NULL,
+    CONSTRAINT fk_co2_data_location FOREIGN KEY (location_id) REFERENCES locations (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_co2_data_deleted_at ON co2_data (deleted_at);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS calibrations;
+
+ALTER TABLE co2_data DROP COLUMN IF EXISTS quality;
+
+ALTER TABLE locations DROP COLUMN IF EXISTS occupancy;
+ALTER TABLE locations DROP COLUMN IF EXISTS room_volume;
@@ -0,0 +1,21 @@
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS room_volume decimal NOT NULL DEFAULT 0;
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS occupancy bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE co2_data ADD COLUMN IF NOT EXISTS quality text NOT NULL DEFAULT 'ok';
+
+CREATE TABLE IF NOT EXISTS calibrations (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    location_id bigint NOT NULL,
+    co2_offset decimal NOT NULL DEFAULT 0,
+    co2_scale decimal NOT NULL DEFAULT 1,
+    temp_offset decimal NOT NULL DEFAULT 0,
+    temp_scale decimal NOT NULL DEFAULT 1,
+    valid_from timestamptz NOT NULL,
+    valid_to timestamptz,
+    CONSTRAINT fk_calibrations_location FOREIGN KEY (location_id) REFERENCES locations (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calibrations_deleted_at ON calibrations (deleted_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_co2_data_location_id_created_at;
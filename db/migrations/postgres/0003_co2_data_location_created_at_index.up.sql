@@ -0,0 +1,2 @@
+-- Used by the time frame queries which filter by location and created_at.
+CREATE INDEX IF NOT EXISTS idx_co2_data_location_id_created_at ON co2_data (location_id, created_at);
@@ -0,0 +1,16 @@
+-- Posted co2 data which was rejected or held back, kept to be fixed and replayed.
+CREATE TABLE IF NOT EXISTS quarantined_co2_data (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    payload text NOT NULL,
+    code text NOT NULL,
+    reason text NOT NULL,
+    errors text NOT NULL,
+    api_key text NOT NULL DEFAULT '',
+    client_ip text NOT NULL DEFAULT '',
+    request_id text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantined_co2_data_deleted_at ON quarantined_co2_data (deleted_at);
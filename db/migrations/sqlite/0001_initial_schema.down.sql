@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS calibrations;
+DROP TABLE IF EXISTS co2_data;
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,45 @@
+-- SQLite support started after calibrations were added, so the first
+-- migration creates the whole schema. IF NOT EXISTS keeps databases created
+-- by AutoMigrate untouched.
+CREATE TABLE IF NOT EXISTS locations (
+    id integer PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    name text NOT NULL UNIQUE,
+    room_volume real NOT NULL DEFAULT 0,
+    occupancy integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_locations_deleted_at ON locations (deleted_at);
+
+CREATE TABLE IF NOT EXISTS co2_data (
+    id integer PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    co2 integer NOT NULL,
+    temp real NOT NULL,
+    location_id integer NOT NULL,
+    quality text NOT NULL DEFAULT 'ok',
+    CONSTRAINT fk_co2_data_location FOREIGN KEY (location_id) REFERENCES locations (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_co2_data_deleted_at ON co2_data (deleted_at);
+
+CREATE TABLE IF NOT EXISTS calibrations (
+    id integer PRIMARY KEY,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    location_id integer NOT NULL,
+    co2_offset real NOT NULL DEFAULT 0,
+    co2_scale real NOT NULL DEFAULT 1,
+    temp_offset real NOT NULL DEFAULT 0,
+    temp_scale real NOT NULL DEFAULT 1,
+    valid_from datetime NOT NULL,
+    valid_to datetime,
+    CONSTRAINT fk_calibrations_location FOREIGN KEY (location_id) REFERENCES locations (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calibrations_deleted_at ON calibrations (deleted_at);
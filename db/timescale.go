@@ -0,0 +1,146 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/models"
+	"gorm.io/gorm"
+)
+
+// continuousAggregate is a rollup of the co2 data maintained by TimescaleDB.
+type continuousAggregate struct {
+	interval      string
+	view          string
+	startOffset   string
+	endOffset     string
+	scheduleEvery string
+}
+
+var continuousAggregates = map[string]continuousAggregate{
+	models.AggregateHour: {interval: "1 hour", view: "co2_data_hourly", startOffset: "3 days", endOffset: "1 hour", scheduleEvery: "30 minutes"},
+	models.AggregateDay:  {interval: "1 day", view: "co2_data_daily", startOffset: "7 days", endOffset: "1 day", scheduleEvery: "1 hour"},
+}
+
+// SetupTimescale turns the co2 data into a hypertable and creates the hourly
+// and daily continuous aggregates if the timescaledb extension is installed.
+// It does nothing on vanilla Postgres and SQLite and can run on every start.
+func SetupTimescale(db *gorm.DB) (bool, error) {
+	if db.Dialector.Name() != "postgres" {
+		return false, nil
+	}
+
+	installed := false
+	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')").Scan(&installed).Error; err != nil {
+		return false, err
+	}
+	if !installed {
+		log.Info("TimescaleDB is not installed, aggregates are computed from the co2 data.")
+		return false, nil
+	}
+
+	dbSchema, table, err := co2DataTable(db)
+	if err != nil {
+		return false, err
+	}
+
+	hypertable := false
+	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM timescaledb_information.hypertables WHERE hypertable_schema = ? AND hypertable_name = ?)", dbSchema, table).Scan(&hypertable).Error; err != nil {
+		return false, err
+	}
+
+	if !hypertable {
+		log.Infof(`Converting <%s.%s> into a hypertable.`, dbSchema, table)
+		// unique indexes of a hypertable have to contain the time column
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s.%s DROP CONSTRAINT IF EXISTS %s_pkey", dbSchema, table, table)).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s.%s ADD PRIMARY KEY (id, created_at)", dbSchema, table)).Error; err != nil {
+				return err
+			}
+			return tx.Exec("SELECT create_hypertable(?, 'created_at', migrate_data => true)", dbSchema+"."+table).Error
+		})
+		if err != nil {
+			return false, fmt.Errorf("could not create hypertable: %w", err)
+		}
+	}
+
+	for _, aggregate := range continuousAggregates {
+		if err := createContinuousAggregate(db, dbSchema, table, aggregate); err != nil {
+			return false, fmt.Errorf("could not create continuous aggregate %s: %w", aggregate.view, err)
+		}
+	}
+
+	log.Info("TimescaleDB is set up, aggregates are read from the continuous aggregates.")
+	return true, nil
+}
+
+// ContinuousAggregateView returns the qualified view of the interval.
+func ContinuousAggregateView(db *gorm.DB, interval string) (string, error) {
+	dbSchema, _, err := co2DataTable(db)
+	if err != nil {
+		return "", err
+	}
+
+	return dbSchema + "." + continuousAggregates[interval].view, nil
+}
+
+func createContinuousAggregate(db *gorm.DB, dbSchema string, table string, aggregate continuousAggregate) error {
+	exists := false
+	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM timescaledb_information.continuous_aggregates WHERE view_schema = ? AND view_name = ?)", dbSchema, aggregate.view).Scan(&exists).Error; err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	view := dbSchema + "." + aggregate.view
+	log.Infof(`Creating continuous aggregate <%s>.`, view)
+
+	// sums instead of averages, so buckets of different qualities can be combined
+	err := db.Exec(fmt.Sprintf(`CREATE MATERIALIZED VIEW %s
+		WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+		SELECT location_id,
+			time_bucket(INTERVAL '%s', created_at) AS bucket,
+			quality,
+			SUM(co2) AS sum_co2,
+			MIN(co2) AS min_co2,
+			MAX(co2) AS max_co2,
+			SUM(temp) AS sum_temp,
+			COUNT(*) AS count
+		FROM %s.%s
+		WHERE deleted_at IS NULL
+		GROUP BY location_id, bucket, quality
+		WITH NO DATA`, view, aggregate.interval, dbSchema, table)).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Exec(fmt.Sprintf(`SELECT add_continuous_aggregate_policy('%s',
+		start_offset => INTERVAL '%s',
+		end_offset => INTERVAL '%s',
+		schedule_interval => INTERVAL '%s',
+		if_not_exists => true)`, view, aggregate.startOffset, aggregate.endOffset, aggregate.scheduleEvery)).Error
+	if err != nil {
+		return err
+	}
+
+	log.Infof(`Materializing existing co2 data into <%s>.`, view)
+	return db.Exec(fmt.Sprintf("CALL refresh_continuous_aggregate('%s', NULL, NULL)", view)).Error
+}
+
+func co2DataTable(db *gorm.DB) (string, string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&models.Co2Data{}); err != nil {
+		return "", "", err
+	}
+
+	dbSchema, table, found := strings.Cut(stmt.Schema.Table, ".")
+	if !found {
+		return "public", stmt.Schema.Table, nil
+	}
+
+	return dbSchema, table, nil
+}
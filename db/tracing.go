@@ -0,0 +1,75 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/fminister/co2monitor.api/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "co2monitor:span"
+
+// TracingPlugin records a span for every query. The spans are children of
+// the span in the context of the statement, so queries have to be run with
+// db.WithContext to show up in the trace of a request.
+type TracingPlugin struct{}
+
+func (p *TracingPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *TracingPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create")),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", endSpan),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query")),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", endSpan),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update")),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", endSpan),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete")),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row")),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", endSpan),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw")),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan),
+	)
+}
+
+func startSpan(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := tracing.Tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemKey.String(db.Dialector.Name()), semconv.DBOperation(operation)),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	attributes := []attribute.KeyValue{
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	}
+	if db.Statement.Table != "" {
+		attributes = append(attributes, semconv.DBSQLTable(db.Statement.Table))
+	}
+	span.SetAttributes(attributes...)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
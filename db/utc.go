@@ -0,0 +1,66 @@
+package db
+
+import (
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// UseUTC stores every time in UTC. SQLite keeps times as text and compares
+// them as strings, so times with different offsets would not be comparable.
+// Queries have to pass their time arguments in UTC as well.
+func UseUTC(db *gorm.DB) error {
+	db.Config.NowFunc = func() time.Time {
+		return time.Now().UTC()
+	}
+
+	if err := db.Callback().Create().Before("gorm:create").Register("co2monitor:utc", timesToUTC); err != nil {
+		return err
+	}
+
+	return db.Callback().Update().Before("gorm:update").Register("co2monitor:utc", timesToUTC)
+}
+
+func timesToUTC(db *gorm.DB) {
+	if db.Statement.Schema == nil || !db.Statement.ReflectValue.IsValid() {
+		return
+	}
+
+	switch db.Statement.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+			fieldsToUTC(db, db.Statement.ReflectValue.Index(i))
+		}
+	case reflect.Struct:
+		fieldsToUTC(db, db.Statement.ReflectValue)
+	}
+}
+
+func fieldsToUTC(db *gorm.DB, value reflect.Value) {
+	value = reflect.Indirect(value)
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, field := range db.Statement.Schema.Fields {
+		if field.DataType != schema.Time {
+			continue
+		}
+
+		fieldValue, isZero := field.ValueOf(db.Statement.Context, value)
+		if isZero {
+			continue
+		}
+
+		switch t := fieldValue.(type) {
+		case time.Time:
+			field.Set(db.Statement.Context, value, t.UTC())
+		case *time.Time:
+			if t != nil {
+				field.Set(db.Statement.Context, value, t.UTC())
+			}
+		}
+	}
+}
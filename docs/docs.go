@@ -16,14 +16,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/co2data/new": {
+        "/calibration/new": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create co2 data by posting a list of co2 data objects.",
+                "description": "Create calibrations by posting a list of calibration objects. Values are corrected on read with value * scale + offset while the calibration is valid. A missing scale means that the values are not scaled, a missing end means that the calibration is valid until further notice.",
                 "consumes": [
                     "application/json"
                 ],
@@ -31,19 +31,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "CO2 Data"
+                    "Calibrations"
                 ],
-                "summary": "Create co2 data for a location",
+                "summary": "Create calibrations",
                 "parameters": [
                     {
-                        "description": "New Co2Data",
-                        "name": "co2data",
+                        "description": "New Calibration",
+                        "name": "calibration",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Co2DataPostDto"
+                                "$ref": "#/definitions/models.CalibrationPostDto"
                             }
                         }
                     }
@@ -54,27 +54,39 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Co2DataDto"
+                                "$ref": "#/definitions/models.CalibrationDto"
                             }
                         }
                     },
                     "400": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     }
                 }
             }
         },
-        "/co2data/{id}/latest": {
-            "get": {
+        "/calibration/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get latest co2 data by passing a location id as parameter.",
+                "description": "Delete a calibration by passing the calibration id as parameter.",
                 "consumes": [
                     "application/json"
                 ],
@@ -82,42 +94,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "CO2 Data"
+                    "Calibrations"
                 ],
-                "summary": "Get latest co2 data for a location",
+                "summary": "Delete a calibration",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "LocationId",
+                        "description": "CalibrationId",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "Deleted successfully"
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "$ref": "#/definitions/models.Co2DataDto"
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     },
-                    "404": {
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     }
                 }
             }
         },
-        "/co2data/{id}/search": {
-            "get": {
+        "/co2data/new": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get co2 data by passing a location id as parameter and a time frame as query parameter. The time frame is from now minus [period] (1m, 1h, 1d).",
+                "description": "Create co2 data by posting a list of co2 data objects. The location is referenced by location_id or location_name, all referenced locations are checked before anything is stored and the errors point to the items referencing unknown or deleted locations. co2 and temp are required, zero included, and have to be within the configured physical ranges. Depending on the config items with values out of range are rejected or quarantined, quarantined items are held back and listed with the status 207. Rejected and quarantined items are kept in the quarantine with the reason and the source, so an admin can fix and replay or discard them. The body and the number of items are limited. With partial=true the valid items are stored even if others are invalid, the response lists the created co2 data and the errors of the rejected items and has the status 207 if any item was rejected.",
                 "consumes": [
                     "application/json"
                 ],
@@ -127,27 +148,30 @@ const docTemplate = `{
                 "tags": [
                     "CO2 Data"
                 ],
-                "summary": "Get co2 data in a time frame",
+                "summary": "Create co2 data for a location",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "LocationId",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "New Co2Data",
+                        "name": "co2data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Co2DataPostDto"
+                            }
+                        }
                     },
                     {
-                        "type": "string",
-                        "example": "1m",
-                        "description": "time frame",
-                        "name": "period",
-                        "in": "query",
-                        "required": true
+                        "type": "boolean",
+                        "description": "store the valid items and report the invalid ones",
+                        "name": "partial",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "type": "array",
                             "items": {
@@ -155,23 +179,47 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.Co2DataBatchDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "413": {
+                        "description": "The body is too large or contains too many items.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     }
                 }
             }
         },
-        "/location": {
+        "/co2data/{id}/aggregate": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get all locations.",
+                "description": "Get the average, minimum and maximum co2 and the average temperature per hour or day by passing a location id as parameter and a time frame as query parameter. The time frame is from now minus [period] (e.g. 30m, 6h, 1d12h, 2w or P7D, 6h by default) and starts with the whole hour or day. Readings flagged as anomalies can be left out. Values are calibrated unless raw values are requested.",
                 "consumes": [
                     "application/json"
                 ],
@@ -179,36 +227,104 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "CO2 Data"
+                ],
+                "summary": "Get hourly or daily co2 data aggregates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "LocationId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "hour",
+                        "description": "hour or day",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "7d",
+                        "description": "time frame",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "leave out readings flagged as anomalies",
+                        "name": "exclude_anomalies",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "return the values without calibration",
+                        "name": "raw",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Get all locations",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.LocationDto"
+                                "$ref": "#/definitions/models.Co2AggregateDto"
                             }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the aggregates"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "the aggregates did not change"
+                    },
+                    "400": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     },
                     "404": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     }
                 }
             }
         },
-        "/location/new": {
-            "post": {
+        "/co2data/{id}/anomalies": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a new location by posting a list of location objects.",
+                "description": "Get all readings of a location which were flagged as out of range, jump, stuck or drift by passing a location id as parameter and a time frame as query parameter. The time frame is from now minus [period] (e.g. 30m, 6h, 1d12h, 2w or P7D, 6h by default).",
                 "consumes": [
                     "application/json"
                 ],
@@ -216,50 +332,85 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "CO2 Data"
                 ],
-                "summary": "Create a new location",
+                "summary": "Get co2 data flagged as anomalies",
                 "parameters": [
                     {
-                        "description": "New Location",
-                        "name": "location",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.LocationPostDto"
-                            }
-                        }
+                        "type": "integer",
+                        "description": "LocationId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "7d",
+                        "description": "time frame",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.LocationDto"
+                                "$ref": "#/definitions/models.Co2DataDto"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the anomalies"
                             }
                         }
                     },
+                    "304": {
+                        "description": "the anomalies did not change"
+                    },
                     "400": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     }
                 }
             }
         },
-        "/location/search": {
+        "/co2data/{id}/forecast": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get one or more locations by passing a location id and/or name as parameter.",
+                "description": "Get predicted co2 values for a location by passing a location id as parameter and a horizon (1m, 1h, max. 24h) as query parameter. The model is fitted to the calibrated readings of the last hour and also estimates when the threshold will be crossed. A longer horizon, a threshold which is not a positive number or an unknown model is rejected.",
                 "consumes": [
                     "application/json"
                 ],
@@ -267,22 +418,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "CO2 Data"
                 ],
-                "summary": "Get one or more locations with search parameters",
+                "summary": "Get a co2 forecast for a location",
                 "parameters": [
                     {
-                        "type": "string",
-                        "example": "1",
+                        "type": "integer",
                         "description": "LocationId",
                         "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "60m",
+                        "description": "forecast horizon",
+                        "name": "horizon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1000,
+                        "description": "co2 threshold in ppm",
+                        "name": "threshold",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "holt",
+                            "linear"
+                        ],
                         "type": "string",
-                        "example": "Office",
-                        "description": "Name of location",
-                        "name": "name",
+                        "description": "forecast model",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "leave out readings flagged as anomalies",
+                        "name": "exclude_anomalies",
                         "in": "query"
                     }
                 ],
@@ -290,29 +464,44 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.LocationDto"
-                            }
+                            "$ref": "#/definitions/models.ForecastDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     },
                     "404": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     }
                 }
             }
         },
-        "/location/{id}": {
-            "delete": {
+        "/co2data/{id}/latest": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a location by passing the location id as parameter.",
+                "description": "Get latest co2 data by passing a location id as parameter. The values are calibrated unless raw values are requested. The latest readings are cached and the response carries an ETag, send it as If-None-Match to get 304 Not Modified as long as there is no new reading.",
                 "consumes": [
                     "application/json"
                 ],
@@ -320,9 +509,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "CO2 Data"
                 ],
-                "summary": "Delete a location",
+                "summary": "Get latest co2 data for a location",
                 "parameters": [
                     {
                         "type": "integer",
@@ -330,27 +519,75 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "return the values without calibration",
+                        "name": "raw",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Deleted successfully"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Co2DataDto"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the reading"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "latest update of the reading or calibrations"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "the reading did not change"
                     },
                     "404": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/co2data/{id}/search": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update a location by posting a location object.",
+                "description": "Get co2 data by passing a location id as parameter and a time frame as query parameter. The time frame is from [to] minus [period] (e.g. 30m, 6h, 1d12h, 2w or P7D, 6h by default) and [to] defaults to now. Readings flagged as anomalies can be left out. Values are calibrated unless raw values are requested. Time frames which ended more than five minutes ago may be cached by the client for a short time, others have to be revalidated with If-None-Match.",
                 "consumes": [
                     "application/json"
                 ],
@@ -358,44 +595,860 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "CO2 Data"
+                ],
+                "summary": "Get co2 data in a time frame",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "LocationId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1m",
+                        "description": "time frame",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2023-08-01T12:00:00Z",
+                        "description": "end of the time frame in RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "leave out readings flagged as anomalies",
+                        "name": "exclude_anomalies",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "return the values without calibration",
+                        "name": "raw",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Co2DataDto"
+                            }
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "private, no-cache for open and private, max-age for closed time frames"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the co2 data"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "the co2 data did not change"
+                    },
+                    "400": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/location": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all locations.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Get all locations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LocationDto"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the locations"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "the locations did not change"
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/location/new": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new location by posting a list of location objects. The body and the number of items are limited. With partial=true the valid locations are created even if others are invalid, the response lists the created locations and the errors of the rejected items and has the status 207 if any item was rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Create a new location",
+                "parameters": [
+                    {
+                        "description": "New Location",
+                        "name": "location",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LocationPostDto"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "create the valid locations and report the invalid ones",
+                        "name": "partial",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LocationDto"
+                            }
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationBatchDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "409": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "413": {
+                        "description": "The body is too large or contains too many items.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/location/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get one or more locations by passing a location id and/or name as parameter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Get one or more locations with search parameters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "1",
+                        "description": "LocationId",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Office",
+                        "description": "Name of location",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LocationDto"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the locations"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "the locations did not change"
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/location/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get whether each location is online or offline and when it reported its last reading. A location is offline if it has been silent for longer than the configured silence window.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Get the status of all locations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LocationStatusDto"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "503": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/location/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a location by passing the location id as parameter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Delete a location",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "LocationId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Deleted successfully"
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a location by posting a location object. Fields missing in the body keep their values.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Update a location",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "LocationId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Location",
+                        "name": "location",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationPostDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "409": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/location/{id}/calibrations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all calibrations of a location by passing the location id as parameter, the newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calibrations"
+                ],
+                "summary": "Get all calibrations of a location",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "LocationId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CalibrationDto"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the calibrations"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "the calibrations did not change"
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/location/{id}/ventilation": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a recommendation whether a location should be aired now, in a few minutes or not at all. It is based on the calibrated co2 slope of the last 30 minutes without anomalies or, if there are not enough readings, on the room volume and occupancy of the location. If there are no readings in the last 30 minutes the latest one is used, unless it is older than two hours.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Get a ventilation recommendation for a location",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "LocationId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.VentilationDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/quarantine/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all posted co2 data items which were rejected or held back, the newest first. Every entry contains the payload as posted, the reason and the errors, and the redacted api key, client ip and request id of the ingest. Requires the admin api key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quarantine"
+                ],
+                "summary": "Get the quarantined co2 data",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.QuarantinedCo2DataDto"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "The admin api key is missing.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/quarantine/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a quarantined co2 data item by passing its id as parameter. Requires the admin api key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quarantine"
+                ],
+                "summary": "Get quarantined co2 data by id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "QuarantineId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QuarantinedCo2DataDto"
+                        }
+                    },
+                    "401": {
+                        "description": "The admin api key is missing.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a quarantined co2 data item without storing it by passing its id as parameter. Requires the admin api key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quarantine"
+                ],
+                "summary": "Discard quarantined co2 data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "QuarantineId",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Discarded successfully"
+                    },
+                    "401": {
+                        "description": "The admin api key is missing.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/quarantine/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Store a quarantined co2 data item by passing its id as parameter. The body may contain the fixed co2 data object, the payload as posted is replayed if the body is empty. The co2 data is validated like a new one, but values out of range are always rejected. It keeps the time it was posted at. The entry is removed from the quarantine before the co2 data is stored and put back if storing fails, so concurrent replays store it only once. Requires the admin api key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
-                "summary": "Update a location",
+                "tags": [
+                    "Quarantine"
+                ],
+                "summary": "Replay quarantined co2 data",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "LocationId",
+                        "description": "QuarantineId",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update Location",
-                        "name": "location",
+                        "description": "Fixed Co2Data",
+                        "name": "co2data",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LocationPostDto"
+                            "$ref": "#/definitions/models.Co2DataPostDto"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.LocationDto"
+                            "$ref": "#/definitions/models.Co2DataDto"
                         }
                     },
                     "400": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "401": {
+                        "description": "The admin api key is missing.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     },
                     "404": {
                         "description": "Something went wrong, please refer to the error message.",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "413": {
+                        "description": "The body is too large.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after the seconds of the Retry-After header.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Something went wrong, please refer to the error message.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProblemDto"
                         }
                     }
                 }
@@ -403,9 +1456,127 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "models.CalibrationDto": {
+            "type": "object",
+            "properties": {
+                "co2_offset": {
+                    "type": "number"
+                },
+                "co2_scale": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "temp_offset": {
+                    "type": "number"
+                },
+                "temp_scale": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CalibrationPostDto": {
+            "type": "object",
+            "properties": {
+                "co2_offset": {
+                    "type": "number"
+                },
+                "co2_scale": {
+                    "type": "number",
+                    "example": 1
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "temp_offset": {
+                    "type": "number"
+                },
+                "temp_scale": {
+                    "type": "number",
+                    "example": 1
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Co2AggregateDto": {
+            "type": "object",
+            "properties": {
+                "avg_co2": {
+                    "type": "number"
+                },
+                "avg_temp": {
+                    "type": "number"
+                },
+                "bucket": {
+                    "type": "string"
+                },
+                "calibrated": {
+                    "type": "boolean"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "max_co2": {
+                    "type": "number"
+                },
+                "min_co2": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Co2DataBatchDto": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Co2DataDto"
+                    }
+                },
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItemErrorDto"
+                    }
+                },
+                "quarantined": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItemErrorDto"
+                    }
+                }
+            }
+        },
         "models.Co2DataDto": {
             "type": "object",
             "properties": {
+                "calibrated": {
+                    "type": "boolean"
+                },
                 "co2": {
                     "type": "integer"
                 },
@@ -418,6 +1589,9 @@ const docTemplate = `{
                 "location_id": {
                     "type": "integer"
                 },
+                "quality": {
+                    "type": "string"
+                },
                 "temp": {
                     "type": "number"
                 },
@@ -435,11 +1609,108 @@ const docTemplate = `{
                 "location_id": {
                     "type": "integer"
                 },
+                "location_name": {
+                    "type": "string"
+                },
                 "temp": {
                     "type": "number"
                 }
             }
         },
+        "models.FieldErrorDto": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "pointer": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ForecastDto": {
+            "type": "object",
+            "properties": {
+                "horizon_minutes": {
+                    "type": "integer"
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "minutes_until_threshold": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "predictions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ForecastPointDto"
+                    }
+                },
+                "slope_per_minute": {
+                    "type": "number"
+                },
+                "threshold": {
+                    "type": "integer"
+                },
+                "threshold_crossing_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ForecastPointDto": {
+            "type": "object",
+            "properties": {
+                "co2": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ItemErrorDto": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldErrorDto"
+                    }
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LocationBatchDto": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LocationDto"
+                    }
+                },
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ItemErrorDto"
+                    }
+                }
+            }
+        },
         "models.LocationDto": {
             "type": "object",
             "properties": {
@@ -452,6 +1723,12 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "occupancy": {
+                    "type": "integer"
+                },
+                "room_volume": {
+                    "type": "number"
+                },
                 "updated_at": {
                     "type": "string"
                 }
@@ -462,6 +1739,125 @@ const docTemplate = `{
             "properties": {
                 "name": {
                     "type": "string"
+                },
+                "occupancy": {
+                    "type": "integer"
+                },
+                "room_volume": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.LocationStatusDto": {
+            "type": "object",
+            "properties": {
+                "last_seen": {
+                    "type": "string"
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProblemDto": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldErrorDto"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.QuarantinedCo2DataDto": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string"
+                },
+                "client_ip": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldErrorDto"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.VentilationDto": {
+            "type": "object",
+            "properties": {
+                "latest_co2": {
+                    "type": "integer"
+                },
+                "location_id": {
+                    "type": "integer"
+                },
+                "measured_at": {
+                    "type": "string"
+                },
+                "minutes_until_threshold": {
+                    "type": "integer"
+                },
+                "recommendation": {
+                    "type": "string"
+                },
+                "slope_per_minute": {
+                    "type": "number"
+                },
+                "slope_source": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "integer"
                 }
             }
         }
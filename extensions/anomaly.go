@@ -0,0 +1,166 @@
+package extensions
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+// AnomalyRules configures when a reading is considered faulty.
+type AnomalyRules struct {
+	// MinCO2 and MaxCO2 are the plausible co2 values in ppm.
+	MinCO2 int
+	MaxCO2 int
+	// MinTemp and MaxTemp are the plausible temperatures in °C.
+	MinTemp float32
+	MaxTemp float32
+	// MaxJump is the largest plausible change in ppm between two readings
+	// which are at most MaxJumpInterval apart.
+	MaxJump         int
+	MaxJumpInterval time.Duration
+	// StuckDuration is how long a sensor may report the very same value.
+	StuckDuration time.Duration
+	// OutdoorBaseline is the co2 concentration of outdoor air in ppm. A
+	// reading below the baseline minus DriftTolerance or a minimum over
+	// DriftWindow above the baseline plus DriftTolerance means that the
+	// sensor has drifted.
+	OutdoorBaseline int
+	DriftTolerance  int
+	DriftWindow     time.Duration
+}
+
+var DefaultAnomalyRules = AnomalyRules{
+	MinCO2:          250,
+	MaxCO2:          10000,
+	MinTemp:         -40,
+	MaxTemp:         85,
+	MaxJump:         2000,
+	MaxJumpInterval: 10 * time.Minute,
+	StuckDuration:   6 * time.Hour,
+	OutdoorBaseline: 420,
+	DriftTolerance:  80,
+	DriftWindow:     24 * time.Hour,
+}
+
+// AnomalyRulesFromConfig returns the default rules with the configured
+// outdoor baseline.
+func AnomalyRulesFromConfig() AnomalyRules {
+	rules := DefaultAnomalyRules
+	rules.OutdoorBaseline = config.Get().Anomaly.OutdoorBaseline
+
+	return rules
+}
+
+// HistoryWindow is the time frame of previous readings DetectAnomalies needs.
+func (r AnomalyRules) HistoryWindow() time.Duration {
+	if r.StuckDuration > r.DriftWindow {
+		return r.StuckDuration
+	}
+
+	return r.DriftWindow
+}
+
+// DetectAnomalies sets the quality flag of every new reading. history holds
+// the already stored readings of the affected locations within
+// HistoryWindow. The order of co2Data is kept.
+func DetectAnomalies(history []models.Co2Data, co2Data []models.Co2Data, rules AnomalyRules) []models.Co2Data {
+	byLocation := map[int][]models.Co2Data{}
+	for _, data := range history {
+		byLocation[data.LocationID] = append(byLocation[data.LocationID], data)
+	}
+	for _, readings := range byLocation {
+		SortCo2DataByTime(readings)
+	}
+
+	// readings are checked from oldest to newest without reordering co2Data
+	order := make([]int, len(co2Data))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return co2Data[order[i]].CreatedAt.Before(co2Data[order[j]].CreatedAt)
+	})
+
+	for _, i := range order {
+		previous := byLocation[co2Data[i].LocationID]
+		co2Data[i].Quality = rules.classify(previous, co2Data[i])
+		byLocation[co2Data[i].LocationID] = append(previous, co2Data[i])
+	}
+
+	return co2Data
+}
+
+func (r AnomalyRules) classify(previous []models.Co2Data, data models.Co2Data) string {
+	if data.CO2 < r.MinCO2 || data.CO2 > r.MaxCO2 || data.Temp < r.MinTemp || data.Temp > r.MaxTemp {
+		return models.QualityOutOfRange
+	}
+	if r.isJump(previous, data) {
+		return models.QualityJump
+	}
+	if r.isStuck(previous, data) {
+		return models.QualityStuck
+	}
+	if r.isDrift(previous, data) {
+		return models.QualityDrift
+	}
+
+	return models.QualityOk
+}
+
+func (r AnomalyRules) isJump(previous []models.Co2Data, data models.Co2Data) bool {
+	for i := len(previous) - 1; i >= 0; i-- {
+		if previous[i].Quality != models.QualityOk {
+			continue
+		}
+		if data.CreatedAt.Sub(previous[i].CreatedAt) > r.MaxJumpInterval {
+			return false
+		}
+
+		return abs(data.CO2-previous[i].CO2) > r.MaxJump
+	}
+
+	return false
+}
+
+func (r AnomalyRules) isStuck(previous []models.Co2Data, data models.Co2Data) bool {
+	for i := len(previous) - 1; i >= 0; i-- {
+		if previous[i].CO2 != data.CO2 {
+			return false
+		}
+		if data.CreatedAt.Sub(previous[i].CreatedAt) >= r.StuckDuration {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r AnomalyRules) isDrift(previous []models.Co2Data, data models.Co2Data) bool {
+	if data.CO2 < r.OutdoorBaseline-r.DriftTolerance {
+		return true
+	}
+
+	// the minimum is only meaningful if the readings cover the whole window
+	if len(previous) == 0 || data.CreatedAt.Sub(previous[0].CreatedAt) < r.DriftWindow*9/10 {
+		return false
+	}
+
+	minimum := data.CO2
+	for _, reading := range previous {
+		if data.CreatedAt.Sub(reading.CreatedAt) <= r.DriftWindow && reading.CO2 < minimum {
+			minimum = reading.CO2
+		}
+	}
+
+	return minimum > r.OutdoorBaseline+r.DriftTolerance
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
@@ -0,0 +1,68 @@
+package extensions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errNoArray = errors.New("the body has to be a JSON array")
+
+// BatchTooLongError is returned when a batch has more items than allowed.
+type BatchTooLongError struct {
+	Max int
+}
+
+func (e *BatchTooLongError) Error() string {
+	return fmt.Sprintf("the batch has more than %d items", e.Max)
+}
+
+// UnmarshalItems decodes the raw items of a batch, an error names the index
+// of the item like DecodeBatch.
+func UnmarshalItems[T any](raw []json.RawMessage) ([]T, error) {
+	items := make([]T, len(raw))
+	for i := range raw {
+		if err := json.Unmarshal(raw[i], &items[i]); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+
+	return items, nil
+}
+
+// DecodeBatch reads a JSON array item by item, so a batch which is too long
+// is rejected without reading the rest of it. A max of 0 allows any length.
+func DecodeBatch[T any](r io.Reader, max int) ([]T, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('[') {
+		return nil, errNoArray
+	}
+
+	items := []T{}
+	for decoder.More() {
+		if max > 0 && len(items) == max {
+			return nil, &BatchTooLongError{Max: max}
+		}
+
+		var item T
+		if err := decoder.Decode(&item); err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(items), err)
+		}
+		items = append(items, item)
+	}
+
+	// the closing bracket
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err == nil {
+		return nil, errors.New("the body has data after the array")
+	} else if err != io.EOF {
+		return nil, err
+	}
+
+	return items, nil
+}
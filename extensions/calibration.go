@@ -0,0 +1,81 @@
+package extensions
+
+import (
+	"math"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+// ApplyCalibrations corrects the readings with the calibration of their
+// location which was valid when they were measured: value * scale + offset.
+// If several calibrations are valid, the one which started last wins.
+// Readings without a valid calibration are left untouched.
+func ApplyCalibrations(co2Data []models.Co2Data, calibrations []models.Calibration) []models.Co2Data {
+	for i := range co2Data {
+		calibration, ok := ValidCalibration(calibrations, co2Data[i].LocationID, co2Data[i].CreatedAt)
+		if !ok {
+			continue
+		}
+
+		co2Data[i].CO2 = int(math.Round(float64(co2Data[i].CO2)*float64(scale(calibration.Co2Scale)) + float64(calibration.Co2Offset)))
+		co2Data[i].Temp = co2Data[i].Temp*scale(calibration.TempScale) + calibration.TempOffset
+		co2Data[i].Calibrated = true
+	}
+
+	return co2Data
+}
+
+// ApplyCalibrationsToAggregates corrects the aggregates with the calibration
+// which was valid at the start of their bucket.
+func ApplyCalibrationsToAggregates(aggregates []models.Co2Aggregate, calibrations []models.Calibration) []models.Co2Aggregate {
+	for i := range aggregates {
+		calibration, ok := ValidCalibration(calibrations, aggregates[i].LocationID, aggregates[i].Bucket)
+		if !ok {
+			continue
+		}
+
+		co2Scale := float64(scale(calibration.Co2Scale))
+		co2Offset := float64(calibration.Co2Offset)
+		aggregates[i].AvgCO2 = aggregates[i].AvgCO2*co2Scale + co2Offset
+		aggregates[i].MinCO2 = aggregates[i].MinCO2*co2Scale + co2Offset
+		aggregates[i].MaxCO2 = aggregates[i].MaxCO2*co2Scale + co2Offset
+		if co2Scale < 0 {
+			aggregates[i].MinCO2, aggregates[i].MaxCO2 = aggregates[i].MaxCO2, aggregates[i].MinCO2
+		}
+		aggregates[i].AvgTemp = aggregates[i].AvgTemp*float64(scale(calibration.TempScale)) + float64(calibration.TempOffset)
+		aggregates[i].Calibrated = true
+	}
+
+	return aggregates
+}
+
+// ValidCalibration returns the calibration of the location which was valid at the given time.
+func ValidCalibration(calibrations []models.Calibration, locationId int, at time.Time) (models.Calibration, bool) {
+	var valid models.Calibration
+	found := false
+
+	for _, calibration := range calibrations {
+		if calibration.LocationID != locationId || at.Before(calibration.ValidFrom) {
+			continue
+		}
+		if calibration.ValidTo != nil && !at.Before(*calibration.ValidTo) {
+			continue
+		}
+		if !found || calibration.ValidFrom.After(valid.ValidFrom) {
+			valid = calibration
+			found = true
+		}
+	}
+
+	return valid, found
+}
+
+// a missing scale is stored as 0 and means that the values are not scaled
+func scale(value float32) float32 {
+	if value == 0 {
+		return 1
+	}
+
+	return value
+}
@@ -0,0 +1,123 @@
+package extensions
+
+import (
+	"math"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+const (
+	ForecastModelHolt   = "holt"
+	ForecastModelLinear = "linear"
+)
+
+// ForecastModels are the models ForecastCo2 can fit, the first one is used if
+// no model is requested.
+var ForecastModels = []string{ForecastModelHolt, ForecastModelLinear}
+
+const (
+	// ForecastWindow is the time frame of readings the forecast model is fitted to.
+	ForecastWindow = time.Hour
+	// ForecastStep is the time between two predicted values.
+	ForecastStep = 5 * time.Minute
+	// DefaultForecastHorizon is used if no horizon is requested.
+	DefaultForecastHorizon = time.Hour
+	// MaxForecastHorizon is the longest horizon which may be requested, short-term trends say nothing about the next day.
+	MaxForecastHorizon = 24 * time.Hour
+
+	// smoothing factors of the level and the trend for Holt's linear method
+	holtAlpha = 0.5
+	holtBeta  = 0.3
+)
+
+// HoltTrend applies Holt's linear exponential smoothing to the readings and
+// returns the smoothed level at the newest reading and the trend in ppm per
+// minute. Uneven gaps between readings are taken into account by scaling the
+// trend with the elapsed minutes. The readings have to be sorted from oldest
+// to newest.
+func HoltTrend(co2Data []models.Co2Data) (level float64, trend float64, ok bool) {
+	if len(co2Data) < 2 {
+		return 0, 0, false
+	}
+
+	first := co2Data[0]
+	level = float64(first.CO2)
+	last := first.CreatedAt
+	initialized := false
+
+	for _, data := range co2Data[1:] {
+		elapsed := data.CreatedAt.Sub(last).Minutes()
+		if elapsed <= 0 {
+			continue
+		}
+
+		value := float64(data.CO2)
+		if !initialized {
+			trend = (value - level) / elapsed
+			level = value
+			initialized = true
+		} else {
+			previousLevel := level
+			level = holtAlpha*value + (1-holtAlpha)*(level+trend*elapsed)
+			trend = holtBeta*(level-previousLevel)/elapsed + (1-holtBeta)*trend
+		}
+		last = data.CreatedAt
+	}
+
+	return level, trend, initialized
+}
+
+// ForecastCo2 predicts the co2 values of the readings for the given horizon
+// in steps of ForecastStep and estimates when the threshold will be crossed.
+// ok is false if the model is unknown or could not be fitted to the readings.
+// The horizon is not limited, callers reject horizons longer than
+// MaxForecastHorizon.
+func ForecastCo2(co2Data []models.Co2Data, model string, horizon time.Duration, threshold int) (models.ForecastDto, bool) {
+	SortCo2DataByTime(co2Data)
+
+	var level, slope float64
+	var ok bool
+	switch model {
+	case ForecastModelHolt:
+		level, slope, ok = HoltTrend(co2Data)
+	case ForecastModelLinear:
+		slope, level, ok = Co2Trend(co2Data)
+	}
+	if !ok {
+		return models.ForecastDto{}, false
+	}
+
+	latest := co2Data[len(co2Data)-1]
+	forecast := models.ForecastDto{
+		LocationID:     latest.LocationID,
+		Model:          model,
+		HorizonMinutes: int(horizon.Minutes()),
+		Threshold:      threshold,
+		SlopePerMinute: math.Round(slope*100) / 100,
+		Predictions:    []models.ForecastPointDto{},
+	}
+
+	for step := ForecastStep; ; step += ForecastStep {
+		if step > horizon {
+			step = horizon
+		}
+		predicted := math.Max(0, level+slope*step.Minutes())
+		forecast.Predictions = append(forecast.Predictions, models.ForecastPointDto{
+			Time: latest.CreatedAt.Add(step),
+			CO2:  int(math.Round(predicted)),
+		})
+		if step == horizon {
+			break
+		}
+	}
+
+	if minutes, ok := MinutesUntil(level, float64(threshold), slope); ok && minutes <= horizon.Minutes() {
+		roundedMinutes := int(math.Round(minutes))
+		crossingAt := latest.CreatedAt.Add(time.Duration(minutes * float64(time.Minute))).Truncate(time.Second)
+		forecast.MinutesUntilThreshold = &roundedMinutes
+		forecast.ThresholdCrossingAt = &crossingAt
+	}
+
+	return forecast, true
+}
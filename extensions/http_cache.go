@@ -0,0 +1,97 @@
+package extensions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+)
+
+// LiveCacheControl makes clients revalidate every time, the response can
+// change with the next reading.
+const LiveCacheControl = "private, no-cache"
+
+// ClosedWindowDelay is the time after which a time window counts as closed.
+// Sensors may post their readings a little late, so the end of the window
+// has to be this far in the past.
+const ClosedWindowDelay = 5 * time.Minute
+
+// ETag returns a strong entity tag of the JSON representation of the value.
+// Equal responses get equal tags, so it does not matter which instance of the
+// api or which cache produced them.
+func ETag(value any) (string, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// ETagMatches reports whether the If-None-Match header contains the tag.
+// Weak tags match their strong counterpart as RFC 9110 asks for GET requests.
+func ETagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NotModified evaluates the conditional headers of a GET request. Like RFC
+// 9110 asks, If-Modified-Since is ignored when If-None-Match is sent.
+func NotModified(ifNoneMatch string, ifModifiedSince string, etag string, lastModified time.Time) bool {
+	if ifNoneMatch != "" {
+		return ETagMatches(ifNoneMatch, etag)
+	}
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// LastModified returns the latest update time of the entities or the zero
+// time if there are none.
+func LastModified[T any](entities []T, updatedAt func(T) time.Time) time.Time {
+	var lastModified time.Time
+	for _, entity := range entities {
+		if t := updatedAt(entity); t.After(lastModified) {
+			lastModified = t
+		}
+	}
+
+	return lastModified
+}
+
+// WindowCacheControl returns the Cache-Control header of a time window which
+// ends at to. Closed windows never get new readings and may be cached for
+// the configured max age, which is short as calibrations still change their
+// values. Open ones have to be revalidated.
+func WindowCacheControl(to time.Time, now time.Time) string {
+	maxAge := config.Get().Cache.ClosedWindowMaxAge
+	if maxAge <= 0 || to.After(now.Add(-ClosedWindowDelay)) {
+		return LiveCacheControl
+	}
+
+	return fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds()))
+}
@@ -1,47 +1,174 @@
 package extensions
 
 import (
+	"fmt"
+	"math"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+)
+
+// DefaultPeriod is the time frame of requests which do not pass a period.
+const DefaultPeriod = 6 * time.Hour
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// periodUnits are the units of compact periods in the order they have to be
+// written, e.g. 1w2d12h30m.
+var periodUnits = []struct {
+	symbol string
+	unit   time.Duration
+}{
+	{"w", week},
+	{"d", day},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
 
-	"github.com/charmbracelet/log"
+var (
+	compactPeriod = regexp.MustCompile(`^(\d+)([a-z])`)
+	isoPeriod     = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
 )
 
-func ValidateTimeDuration(input string) time.Duration {
-	defaultDuration := 6 * time.Hour
+// PeriodError tells why a period could not be parsed. The reason is written
+// for clients, e.g. as detail of a problem response.
+type PeriodError struct {
+	Input  string
+	Reason string
+}
+
+func (e *PeriodError) Error() string {
+	return fmt.Sprintf("invalid period %q: %s", e.Input, e.Reason)
+}
+
+// ParsePeriod parses a positive compact duration like 30m, 6h, 1d12h or 2w,
+// or an ISO 8601 duration like P7D or PT1H30M. Years and months are rejected
+// since their length differs. Periods longer than max are rejected unless max
+// is 0.
+func ParsePeriod(input string, max time.Duration) (time.Duration, error) {
+	var duration time.Duration
+	var ok bool
+	if strings.HasPrefix(input, "P") {
+		if isoPeriod.MatchString(input) {
+			if match := isoPeriod.FindStringSubmatch(input); match[1] != "" || match[2] != "" {
+				return 0, &PeriodError{Input: input, Reason: "Years and months are not supported, use weeks or days, e.g. P30D."}
+			}
+		}
+		duration, ok = parseIsoPeriod(input)
+	} else {
+		duration, ok = parseCompactPeriod(input)
+	}
+
+	if !ok {
+		return 0, &PeriodError{Input: input, Reason: "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."}
+	}
+	if duration <= 0 {
+		return 0, &PeriodError{Input: input, Reason: "The period has to be longer than 0."}
+	}
+	if max > 0 && duration > max {
+		return 0, &PeriodError{Input: input, Reason: fmt.Sprintf("The period must not be longer than %s.", FormatPeriod(max))}
+	}
+
+	return duration, nil
+}
+
+// FormatPeriod writes the duration as compact period, e.g. 90d or 1d12h.
+func FormatPeriod(duration time.Duration) string {
+	if duration <= 0 {
+		return "0s"
+	}
+
+	var b strings.Builder
+	for _, u := range periodUnits[1:] {
+		if count := duration / u.unit; count > 0 {
+			b.WriteString(strconv.FormatInt(int64(count), 10))
+			b.WriteString(u.symbol)
+			duration -= count * u.unit
+		}
+	}
 
-	// Prüfe, ob der String mindestens zwei Zeichen hat
-	if len(input) < 2 {
-		log.Infof("Ungültige Eingabe bei TimeDuration: %s", input)
-		return defaultDuration
+	return b.String()
+}
+
+func parseCompactPeriod(input string) (time.Duration, bool) {
+	if input == "" {
+		return 0, false
 	}
 
-	// Extrahiere die letzte Zeichen als Einheit (m, h, d)
-	unit := input[len(input)-1]
+	var duration time.Duration
+	next := 0
+	for input != "" {
+		match := compactPeriod.FindStringSubmatch(input)
+		if match == nil {
+			return 0, false
+		}
+
+		index := unitIndex(match[2])
+		// Every unit may appear once and only after the larger ones.
+		if index < next {
+			return 0, false
+		}
+		next = index + 1
 
-	// Prüfe, ob die Einheit gültig ist
-	if unit != 'm' && unit != 'h' && unit != 'd' {
-		log.Infof("Ungültige Einheit bei TimeDuration: %s", input)
-		return defaultDuration
+		var ok bool
+		if duration, ok = addUnits(duration, match[1], periodUnits[index].unit); !ok {
+			return 0, false
+		}
+		input = input[len(match[0]):]
 	}
 
-	// Extrahiere den numerischen Wert aus dem String
-	valueStr := input[:len(input)-1]
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
-		log.Infof("Ungültiger numerischer Wert bei TimeDuration: %s", input)
-		return defaultDuration
+	return duration, true
+}
+
+func parseIsoPeriod(input string) (time.Duration, bool) {
+	match := isoPeriod.FindStringSubmatch(input)
+	// P and PT alone have no value.
+	if match == nil || input == "P" || strings.HasSuffix(input, "T") {
+		return 0, false
 	}
 
-	// Konvertiere den numerischen Wert in eine TimeDuration basierend auf der Einheit
-	switch unit {
-	case 'm':
-		return time.Duration(value) * time.Minute
-	case 'h':
-		return time.Duration(value) * time.Hour
-	case 'd':
-		return time.Duration(value) * time.Hour * 24
-	default:
-		return defaultDuration
+	var duration time.Duration
+	units := []time.Duration{week, day, time.Hour, time.Minute, time.Second}
+	for i, value := range match[3:] {
+		if value == "" {
+			continue
+		}
+
+		var ok bool
+		if duration, ok = addUnits(duration, value, units[i]); !ok {
+			return 0, false
+		}
 	}
+
+	return duration, true
+}
+
+func unitIndex(symbol string) int {
+	for i, u := range periodUnits {
+		if u.symbol == symbol {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// addUnits adds count times unit to the duration unless it overflows.
+func addUnits(duration time.Duration, count string, unit time.Duration) (time.Duration, bool) {
+	value, err := strconv.ParseInt(count, 10, 64)
+	if err != nil || value > int64(math.MaxInt64/unit) {
+		return 0, false
+	}
+
+	added := time.Duration(value) * unit
+	if duration > math.MaxInt64-added {
+		return 0, false
+	}
+
+	return duration + added, true
 }
@@ -0,0 +1,88 @@
+package extensions
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+// ValueRange is the physical range of a value of a reading, both ends included.
+type ValueRange struct {
+	Min  float64
+	Max  float64
+	Unit string
+}
+
+// ReadingRules tell which values of a reading are physically possible. Unlike
+// the anomaly rules they do not flag a reading, a reading out of range is
+// rejected or quarantined.
+type ReadingRules struct {
+	CO2  ValueRange
+	Temp ValueRange
+}
+
+// ReadingRulesFromConfig returns the configured ranges.
+func ReadingRulesFromConfig() ReadingRules {
+	readings := config.Get().Readings
+
+	return ReadingRules{
+		CO2:  ValueRange{Min: float64(readings.CO2Min), Max: float64(readings.CO2Max), Unit: "ppm"},
+		Temp: ValueRange{Min: readings.TempMin, Max: readings.TempMax, Unit: "°C"},
+	}
+}
+
+func (r ValueRange) contains(value float64) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+func (r ValueRange) detail(name string, value interface{}) string {
+	return fmt.Sprintf("%s has to be between %g and %g %s, got %v.", name, r.Min, r.Max, r.Unit, value)
+}
+
+// Check returns an error for every value of the reading which is out of range.
+func (r ReadingRules) Check(data models.Co2Data) []models.FieldErrorDto {
+	fieldErrors := []models.FieldErrorDto{}
+	if !r.CO2.contains(float64(data.CO2)) {
+		fieldErrors = append(fieldErrors, models.FieldErrorDto{Pointer: "/co2", Detail: r.CO2.detail("co2", data.CO2)})
+	}
+	if !r.Temp.contains(float64(data.Temp)) {
+		fieldErrors = append(fieldErrors, models.FieldErrorDto{Pointer: "/temp", Detail: r.Temp.detail("temp", data.Temp)})
+	}
+
+	return fieldErrors
+}
+
+// MissingFields returns a required error for every field which is not in the
+// JSON object. Unlike the required rule of the validator it accepts zero
+// values, e.g. a temperature of 0 °C. Keys match case-insensitively like
+// encoding/json decodes them.
+func MissingFields(item json.RawMessage, fields ...string) []models.FieldErrorDto {
+	present := map[string]json.RawMessage{}
+	if err := json.Unmarshal(item, &present); err != nil {
+		return []models.FieldErrorDto{{Pointer: "", Detail: "has to be an object"}}
+	}
+
+	fieldErrors := []models.FieldErrorDto{}
+	for _, field := range fields {
+		if !hasField(present, field) {
+			fieldErrors = append(fieldErrors, models.FieldErrorDto{Pointer: "/" + escapePointer(field), Detail: "required"})
+		}
+	}
+
+	return fieldErrors
+}
+
+// hasField reports whether a key of the object matches the field and is not
+// null, a null leaves the decoded field unset.
+func hasField(present map[string]json.RawMessage, field string) bool {
+	for key, value := range present {
+		if strings.EqualFold(key, field) && string(value) != "null" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,61 @@
+package extensions
+
+import (
+	"sort"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+// SortCo2DataByTime sorts the readings in place from oldest to newest.
+func SortCo2DataByTime(co2Data []models.Co2Data) {
+	sort.SliceStable(co2Data, func(i, j int) bool {
+		return co2Data[i].CreatedAt.Before(co2Data[j].CreatedAt)
+	})
+}
+
+// Co2Trend fits a least squares line through the readings and returns the
+// slope in ppm per minute together with the fitted value at the time of the
+// newest reading. The readings have to be sorted from oldest to newest. ok is
+// false if there are less than two readings or they all share one timestamp.
+func Co2Trend(co2Data []models.Co2Data) (slope float64, fitted float64, ok bool) {
+	if len(co2Data) < 2 {
+		return 0, 0, false
+	}
+
+	origin := co2Data[0].CreatedAt
+	n := float64(len(co2Data))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, data := range co2Data {
+		x := data.CreatedAt.Sub(origin).Minutes()
+		y := float64(data.CO2)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, 0, false
+	}
+
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	last := co2Data[len(co2Data)-1].CreatedAt.Sub(origin).Minutes()
+
+	return slope, intercept + slope*last, true
+}
+
+// MinutesUntil returns the minutes it takes to get from value to threshold
+// with the given slope in ppm per minute. ok is false if the threshold is
+// never reached.
+func MinutesUntil(value float64, threshold float64, slope float64) (minutes float64, ok bool) {
+	if value >= threshold {
+		return 0, true
+	}
+	if slope <= 0 {
+		return 0, false
+	}
+
+	return (threshold - value) / slope, true
+}
@@ -1,6 +1,10 @@
 package extensions
 
 import (
+	"sort"
+	"strings"
+
+	"github.com/fminister/co2monitor.api/models"
 	"github.com/golodash/galidator"
 )
 
@@ -9,3 +13,42 @@ var g = galidator.G()
 func Validator(s interface{}) galidator.Validator {
 	return g.Validator(s)
 }
+
+// ValidationErrors flattens the result of a galidator validation into field
+// errors. They are sorted, so the same body always gets the same response.
+func ValidationErrors(result interface{}) []models.FieldErrorDto {
+	fieldErrors := []models.FieldErrorDto{}
+	collectValidationErrors(result, "", &fieldErrors)
+
+	sort.Slice(fieldErrors, func(i, j int) bool {
+		if fieldErrors[i].Pointer != fieldErrors[j].Pointer {
+			return fieldErrors[i].Pointer < fieldErrors[j].Pointer
+		}
+		return fieldErrors[i].Detail < fieldErrors[j].Detail
+	})
+
+	return fieldErrors
+}
+
+func collectValidationErrors(result interface{}, pointer string, fieldErrors *[]models.FieldErrorDto) {
+	switch value := result.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			collectValidationErrors(nested, pointer+"/"+escapePointer(key), fieldErrors)
+		}
+	case map[string][]string:
+		for key, messages := range value {
+			collectValidationErrors(messages, pointer+"/"+escapePointer(key), fieldErrors)
+		}
+	case []string:
+		for _, message := range value {
+			*fieldErrors = append(*fieldErrors, models.FieldErrorDto{Pointer: pointer, Detail: message})
+		}
+	case string:
+		*fieldErrors = append(*fieldErrors, models.FieldErrorDto{Pointer: pointer, Detail: value})
+	}
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,88 @@
+package extensions
+
+import (
+	"math"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+const (
+	VentilateNow          = "open_window_now"
+	VentilateSoon         = "open_window_soon"
+	VentilationSufficient = "ventilation_sufficient"
+
+	SlopeMeasured  = "measured"
+	SlopeEstimated = "estimated"
+	SlopeUnknown   = "unknown"
+)
+
+const (
+	// VentilationThreshold is the co2 concentration in ppm at which a room should be aired.
+	VentilationThreshold = 1000
+	// VentilationWindow is the time frame of readings used to measure the co2 slope.
+	VentilationWindow = 30 * time.Minute
+	// VentilationLeadTime is the time before the threshold is reached at which airing is recommended right away.
+	VentilationLeadTime = 5 * time.Minute
+	// VentilationHorizon is the time frame in which an upcoming threshold crossing is announced.
+	VentilationHorizon = 60 * time.Minute
+	// VentilationMaxReadingAge is the age up to which the latest reading is used if the window is empty.
+	VentilationMaxReadingAge = 2 * time.Hour
+
+	// a resting adult exhales roughly 0.3 l co2 per minute, which raises the
+	// concentration of one cubic meter of sealed air by 300 ppm per minute
+	co2PpmPerPersonAndCubicMeter = 300
+	minReadingsForMeasuredSlope  = 3
+)
+
+// RecommendVentilation derives a ventilation recommendation for a location
+// from its recent readings. The slope is measured from the readings if there
+// are enough of them, otherwise it is estimated from the room volume and the
+// occupancy of the location. co2Data must contain at least one reading.
+func RecommendVentilation(location models.Location, co2Data []models.Co2Data) models.VentilationDto {
+	SortCo2DataByTime(co2Data)
+	latest := co2Data[len(co2Data)-1]
+
+	slope, source := ventilationSlope(location, co2Data)
+
+	recommendation := models.VentilationDto{
+		LocationID:     location.ID,
+		Recommendation: VentilationSufficient,
+		LatestCO2:      latest.CO2,
+		Threshold:      VentilationThreshold,
+		SlopePerMinute: math.Round(slope*100) / 100,
+		SlopeSource:    source,
+		MeasuredAt:     latest.CreatedAt,
+	}
+
+	minutes, ok := MinutesUntil(float64(latest.CO2), VentilationThreshold, slope)
+	if !ok {
+		return recommendation
+	}
+
+	roundedMinutes := int(math.Round(minutes))
+	recommendation.MinutesUntilThreshold = &roundedMinutes
+
+	switch {
+	case minutes <= VentilationLeadTime.Minutes():
+		recommendation.Recommendation = VentilateNow
+	case minutes <= VentilationHorizon.Minutes():
+		recommendation.Recommendation = VentilateSoon
+	}
+
+	return recommendation
+}
+
+func ventilationSlope(location models.Location, co2Data []models.Co2Data) (float64, string) {
+	if len(co2Data) >= minReadingsForMeasuredSlope {
+		if slope, _, ok := Co2Trend(co2Data); ok {
+			return slope, SlopeMeasured
+		}
+	}
+
+	if location.RoomVolume > 0 && location.Occupancy > 0 {
+		return float64(location.Occupancy) * co2PpmPerPersonAndCubicMeter / float64(location.RoomVolume), SlopeEstimated
+	}
+
+	return 0, SlopeUnknown
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"gorm.io/gorm"
+
+	"github.com/fminister/co2monitor.api/db/migrations"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/workers"
+)
+
+const databasePingTimeout = 2 * time.Second
+
+var startedAt = time.Now()
+
+// Liveness only tells that the process is up and serving requests.
+func Liveness() models.HealthDto {
+	return models.HealthDto{
+		Status: models.HealthOk,
+		Uptime: time.Since(startedAt).Round(time.Second).String(),
+	}
+}
+
+// Checker bundles the dependencies of the readiness checks, so the
+// controllers do not have to know about the database.
+type Checker struct {
+	DB        *gorm.DB
+	Heartbeat *workers.HeartbeatWatcher
+}
+
+func (c *Checker) Readiness(ctx context.Context, logger *log.Logger) models.HealthDto {
+	return Readiness(ctx, c.DB, c.Heartbeat, logger)
+}
+
+// Readiness checks the database, the schema, the background workers and
+// the ingestion. A failing check makes the service unavailable, a degraded
+// check only marks it as degraded. The probes are not authenticated, so the
+// errors of the database are only logged.
+func Readiness(ctx context.Context, db *gorm.DB, heartbeat *workers.HeartbeatWatcher, logger *log.Logger) models.HealthDto {
+	checks := map[string]models.HealthCheckDto{
+		"database":          CheckDatabase(ctx, db, logger),
+		"migrations":        CheckMigrations(db, logger),
+		"heartbeat_watcher": CheckHeartbeatWatcher(heartbeat),
+		"ingestion":         Ingest.Check(),
+	}
+
+	status := models.HealthOk
+	for _, check := range checks {
+		switch check.Status {
+		case models.HealthUnavailable:
+			status = models.HealthUnavailable
+		case models.HealthDegraded:
+			if status == models.HealthOk {
+				status = models.HealthDegraded
+			}
+		}
+	}
+
+	return models.HealthDto{
+		Status: status,
+		Uptime: time.Since(startedAt).Round(time.Second).String(),
+		Checks: checks,
+	}
+}
+
+func CheckDatabase(ctx context.Context, db *gorm.DB, logger *log.Logger) models.HealthCheckDto {
+	if db == nil {
+		return models.HealthCheckDto{Status: models.HealthUnavailable, Detail: "not connected"}
+	}
+
+	sqlDb, err := db.DB()
+	if err != nil {
+		logger.Error("Readiness check could not get the database connection.", "error", err)
+		return models.HealthCheckDto{Status: models.HealthUnavailable, Detail: "database unreachable"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, databasePingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := sqlDb.PingContext(ctx); err != nil {
+		logger.Error("Readiness check could not ping the database.", "error", err)
+		return models.HealthCheckDto{Status: models.HealthUnavailable, Detail: "database unreachable"}
+	}
+
+	return models.HealthCheckDto{Status: models.HealthOk, Detail: fmt.Sprintf("ping took %s", time.Since(start).Round(time.Microsecond))}
+}
+
+// CheckMigrations makes sure that all migrations are applied.
+func CheckMigrations(db *gorm.DB, logger *log.Logger) models.HealthCheckDto {
+	if db == nil {
+		return models.HealthCheckDto{Status: models.HealthUnavailable, Detail: "not connected"}
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		logger.Error("Readiness check could not load the migrations.", "error", err)
+		return models.HealthCheckDto{Status: models.HealthUnavailable, Detail: "migrations unknown"}
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		logger.Error("Readiness check could not read the applied migrations.", "error", err)
+		return models.HealthCheckDto{Status: models.HealthUnavailable, Detail: "database unreachable"}
+	}
+	if len(pending) > 0 {
+		return models.HealthCheckDto{Status: models.HealthUnavailable, Detail: fmt.Sprintf("%d migrations are pending, the first is %04d_%s", len(pending), pending[0].Version, pending[0].Name)}
+	}
+
+	return models.HealthCheckDto{Status: models.HealthOk}
+}
+
+func CheckHeartbeatWatcher(heartbeat *workers.HeartbeatWatcher) models.HealthCheckDto {
+	if heartbeat == nil || !heartbeat.Running() {
+		return models.HealthCheckDto{Status: models.HealthUnavailable, Detail: "not running"}
+	}
+
+	return models.HealthCheckDto{Status: models.HealthOk}
+}
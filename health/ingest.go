@@ -0,0 +1,59 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+const (
+	// ingestFailureThreshold is the number of failed ingests in a row after which ingestion is degraded.
+	ingestFailureThreshold = 3
+)
+
+// IngestTracker remembers whether storing readings has been failing lately.
+// It keeps no errors, the readiness is public and the failed requests log
+// them.
+type IngestTracker struct {
+	mu                  sync.Mutex
+	consecutiveFailures int
+	lastFailure         time.Time
+	lastSuccess         time.Time
+}
+
+var Ingest = &IngestTracker{}
+
+// Record is called after every attempt to store readings, err is nil on success.
+func (t *IngestTracker) Record(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err == nil {
+		t.consecutiveFailures = 0
+		t.lastSuccess = time.Now()
+		return
+	}
+
+	t.consecutiveFailures++
+	t.lastFailure = time.Now()
+}
+
+func (t *IngestTracker) Check() models.HealthCheckDto {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.consecutiveFailures >= ingestFailureThreshold {
+		return models.HealthCheckDto{
+			Status: models.HealthDegraded,
+			Detail: fmt.Sprintf("%d ingests failed in a row, last at %s", t.consecutiveFailures, t.lastFailure.Format(time.RFC3339)),
+		}
+	}
+
+	if t.lastSuccess.IsZero() {
+		return models.HealthCheckDto{Status: models.HealthOk, Detail: "no readings ingested yet"}
+	}
+
+	return models.HealthCheckDto{Status: models.HealthOk, Detail: fmt.Sprintf("last ingest at %s", t.lastSuccess.Format(time.RFC3339))}
+}
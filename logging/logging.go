@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/gin-gonic/gin"
+)
+
+const megabyte = 1 << 20
+
+// Setup configures the global logger with the level and format of the
+// configuration. The logs go to stdout and, if a file is configured, to a
+// rotating log file which the returned closer closes.
+func Setup(logConfig config.LogConfig) (io.Closer, error) {
+	var output io.Writer = os.Stdout
+	var closer io.Closer = nopCloser{}
+	if logConfig.File != "" {
+		file, err := NewRotatingFile(logConfig.File, int64(logConfig.MaxSizeMB)*megabyte, logConfig.RotateInterval, logConfig.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		output = io.MultiWriter(os.Stdout, file)
+		closer = file
+	}
+
+	Configure(log.Default(), output, logConfig)
+	gin.DefaultWriter = output
+	gin.DefaultErrorWriter = output
+
+	return closer, nil
+}
+
+// Configure sets output, level and format of the logger. JSON lines carry the
+// time, level, caller and message as fields.
+func Configure(logger *log.Logger, output io.Writer, logConfig config.LogConfig) {
+	logger.SetOutput(output)
+	logger.SetLevel(log.ParseLevel(logConfig.Level))
+	logger.SetReportTimestamp(true)
+	logger.SetReportCaller(true)
+	if logConfig.Format == config.LogFormatText {
+		logger.SetFormatter(log.TextFormatter)
+		logger.SetTimeFormat(time.RFC1123)
+	} else {
+		logger.SetFormatter(log.JSONFormatter)
+		logger.SetTimeFormat(time.RFC3339Nano)
+	}
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// RedactKey replaces an API key with a short fingerprint, so log lines of
+// the same key can be matched without writing the key itself.
+func RedactKey(key string) string {
+	if key == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,236 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "20060102T150405"
+
+// RotatingFile appends to a log file and moves it aside once it grows past
+// MaxSize bytes or gets older than Interval. Rotated files are named after
+// the time of the rotation, e.g. gin-20230801T120000.log, and only the
+// newest MaxBackups of them are kept.
+type RotatingFile struct {
+	Path       string
+	MaxSize    int64
+	Interval   time.Duration
+	MaxBackups int
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// NewRotatingFile opens the file for appending, so restarts keep the logs of
+// the previous run.
+func NewRotatingFile(path string, maxSize int64, interval time.Duration, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{Path: path, MaxSize: maxSize, Interval: interval, MaxBackups: maxBackups, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// SetClock replaces the clock used to decide on time based rotations.
+func (r *RotatingFile) SetClock(now func() time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.now = now
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	// A failed rotation is tried again with the next write, until then the
+	// logs keep going to the reopened file.
+	if r.due(int64(len(p))) {
+		if err := r.rotate(); err != nil && r.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+
+	return err
+}
+
+// due reports whether the next write has to go to a new file. An empty file
+// is never rotated, so a single large write does not rotate in a loop.
+func (r *RotatingFile) due(next int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.MaxSize > 0 && r.size+next > r.MaxSize {
+		return true
+	}
+
+	return r.Interval > 0 && r.now().Sub(r.openedAt) >= r.Interval
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(r.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	// The modification time is the best guess for the age of a file of a
+	// previous run.
+	r.openedAt = r.now()
+	if r.size > 0 {
+		r.openedAt = info.ModTime()
+	}
+
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+
+	if err := os.Rename(r.Path, r.backupPath(r.now())); err != nil {
+		if openErr := r.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	return r.prune()
+}
+
+func (r *RotatingFile) backupPath(at time.Time) string {
+	ext := filepath.Ext(r.Path)
+	base := strings.TrimSuffix(r.Path, ext)
+	stamp := at.UTC().Format(backupTimeFormat)
+
+	// Rotations within the same second get a counter above the ones of the
+	// earlier rotations, so they still sort after them.
+	counter := -1
+	backups, _ := r.backups()
+	for _, b := range backups {
+		if b.stamp == stamp && b.counter > counter {
+			counter = b.counter
+		}
+	}
+	if counter < 0 {
+		return fmt.Sprintf("%s-%s%s", base, stamp, ext)
+	}
+
+	return fmt.Sprintf("%s-%s.%d%s", base, stamp, counter+1, ext)
+}
+
+// prune removes the oldest rotated files beyond MaxBackups.
+func (r *RotatingFile) prune() error {
+	if r.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := r.Backups()
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > r.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// Backups returns the rotated files, the oldest first.
+func (r *RotatingFile) Backups() ([]string, error) {
+	found, err := r.backups()
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]string, len(found))
+	for i, b := range found {
+		backups[i] = b.path
+	}
+
+	return backups, nil
+}
+
+type backup struct {
+	path    string
+	stamp   string
+	counter int
+}
+
+func (r *RotatingFile) backups() ([]backup, error) {
+	ext := filepath.Ext(r.Path)
+	base := strings.TrimSuffix(r.Path, ext)
+	matches, err := filepath.Glob(base + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+
+	found := []backup{}
+	for _, match := range matches {
+		stamp, counter, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(match, base+"-"), ext), ".")
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		number, _ := strconv.Atoi(counter)
+		found = append(found, backup{path: match, stamp: stamp, counter: number})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].stamp != found[j].stamp {
+			return found[i].stamp < found[j].stamp
+		}
+		return found[i].counter < found[j].counter
+	})
+
+	return found, nil
+}
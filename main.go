@@ -1,28 +1,50 @@
 package main
 
 import (
-	"fmt"
-	"io"
-	"net/http"
+	"context"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
 	"github.com/charmbracelet/log"
 	swaggerFiles "github.com/swaggo/files"     // swagger embed files
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 
+	"github.com/fminister/co2monitor.api/cache"
+	"github.com/fminister/co2monitor.api/config"
 	"github.com/fminister/co2monitor.api/db"
 	"github.com/fminister/co2monitor.api/docs"
-	"github.com/fminister/co2monitor.api/initializers"
+	"github.com/fminister/co2monitor.api/logging"
+	"github.com/fminister/co2monitor.api/metrics"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/ratelimit"
 	"github.com/fminister/co2monitor.api/routes"
-	"github.com/gin-contrib/gzip"
+	"github.com/fminister/co2monitor.api/server"
+	"github.com/fminister/co2monitor.api/tracing"
+	"github.com/fminister/co2monitor.api/workers"
 	"github.com/gin-gonic/gin"
 )
 
-func init() {
-	initializers.LoadEnvVariables()
+func setup() {
 	db.ConnectToDb()
-	initializers.SyncDatabase()
+	if config.Get().Database.AutoMigrate {
+		migrateUp()
+	}
+	timescale, err := db.SetupTimescale(db.GetDB())
+	if err != nil {
+		log.Error("Failed to set up TimescaleDB, aggregates are computed from the co2 data. \n", err)
+	}
+	db.DB.Timescale = timescale
+	if err := cache.Setup(config.Get().Cache); err != nil {
+		log.Error("Failed to set up the cache, latest readings are read from the database. \n", err)
+	}
+	if err := ratelimit.Setup(config.Get().RateLimit, config.Get().Cache.RedisURL); err != nil {
+		log.Error("Failed to set up the rate limit, requests are not limited. \n", err)
+	}
+	workers.StartHeartbeatWatcher(context.Background(), db.GetDB())
+	if err := metrics.Register(db.GetDB()); err != nil {
+		log.Fatal("Failed to register metrics. \n", err)
+	}
 }
 
 // @securityDefinitions.apikey ApiKeyAuth
@@ -30,52 +52,84 @@ func init() {
 // @name X-API-KEY
 // @description Paste in the api key
 func main() {
-	f, _ := os.Create("logs/gin.log")
-	gin.DefaultWriter = io.MultiWriter(f, os.Stdout)
+	args := os.Args[1:]
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		printConfig(args[2:])
+		return
+	}
+	if len(args) >= 1 && args[0] == "migrate" {
+		migrate(args[1:])
+		return
+	}
 
-	log.SetTimeFormat(fmt.Sprintf("[%s]", time.RFC1123))
-	log.SetReportCaller(true)
-	log.SetReportTimestamp(true)
-	log.SetOutput(io.MultiWriter(f, os.Stdout))
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatal("Invalid configuration. \n", err)
+	}
+	config.Set(cfg)
+
+	logs, err := logging.Setup(cfg.Log)
+	if err != nil {
+		log.Fatal("Could not open the log file. \n", err)
+	}
+	log.Infof(`Loaded configuration. Config: <%s>`, cfg)
+
+	stopTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal("Failed to set up tracing. \n", err)
+	}
+
+	setup()
 
 	app := gin.New()
 
 	docs.SwaggerInfo.Title = "CO2 Monitor API"
 	docs.SwaggerInfo.Description = "CO2 Monitor API for the CO2 Monitor project."
 	docs.SwaggerInfo.Version = "1.0"
-	docs.SwaggerInfo.Host = os.Getenv("APP_HOST")
+	docs.SwaggerInfo.Host = cfg.App.Host
 	docs.SwaggerInfo.BasePath = "/api"
-	if os.Getenv("APP_ENV") != "development" {
+	if !cfg.Development() {
 		docs.SwaggerInfo.Schemes = []string{"https"}
 	} else {
 		docs.SwaggerInfo.Schemes = []string{"http"}
 	}
 
-	app.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("[%s] - %s \"%s %s %s %d %s %s\"\n",
-			param.TimeStamp.Format(time.RFC1123),
-			param.ClientIP,
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.ErrorMessage,
-		)
-	}))
-	app.Use(gin.Recovery())
-	app.Use(gzip.Gzip(gzip.DefaultCompression))
+	app.HandleMethodNotAllowed = true
+	app.Use(middleware.Global(cfg.Tracing)...)
 
 	router := app.Group("/api")
 	routes.AddRoutes(router)
 
 	app.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	app.GET("/metrics", gin.WrapH(metrics.Handler()))
+	routes.AddHealthRoutes(app)
 
-	app.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{"message": "The requested route does not exist."})
-	})
+	app.NoRoute(middleware.NoRoute)
+	app.NoMethod(middleware.NoMethod)
+
+	srv := server.New(app, cfg.Server)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err = server.Run(ctx, srv, cfg.Server,
+		func(ctx context.Context) error {
+			workers.GetHeartbeatWatcher().Stop()
+			return nil
+		},
+		cache.Close,
+		ratelimit.Close,
+		func(ctx context.Context) error {
+			return db.CloseDb()
+		},
+		stopTracing,
+	)
+	if err != nil {
+		log.Fatal("Server stopped with error. \n", err)
+	}
 
-	app.Run()
+	log.Info("Server stopped.")
+	logs.Close()
 }
 
 // CompileDaemon -command="./co2monitor.api
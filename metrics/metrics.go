@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+const namespace = "co2monitor"
+
+var (
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of handled HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of handled HTTP requests by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	IngestBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ingest_batch_size",
+		Help:      "Number of co2 readings per ingest request.",
+		Buckets:   []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+	})
+
+	IngestedReadingsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ingested_readings_total",
+		Help:      "Number of stored co2 readings by quality flag.",
+	}, []string{"quality"})
+
+	QuarantinedReadingsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quarantined_readings_total",
+		Help:      "Number of co2 readings held back for values out of range.",
+	})
+
+	LatestCacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "latest_cache_requests_total",
+		Help:      "Number of latest reading lookups by result (hit, miss or error).",
+	}, []string{"result"})
+
+	RateLimitedRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Number of requests rejected by the rate limit by route group.",
+	}, []string{"group"})
+)
+
+var registry *prometheus.Registry
+
+// Register creates the global registry which is served by Handler.
+func Register(db *gorm.DB) error {
+	var err error
+	registry, err = NewRegistry(db)
+
+	return err
+}
+
+// NewRegistry returns a registry with the process, request, ingest, db pool
+// and reading metrics.
+func NewRegistry(db *gorm.DB) (*prometheus.Registry, error) {
+	sqlDb, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	registry := prometheus.NewRegistry()
+	for _, collector := range []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(sqlDb, namespace),
+		RequestsTotal,
+		RequestDuration,
+		IngestBatchSize,
+		IngestedReadingsTotal,
+		QuarantinedReadingsTotal,
+		LatestCacheRequestsTotal,
+		RateLimitedRequestsTotal,
+		newReadingsCollector(db),
+	} {
+		if err := registry.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+func Handler() http.Handler {
+	return HandlerFor(registry)
+}
+
+func HandlerFor(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
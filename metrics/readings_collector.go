@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+
+	"github.com/fminister/co2monitor.api/db/db_calls"
+	ex "github.com/fminister/co2monitor.api/extensions"
+)
+
+// readingsCollector exposes the latest calibrated reading of every location.
+// The locations, their latest readings and the calibrations are loaded with
+// one query each per scrape.
+type readingsCollector struct {
+	db       *gorm.DB
+	co2      *prometheus.Desc
+	temp     *prometheus.Desc
+	lastSeen *prometheus.Desc
+}
+
+func newReadingsCollector(db *gorm.DB) *readingsCollector {
+	labels := []string{"location"}
+
+	return &readingsCollector{
+		db:       db,
+		co2:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "location", "co2_ppm"), "Latest co2 reading of a location.", labels, nil),
+		temp:     prometheus.NewDesc(prometheus.BuildFQName(namespace, "location", "temperature_celsius"), "Latest temperature reading of a location.", labels, nil),
+		lastSeen: prometheus.NewDesc(prometheus.BuildFQName(namespace, "location", "last_seen_timestamp_seconds"), "Time of the latest reading of a location.", labels, nil),
+	}
+}
+
+func (r *readingsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.co2
+	ch <- r.temp
+	ch <- r.lastSeen
+}
+
+func (r *readingsCollector) Collect(ch chan<- prometheus.Metric) {
+	locations, err := db_calls.GetLocation(r.db)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(r.co2, err)
+		return
+	}
+
+	co2Data, err := db_calls.GetLatestCo2DataPerLocation(r.db)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(r.co2, err)
+		return
+	}
+
+	calibrations, err := db_calls.GetAllCalibrations(r.db)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(r.co2, err)
+		return
+	}
+	co2Data = ex.ApplyCalibrations(co2Data, calibrations)
+
+	names := map[int]string{}
+	for _, location := range locations {
+		names[int(location.ID)] = location.Name
+	}
+
+	for _, data := range co2Data {
+		name, ok := names[data.LocationID]
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(r.co2, prometheus.GaugeValue, float64(data.CO2), name)
+		ch <- prometheus.MustNewConstMetric(r.temp, prometheus.GaugeValue, float64(data.Temp), name)
+		ch <- prometheus.MustNewConstMetric(r.lastSeen, prometheus.GaugeValue, float64(data.CreatedAt.Unix()), name)
+	}
+}
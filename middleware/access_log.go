@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog writes one line per request with the route, status and latency.
+// Server errors are logged as errors, client errors as warnings. It has to
+// run after RequestID, so the lines carry the request id.
+func AccessLog(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	keyvals := []interface{}{
+		"method", c.Request.Method,
+		"route", route,
+		"path", c.Request.URL.Path,
+		"status", c.Writer.Status(),
+		"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+		"bytes", c.Writer.Size(),
+		"client_ip", c.ClientIP(),
+		"user_agent", c.Request.UserAgent(),
+	}
+
+	logger := Logger(c)
+	switch status := c.Writer.Status(); {
+	case status >= 500:
+		logger.Error("Request failed.", keyvals...)
+	case status >= 400:
+		logger.Warn("Request rejected.", keyvals...)
+	default:
+		logger.Info("Request handled.", keyvals...)
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/gin-contrib/gzip"
+	"github.com/gin-gonic/gin"
+)
+
+// Global returns the middleware of every request in the order it has to be
+// used in. Gzip wraps Recovery and Errors, so their problems are written
+// before gzip closes the response, which counts as written.
+func Global(tracing config.TracingConfig) []gin.HandlerFunc {
+	handlers := []gin.HandlerFunc{}
+	if tracing.Enabled {
+		handlers = append(handlers, Tracing(tracing.ServiceName))
+	}
+
+	return append(handlers,
+		RequestID,
+		AccessLog,
+		Metrics,
+		gzip.Gzip(gzip.DefaultCompression),
+		Recovery,
+		Errors,
+	)
+}
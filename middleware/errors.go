@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// Errors renders the last error a handler added with c.Error as problem
+// details. Errors which are no problems become a 500 without their message.
+func Errors(c *gin.Context) {
+	c.Next()
+
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	err := c.Errors.Last().Err
+	var p *problem.Problem
+	if !errors.As(err, &p) {
+		p = problem.Internal("An unexpected error occurred.").WithCause(err)
+	}
+
+	AbortWithProblem(c, p)
+}
+
+// Recovery turns panics into a 500 problem response.
+var Recovery = gin.CustomRecovery(func(c *gin.Context, recovered any) {
+	Logger(c).Error("Recovered from panic.", "panic", recovered, "stack", string(debug.Stack()))
+	AbortWithProblem(c, problem.Internal("An unexpected error occurred."))
+})
+
+// NoRoute answers requests to routes which do not exist.
+func NoRoute(c *gin.Context) {
+	AbortWithProblem(c, problem.NotFound(problem.CodeRouteNotFound, "The requested route does not exist."))
+}
+
+// NoMethod answers requests with a method the route does not support.
+func NoMethod(c *gin.Context) {
+	AbortWithProblem(c, problem.New(http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "The requested method is not allowed for this route."))
+}
+
+// AbortWithProblem logs the problem and responds with it, for middleware
+// which stops the request before a handler runs.
+func AbortWithProblem(c *gin.Context, p *problem.Problem) {
+	logger := Logger(c)
+	keyvals := []interface{}{"status", p.Status, "code", p.Code, "route", c.FullPath()}
+	if cause := p.Unwrap(); cause != nil {
+		keyvals = append(keyvals, "error", cause)
+	}
+	if p.Status >= http.StatusInternalServerError {
+		logger.Error(p.Detail, keyvals...)
+	} else {
+		logger.Warn(p.Detail, keyvals...)
+	}
+
+	c.Header("Content-Type", problem.ContentType)
+	c.AbortWithStatusJSON(p.Status, p.Dto(c.Request.URL.Path, GetRequestID(c)))
+}
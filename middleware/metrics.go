@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/fminister/co2monitor.api/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics counts the requests and measures their latency per gin route.
+func Metrics(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	metrics.RequestsTotal.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+	metrics.RequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+}
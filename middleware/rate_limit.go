@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/logging"
+	"github.com/fminister/co2monitor.api/metrics"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/fminister/co2monitor.api/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit allows every client rate requests to the route group. Clients are
+// identified by their api key, or by their ip if identifyBy is ip or they
+// sent no or an unknown key. It runs before the api key is checked, so
+// guessing keys is limited as well. The RateLimit-* headers tell clients what
+// is left, limited requests get a 429 with a Retry-After header. Without a
+// store or with the unlimited rate every request passes, and so does it if
+// the store fails.
+func RateLimit(store ratelimit.Store, group string, rate config.Rate, identifyBy string) gin.HandlerFunc {
+	if store == nil || rate.Unlimited() {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	policy := fmt.Sprintf("%d;w=%d", rate.Requests, seconds(rate.Period))
+
+	return func(c *gin.Context) {
+		result, err := store.Take(c.Request.Context(), group+":"+client(c, identifyBy), rate)
+		if err != nil {
+			Logger(c).Error("Could not check the rate limit, the request is let through.", "group", group, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			retryAfter := max(seconds(result.RetryAfter), 1)
+			metrics.RateLimitedRequestsTotal.WithLabelValues(group).Inc()
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			AbortWithProblem(c, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited, fmt.Sprintf("Too many requests. Retry in %d seconds.", retryAfter)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// client is the fingerprint of the api key, so the key is not kept in the
+// store, or the ip of the client. Unknown keys count for the ip, otherwise
+// every guessed key would get a bucket of its own.
+func client(c *gin.Context, identifyBy string) string {
+	if key := c.GetHeader("X-API-KEY"); knownKey(key) && identifyBy == config.IdentifyByKey {
+		return "key:" + logging.RedactKey(key)
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func knownKey(key string) bool {
+	auth := config.Get().Auth
+
+	return key != "" && (key == auth.APIKey || key == auth.AdminAPIKey)
+}
+
+// seconds rounds up, so clients do not retry too early.
+func seconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/charmbracelet/log"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+const (
+	requestIDKey = "request_id"
+	loggerKey    = "logger"
+)
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestID takes the request id of a proxy or creates a new one. It is sent
+// back in the X-Request-ID header, is part of every problem response and of
+// every line written with the logger of the request. Traced requests also log
+// the trace id. The logger is put into the request context as well, for code
+// which only gets the context like the repositories.
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(RequestIDHeader)
+	if !validRequestID.MatchString(id) {
+		id = newRequestID()
+	}
+
+	keyvals := []interface{}{requestIDKey, id}
+	if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+		keyvals = append(keyvals, "trace_id", span.TraceID().String())
+	}
+
+	logger := log.With(keyvals...)
+	c.Set(requestIDKey, id)
+	c.Set(loggerKey, logger)
+	c.Request = c.Request.WithContext(log.WithContext(c.Request.Context(), logger))
+	c.Header(RequestIDHeader, id)
+	c.Next()
+}
+
+// GetRequestID returns the id set by RequestID or an empty string.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// Logger returns the logger of the request, which adds the request id to
+// every line, or the default logger outside of RequestID.
+func Logger(c *gin.Context) *log.Logger {
+	if logger, ok := c.Get(loggerKey); ok {
+		return logger.(*log.Logger)
+	}
+
+	return log.Default()
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(id)
+}
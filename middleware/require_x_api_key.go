@@ -2,20 +2,20 @@ package middleware
 
 import (
 	"net/http"
-	"os"
 
-	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/logging"
+	"github.com/fminister/co2monitor.api/problem"
 	"github.com/gin-gonic/gin"
 )
 
 func RequireApiKey(c *gin.Context) {
 	APIKey := c.Request.Header.Get("X-API-KEY")
-	adminAPIKey := os.Getenv("X_API_KEY_ADMIN")
-	normalAPIKey := os.Getenv("X_API_KEY")
+	adminAPIKey := config.Get().Auth.AdminAPIKey
+	normalAPIKey := config.Get().Auth.APIKey
 
 	if APIKey == "" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
-		log.Infof(`API-Key was not found or wrong. API-Key: <%s>; URL: <%s>`, APIKey, c.Request.URL)
+		AbortWithProblem(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "The X-API-KEY header is missing."))
 		return
 	}
 
@@ -32,6 +32,24 @@ func RequireApiKey(c *gin.Context) {
 		}
 	}
 
-	log.Infof(`Unauthorized API-Key. API-Key: <%s>; URL: <%s>; Method: <%s>; Path: <%s>`, APIKey, c.Request.URL, c.Request.Method, c.FullPath())
-	c.AbortWithStatus(http.StatusUnauthorized)
+	Logger(c).Info("Unauthorized API-Key.", "api_key", logging.RedactKey(APIKey), "method", c.Request.Method, "route", c.FullPath())
+	AbortWithProblem(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "The API key is not allowed to access this route."))
+}
+
+// RequireAdminApiKey only lets requests with the admin api key through, also
+// for reading, e.g. for routes exposing the payloads of other clients.
+func RequireAdminApiKey(c *gin.Context) {
+	APIKey := c.Request.Header.Get("X-API-KEY")
+
+	if APIKey == "" {
+		AbortWithProblem(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "The X-API-KEY header is missing."))
+		return
+	}
+	if APIKey == config.Get().Auth.AdminAPIKey {
+		c.Next()
+		return
+	}
+
+	Logger(c).Info("Unauthorized API-Key.", "api_key", logging.RedactKey(APIKey), "method", c.Request.Method, "route", c.FullPath())
+	AbortWithProblem(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "The API key is not allowed to access this route."))
 }
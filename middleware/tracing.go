@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// untracedPaths are polled by probes and scrapers and would only add noise.
+var untracedPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// Tracing starts a span for every request, continuing the trace of the
+// traceparent header if the client sent one. The span is passed on in the
+// context of the request.
+func Tracing(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return !untracedPaths[r.URL.Path]
+	}))
+}
@@ -0,0 +1,26 @@
+package models
+
+// ItemErrorDto tells why an item of a batch was not created. Index is the
+// position of the item in the posted array.
+type ItemErrorDto struct {
+	Index  int             `json:"index"`
+	Status int             `json:"status"`
+	Code   string          `json:"code"`
+	Detail string          `json:"detail"`
+	Errors []FieldErrorDto `json:"errors,omitempty"`
+}
+
+// Co2DataBatchDto is the response of a partial ingest, the created co2 data
+// and the items which were rejected. Quarantined lists the items with values
+// out of range, which were held back instead of being rejected.
+type Co2DataBatchDto struct {
+	Created     []Co2DataDto   `json:"created"`
+	Failed      []ItemErrorDto `json:"failed"`
+	Quarantined []ItemErrorDto `json:"quarantined"`
+}
+
+// LocationBatchDto is the response of a partial create of locations.
+type LocationBatchDto struct {
+	Created []LocationDto  `json:"created"`
+	Failed  []ItemErrorDto `json:"failed"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Calibration struct {
+	gorm.Model
+	LocationID int        `g:"required" gorm:"not null;" json:"location_id"`
+	Co2Offset  float32    `gorm:"not null;default:0;" json:"co2_offset"`
+	Co2Scale   float32    `gorm:"not null;default:1;" json:"co2_scale"`
+	TempOffset float32    `gorm:"not null;default:0;" json:"temp_offset"`
+	TempScale  float32    `gorm:"not null;default:1;" json:"temp_scale"`
+	ValidFrom  time.Time  `g:"required" gorm:"not null;" json:"valid_from"`
+	ValidTo    *time.Time `json:"valid_to"`
+	Location   Location
+}
+
+type CalibrationDto struct {
+	ID         uint       `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	LocationID int        `json:"location_id"`
+	Co2Offset  float32    `json:"co2_offset"`
+	Co2Scale   float32    `json:"co2_scale"`
+	TempOffset float32    `json:"temp_offset"`
+	TempScale  float32    `json:"temp_scale"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidTo    *time.Time `json:"valid_to"`
+}
+
+type CalibrationPostDto struct {
+	LocationID int        `json:"location_id"`
+	Co2Offset  float32    `json:"co2_offset"`
+	Co2Scale   float32    `json:"co2_scale" example:"1"`
+	TempOffset float32    `json:"temp_offset"`
+	TempScale  float32    `json:"temp_scale" example:"1"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidTo    *time.Time `json:"valid_to"`
+}
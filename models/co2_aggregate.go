@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	AggregateHour = "hour"
+	AggregateDay  = "day"
+)
+
+// Co2Aggregate summarizes the co2 data of a location in one hour or day.
+type Co2Aggregate struct {
+	LocationID int
+	Bucket     time.Time
+	AvgCO2     float64
+	MinCO2     float64
+	MaxCO2     float64
+	AvgTemp    float64
+	Count      int
+	Calibrated bool
+}
+
+type Co2AggregateDto struct {
+	LocationID int       `json:"location_id"`
+	Bucket     time.Time `json:"bucket"`
+	AvgCO2     float64   `json:"avg_co2"`
+	MinCO2     float64   `json:"min_co2"`
+	MaxCO2     float64   `json:"max_co2"`
+	AvgTemp    float64   `json:"avg_temp"`
+	Count      int       `json:"count"`
+	Calibrated bool      `json:"calibrated"`
+}
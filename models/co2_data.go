@@ -6,12 +6,27 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	QualityOk         = "ok"
+	QualityOutOfRange = "out_of_range"
+	QualityJump       = "jump"
+	QualityStuck      = "stuck"
+	QualityDrift      = "drift"
+)
+
+// Co2Data is a reading of a location. The location can be posted by its name
+// instead of its id, the name is resolved to the id before storing. Zero is a
+// valid value, so co2 and temp are only required to be present in the body
+// and are checked against the physical ranges of the readings config.
 type Co2Data struct {
 	gorm.Model
-	CO2        int     `g:"required" gorm:"not null;" json:"co2"`
-	Temp       float32 `g:"required" gorm:"not null;" json:"temp"`
-	LocationID int     `g:"required" gorm:"not null;" json:"location_id"`
-	Location   Location
+	CO2          int     `gorm:"not null;" json:"co2"`
+	Temp         float32 `gorm:"not null;" json:"temp"`
+	LocationID   int     `g:"when_not_exist_one=LocationName" when_not_exist_one:"required unless location_name is given" gorm:"not null;" json:"location_id"`
+	LocationName string  `gorm:"-" json:"location_name,omitempty"`
+	Quality      string  `gorm:"not null;default:ok;" json:"quality"`
+	Calibrated   bool    `gorm:"-" json:"calibrated"`
+	Location     Location
 }
 
 type Co2DataDto struct {
@@ -21,10 +36,13 @@ type Co2DataDto struct {
 	CO2        int       `json:"co2"`
 	Temp       float32   `json:"temp"`
 	LocationID int       `json:"location_id"`
+	Quality    string    `json:"quality"`
+	Calibrated bool      `json:"calibrated"`
 }
 
 type Co2DataPostDto struct {
-	CO2        int     `json:"co2"`
-	Temp       float32 `json:"temp"`
-	LocationID int     `json:"location_id"`
+	CO2          int     `json:"co2"`
+	Temp         float32 `json:"temp"`
+	LocationID   int     `json:"location_id"`
+	LocationName string  `json:"location_name"`
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type ForecastDto struct {
+	LocationID            int                `json:"location_id"`
+	Model                 string             `json:"model"`
+	HorizonMinutes        int                `json:"horizon_minutes"`
+	Threshold             int                `json:"threshold"`
+	SlopePerMinute        float64            `json:"slope_per_minute"`
+	MinutesUntilThreshold *int               `json:"minutes_until_threshold"`
+	ThresholdCrossingAt   *time.Time         `json:"threshold_crossing_at"`
+	Predictions           []ForecastPointDto `json:"predictions"`
+}
+
+type ForecastPointDto struct {
+	Time time.Time `json:"time"`
+	CO2  int       `json:"co2"`
+}
@@ -0,0 +1,18 @@
+package models
+
+const (
+	HealthOk          = "ok"
+	HealthDegraded    = "degraded"
+	HealthUnavailable = "unavailable"
+)
+
+type HealthDto struct {
+	Status string                    `json:"status"`
+	Uptime string                    `json:"uptime,omitempty"`
+	Checks map[string]HealthCheckDto `json:"checks,omitempty"`
+}
+
+type HealthCheckDto struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
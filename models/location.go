@@ -8,16 +8,22 @@ import (
 
 type Location struct {
 	gorm.Model
-	Name string `g:"required,min=3" gorm:"unique;not null;" json:"name"`
+	Name       string  `g:"required,min=3" gorm:"unique;not null;" json:"name"`
+	RoomVolume float32 `g:"min=0" min:"must not be negative" gorm:"not null;default:0;" json:"room_volume"`
+	Occupancy  int     `g:"min=0" min:"must not be negative" gorm:"not null;default:0;" json:"occupancy"`
 }
 
 type LocationDto struct {
-	ID        uint      `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Name      string    `json:"name"`
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Name       string    `json:"name"`
+	RoomVolume float32   `json:"room_volume"`
+	Occupancy  int       `json:"occupancy"`
 }
 
 type LocationPostDto struct {
-	Name string `json:"name"`
+	Name       string  `json:"name"`
+	RoomVolume float32 `json:"room_volume"`
+	Occupancy  int     `json:"occupancy"`
 }
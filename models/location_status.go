@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+const (
+	StatusOnline  = "online"
+	StatusOffline = "offline"
+)
+
+type LocationStatusDto struct {
+	LocationID uint       `json:"location_id"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	LastSeen   *time.Time `json:"last_seen"`
+}
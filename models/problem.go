@@ -0,0 +1,22 @@
+package models
+
+// ProblemDto is an error response following RFC 7807 (problem details). Code
+// is a machine-readable identifier of the error, Errors lists the invalid
+// fields of a request body.
+type ProblemDto struct {
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Status    int             `json:"status"`
+	Detail    string          `json:"detail,omitempty"`
+	Instance  string          `json:"instance,omitempty"`
+	Code      string          `json:"code"`
+	RequestID string          `json:"request_id,omitempty"`
+	Errors    []FieldErrorDto `json:"errors,omitempty"`
+}
+
+// FieldErrorDto points to an invalid value of the request body with a JSON
+// pointer (RFC 6901), e.g. /0/name for the name of the first item.
+type FieldErrorDto struct {
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
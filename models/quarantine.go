@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QuarantinedCo2Data is a posted co2 data item which was rejected or held
+// back. The payload is kept as it was posted, so it can be fixed and
+// replayed. Code, Reason and Errors tell why it was not stored, APIKey
+// (redacted), ClientIP and RequestID where it came from.
+type QuarantinedCo2Data struct {
+	gorm.Model
+	Payload   json.RawMessage `gorm:"serializer:json;not null;"`
+	Code      string          `gorm:"not null;"`
+	Reason    string          `gorm:"not null;"`
+	Errors    []FieldErrorDto `gorm:"serializer:json;not null;"`
+	APIKey    string          `gorm:"not null;default:'';"`
+	ClientIP  string          `gorm:"not null;default:'';"`
+	RequestID string          `gorm:"not null;default:'';"`
+}
+
+type QuarantinedCo2DataDto struct {
+	ID        uint            `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	Code      string          `json:"code"`
+	Reason    string          `json:"reason"`
+	Errors    []FieldErrorDto `json:"errors"`
+	APIKey    string          `json:"api_key"`
+	ClientIP  string          `json:"client_ip"`
+	RequestID string          `json:"request_id"`
+}
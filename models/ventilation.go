@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type VentilationDto struct {
+	LocationID            uint      `json:"location_id"`
+	Recommendation        string    `json:"recommendation"`
+	MinutesUntilThreshold *int      `json:"minutes_until_threshold"`
+	LatestCO2             int       `json:"latest_co2"`
+	Threshold             int       `json:"threshold"`
+	SlopePerMinute        float64   `json:"slope_per_minute"`
+	SlopeSource           string    `json:"slope_source"`
+	MeasuredAt            time.Time `json:"measured_at"`
+}
@@ -0,0 +1,103 @@
+package problem
+
+import (
+	"net/http"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+// ContentType is the media type of problem details responses.
+const ContentType = "application/problem+json"
+
+// Codes tell clients what went wrong without parsing the detail.
+const (
+	CodeInvalidBody         = "invalid_body"
+	CodeBodyTooLarge        = "body_too_large"
+	CodeBatchTooLong        = "batch_too_long"
+	CodeValidationFailed    = "validation_failed"
+	CodeInvalidParameter    = "invalid_parameter"
+	CodeDuplicateName       = "duplicate_name"
+	CodeUnknownReference    = "unknown_reference"
+	CodeQuarantined         = "quarantined"
+	CodeLocationNotFound    = "location_not_found"
+	CodeCo2DataNotFound     = "co2_data_not_found"
+	CodeCalibrationNotFound = "calibration_not_found"
+	CodeQuarantineNotFound  = "quarantine_not_found"
+	CodeNotEnoughData       = "not_enough_data"
+	CodeRouteNotFound       = "route_not_found"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeUnauthorized        = "unauthorized"
+	CodeRateLimited         = "rate_limited"
+	CodeUnavailable         = "service_unavailable"
+	CodeInternal            = "internal_error"
+)
+
+// Problem is an error which is rendered as problem details by the error
+// middleware. The cause is only logged, it never reaches the client.
+type Problem struct {
+	Status int
+	Code   string
+	Detail string
+	Errors []models.FieldErrorDto
+	cause  error
+}
+
+func New(status int, code string, detail string) *Problem {
+	return &Problem{Status: status, Code: code, Detail: detail}
+}
+
+func BadRequest(code string, detail string) *Problem {
+	return New(http.StatusBadRequest, code, detail)
+}
+
+func NotFound(code string, detail string) *Problem {
+	return New(http.StatusNotFound, code, detail)
+}
+
+func Conflict(code string, detail string) *Problem {
+	return New(http.StatusConflict, code, detail)
+}
+
+// Validation reports the invalid fields of a request body.
+func Validation(detail string, errors []models.FieldErrorDto) *Problem {
+	p := New(http.StatusBadRequest, CodeValidationFailed, detail)
+	p.Errors = errors
+
+	return p
+}
+
+func Internal(detail string) *Problem {
+	return New(http.StatusInternalServerError, CodeInternal, detail)
+}
+
+// WithCause attaches the error which led to the problem.
+func (p *Problem) WithCause(err error) *Problem {
+	p.cause = err
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.cause == nil {
+		return p.Detail
+	}
+
+	return p.Detail + " " + p.cause.Error()
+}
+
+func (p *Problem) Unwrap() error {
+	return p.cause
+}
+
+// Dto renders the problem for the request it occurred in.
+func (p *Problem) Dto(instance string, requestId string) models.ProblemDto {
+	return models.ProblemDto{
+		Type:      "about:blank",
+		Title:     http.StatusText(p.Status),
+		Status:    p.Status,
+		Detail:    p.Detail,
+		Instance:  instance,
+		Code:      p.Code,
+		RequestID: requestId,
+		Errors:    p.Errors,
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+)
+
+// sweepInterval is how often the buckets of idle clients are dropped.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is the time at which the bucket is full again and can be dropped.
+	full time.Time
+}
+
+// MemoryStore keeps the buckets in the process. Every instance of the api
+// limits on its own, use the redis store when running several.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, rate config.Rate) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Requests), updated: now}
+		s.buckets[key] = b
+	}
+
+	tokens, allowed := take(b.tokens, now.Sub(b.updated), rate)
+	result := newResult(tokens, allowed, rate)
+	b.tokens = tokens
+	b.updated = now
+	b.full = now.Add(result.ResetAfter)
+
+	return result, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// SetClock replaces the clock used to refill the buckets, so tests do not have to sleep.
+func (s *MemoryStore) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.now = now
+}
+
+// Len returns the number of buckets which are kept.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.buckets)
+}
+
+// sweep drops the full buckets, a new bucket starts full anyway.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps a token bucket per key. A bucket holds up to Requests tokens
+// and is refilled with Requests tokens per Period, every request takes one.
+type Store interface {
+	Take(ctx context.Context, key string, rate config.Rate) (Result, error)
+	Close() error
+}
+
+// Result tells whether a request may pass and what is left of the bucket.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is the time until the next token, zero if one is left.
+	RetryAfter time.Duration
+	// ResetAfter is the time until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+var store Store
+
+// Setup creates the global store of the configured backend. With the backend
+// none no store is created and requests are not limited.
+func Setup(rateLimitConfig config.RateLimitConfig, redisURL string) error {
+	switch rateLimitConfig.Backend {
+	case config.RateLimitNone:
+		store = nil
+		log.Info("Rate limiting is disabled.")
+		return nil
+	case config.RateLimitMemory:
+		store = NewMemoryStore()
+	case config.RateLimitRedis:
+		options, err := redis.ParseURL(redisURL)
+		if err != nil {
+			return fmt.Errorf("invalid redis url: %w", err)
+		}
+		client := redis.NewClient(options)
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			client.Close()
+			return fmt.Errorf("could not connect to redis: %w", err)
+		}
+		store = NewRedisStore(client)
+	default:
+		return fmt.Errorf("unknown rate limit backend %q", rateLimitConfig.Backend)
+	}
+
+	log.Infof(`Limiting requests. Backend: <%s> Identify by: <%s>`, rateLimitConfig.Backend, rateLimitConfig.IdentifyBy)
+
+	return nil
+}
+
+func GetStore() Store {
+	return store
+}
+
+// Close releases the connection of the global store.
+func Close(ctx context.Context) error {
+	if store == nil {
+		return nil
+	}
+
+	return store.Close()
+}
+
+// take refills the bucket for the time since it was updated and takes a
+// token if there is one. Both stores use it, the redis script mirrors it.
+func take(tokens float64, elapsed time.Duration, rate config.Rate) (float64, bool) {
+	capacity := float64(rate.Requests)
+	tokens = math.Min(capacity, tokens+float64(max(elapsed, 0))*perNanosecond(rate))
+	if tokens < 1 {
+		return tokens, false
+	}
+
+	return tokens - 1, true
+}
+
+func newResult(tokens float64, allowed bool, rate config.Rate) Result {
+	result := Result{
+		Allowed:    allowed,
+		Limit:      rate.Requests,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration(math.Ceil((float64(rate.Requests) - tokens) / perNanosecond(rate))),
+	}
+	if tokens < 1 {
+		result.RetryAfter = time.Duration(math.Ceil((1 - tokens) / perNanosecond(rate)))
+	}
+
+	return result
+}
+
+func perNanosecond(rate config.Rate) float64 {
+	return float64(rate.Requests) / float64(rate.Period)
+}
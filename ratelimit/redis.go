@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "co2monitor:ratelimit:"
+
+// takeScript is take as redis script, so instances sharing a bucket cannot
+// take the same token. The bucket expires once it would be full again.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * capacity / period)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) * period / capacity) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore shares the buckets between all instances of the api. Any server
+// speaking the redis protocol and running scripts can be used.
+type RedisStore struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client, now: time.Now}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, rate config.Rate) (Result, error) {
+	reply, err := takeScript.Run(ctx, s.client, []string{redisKeyPrefix + key},
+		rate.Requests, rate.Period.Milliseconds(), s.now().UnixMilli()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected reply of the rate limit script: %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	value, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected tokens in the rate limit reply: %w", err)
+	}
+
+	return newResult(tokens, allowed == 1, rate), nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// SetClock replaces the clock used to refill the buckets, so tests do not have to sleep.
+func (s *RedisStore) SetClock(now func() time.Time) {
+	s.now = now
+}
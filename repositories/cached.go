@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/cache"
+	"github.com/fminister/co2monitor.api/metrics"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+// CachedCo2Repository reads the latest reading of a location through the
+// cache and keeps the cache up to date on ingest. A failing cache is logged
+// with the logger of the context and the database is used instead.
+type CachedCo2Repository struct {
+	Co2Repository
+	latest cache.LatestStore
+	ctx    context.Context
+}
+
+// CachedLocationRepository drops the cached latest reading of a deleted location.
+type CachedLocationRepository struct {
+	LocationRepository
+	latest cache.LatestStore
+	ctx    context.Context
+}
+
+func NewCachedCo2Repository(repository Co2Repository, latest cache.LatestStore) *CachedCo2Repository {
+	return &CachedCo2Repository{Co2Repository: repository, latest: latest, ctx: context.Background()}
+}
+
+func NewCachedLocationRepository(repository LocationRepository, latest cache.LatestStore) *CachedLocationRepository {
+	return &CachedLocationRepository{LocationRepository: repository, latest: latest, ctx: context.Background()}
+}
+
+// WithContext binds the queries and the cache lookups to the context.
+func (r *CachedCo2Repository) WithContext(ctx context.Context) Co2Repository {
+	return &CachedCo2Repository{Co2Repository: r.Co2Repository.WithContext(ctx), latest: r.latest, ctx: ctx}
+}
+
+func (r *CachedLocationRepository) WithContext(ctx context.Context) LocationRepository {
+	return &CachedLocationRepository{LocationRepository: r.LocationRepository.WithContext(ctx), latest: r.latest, ctx: ctx}
+}
+
+// GetLatest only caches the unfiltered latest reading, the one without
+// anomalies is read from the database.
+func (r *CachedCo2Repository) GetLatest(locationId string, excludeAnomalies bool) (models.Co2Data, error) {
+	id, err := parseId(locationId)
+	if excludeAnomalies || err != nil {
+		return r.Co2Repository.GetLatest(locationId, excludeAnomalies)
+	}
+
+	ctx := r.ctx
+	co2Data, ok, err := r.latest.Get(ctx, id)
+	switch {
+	case err != nil:
+		metrics.LatestCacheRequestsTotal.WithLabelValues("error").Inc()
+		log.FromContext(ctx).Error("Could not read the latest co2 data from the cache.", "location_id", locationId, "error", err)
+	case ok:
+		metrics.LatestCacheRequestsTotal.WithLabelValues("hit").Inc()
+		return co2Data, nil
+	default:
+		metrics.LatestCacheRequestsTotal.WithLabelValues("miss").Inc()
+	}
+
+	co2Data, err = r.Co2Repository.GetLatest(locationId, false)
+	if err != nil {
+		return co2Data, err
+	}
+
+	if err := r.latest.Set(ctx, co2Data); err != nil {
+		log.FromContext(ctx).Error("Could not cache the latest co2 data.", "location_id", locationId, "error", err)
+	}
+
+	return co2Data, nil
+}
+
+// Create replaces the cached readings which are older than the new ones.
+// Locations which are not cached yet are left alone, the new readings could
+// be backfilled and older than the ones in the database.
+func (r *CachedCo2Repository) Create(co2Data []models.Co2Data) ([]models.Co2Data, error) {
+	co2Data, err := r.Co2Repository.Create(co2Data)
+	if err != nil {
+		return co2Data, err
+	}
+
+	newest := map[int]models.Co2Data{}
+	for _, data := range co2Data {
+		if current, ok := newest[data.LocationID]; !ok || !data.CreatedAt.Before(current.CreatedAt) {
+			newest[data.LocationID] = data
+		}
+	}
+
+	ctx := r.ctx
+	for locationId, data := range newest {
+		cached, ok, err := r.latest.Get(ctx, uint(locationId))
+		if err != nil || !ok || data.CreatedAt.Before(cached.CreatedAt) {
+			continue
+		}
+		if err := r.latest.Set(ctx, data); err != nil {
+			log.FromContext(ctx).Error("Could not cache the latest co2 data.", "location_id", locationId, "error", err)
+		}
+	}
+
+	return co2Data, nil
+}
+
+func (r *CachedLocationRepository) Delete(location models.Location) error {
+	if err := r.LocationRepository.Delete(location); err != nil {
+		return err
+	}
+
+	if err := r.latest.Delete(r.ctx, location.ID); err != nil {
+		log.FromContext(r.ctx).Error("Could not remove the latest co2 data of a deleted location from the cache.", "location_id", location.ID, "error", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fminister/co2monitor.api/db/db_calls"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/glebarez/go-sqlite"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// The GORM repositories delegate to db_calls and translate their errors.
+
+type GormLocationRepository struct {
+	db *gorm.DB
+}
+
+type GormCo2Repository struct {
+	db *gorm.DB
+	// timescale reads aggregates from the TimescaleDB continuous aggregates.
+	timescale bool
+}
+
+type GormCalibrationRepository struct {
+	db *gorm.DB
+}
+
+type GormQuarantineRepository struct {
+	db *gorm.DB
+}
+
+func NewGormLocationRepository(db *gorm.DB) *GormLocationRepository {
+	return &GormLocationRepository{db: db}
+}
+
+func NewGormCo2Repository(db *gorm.DB, timescale bool) *GormCo2Repository {
+	return &GormCo2Repository{db: db, timescale: timescale}
+}
+
+func NewGormCalibrationRepository(db *gorm.DB) *GormCalibrationRepository {
+	return &GormCalibrationRepository{db: db}
+}
+
+func NewGormQuarantineRepository(db *gorm.DB) *GormQuarantineRepository {
+	return &GormQuarantineRepository{db: db}
+}
+
+func (r *GormLocationRepository) WithContext(ctx context.Context) LocationRepository {
+	return &GormLocationRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *GormLocationRepository) GetAll() ([]models.Location, error) {
+	locations, err := db_calls.GetLocation(r.db)
+	return locations, translate(err)
+}
+
+func (r *GormLocationRepository) Search(id string, name string) ([]models.Location, error) {
+	if _, err := parseId(id); err != nil {
+		id = ""
+	}
+
+	locations, err := db_calls.GetLocationBySearch(r.db, id, name)
+	return locations, translate(err)
+}
+
+func (r *GormLocationRepository) GetById(id string) (models.Location, error) {
+	// GORM reads a string which is no number as a raw where clause.
+	if _, err := parseId(id); err != nil {
+		return models.Location{}, ErrNotFound
+	}
+
+	location, err := db_calls.GetLocationById(r.db, id)
+	return location, translate(err)
+}
+
+func (r *GormLocationRepository) GetByIdsOrNames(ids []int, names []string) ([]models.Location, error) {
+	locations, err := db_calls.GetLocationsByIdsOrNames(r.db, ids, names)
+	return locations, translate(err)
+}
+
+func (r *GormLocationRepository) Create(locations []models.Location) ([]models.Location, error) {
+	locations, err := db_calls.CreateLocation(r.db, locations)
+	return locations, translate(err)
+}
+
+func (r *GormLocationRepository) Update(location models.Location) (models.Location, error) {
+	location, err := db_calls.UpdateLocation(r.db, location)
+	return location, translate(err)
+}
+
+func (r *GormLocationRepository) Delete(location models.Location) error {
+	return translate(db_calls.DeleteLocation(r.db, location))
+}
+
+func (r *GormCo2Repository) WithContext(ctx context.Context) Co2Repository {
+	return &GormCo2Repository{db: r.db.WithContext(ctx), timescale: r.timescale}
+}
+
+func (r *GormCo2Repository) GetByTimeFrame(locationId string, period time.Duration, excludeAnomalies bool) ([]models.Co2Data, error) {
+	co2Data, err := db_calls.GetCo2DataByTimeFrame(r.query(excludeAnomalies), locationId, period)
+	return co2Data, translate(err)
+}
+
+func (r *GormCo2Repository) GetByTimeRange(locationId string, from time.Time, to time.Time, excludeAnomalies bool) ([]models.Co2Data, error) {
+	co2Data, err := db_calls.GetCo2DataByTimeRange(r.query(excludeAnomalies), locationId, from, to)
+	return co2Data, translate(err)
+}
+
+func (r *GormCo2Repository) GetLatest(locationId string, excludeAnomalies bool) (models.Co2Data, error) {
+	co2Data, err := db_calls.GetLatestCo2Data(r.query(excludeAnomalies), locationId)
+	return co2Data, translate(err)
+}
+
+func (r *GormCo2Repository) GetLatestPerLocation() ([]models.Co2Data, error) {
+	co2Data, err := db_calls.GetLatestCo2DataPerLocation(r.db)
+	return co2Data, translate(err)
+}
+
+func (r *GormCo2Repository) GetSince(locationIds []int, since time.Time) ([]models.Co2Data, error) {
+	co2Data, err := db_calls.GetCo2DataSince(r.db, locationIds, since)
+	return co2Data, translate(err)
+}
+
+func (r *GormCo2Repository) GetAnomalies(locationId string, period time.Duration) ([]models.Co2Data, error) {
+	co2Data, err := db_calls.GetAnomalies(r.db, locationId, period)
+	return co2Data, translate(err)
+}
+
+func (r *GormCo2Repository) GetAggregates(locationId string, interval string, since time.Time, excludeAnomalies bool) ([]models.Co2Aggregate, error) {
+	aggregates, err := db_calls.GetCo2DataAggregates(r.query(excludeAnomalies), locationId, interval, since, r.timescale)
+	return aggregates, translate(err)
+}
+
+func (r *GormCo2Repository) Create(co2Data []models.Co2Data) ([]models.Co2Data, error) {
+	co2Data, err := db_calls.CreateCo2Data(r.db, co2Data)
+	return co2Data, translate(err)
+}
+
+func (r *GormCo2Repository) query(excludeAnomalies bool) *gorm.DB {
+	if excludeAnomalies {
+		return r.db.Scopes(db_calls.ExcludeAnomalies)
+	}
+
+	return r.db
+}
+
+func (r *GormCalibrationRepository) WithContext(ctx context.Context) CalibrationRepository {
+	return &GormCalibrationRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *GormCalibrationRepository) GetByLocation(locationId string) ([]models.Calibration, error) {
+	calibrations, err := db_calls.GetCalibrations(r.db, locationId)
+	return calibrations, translate(err)
+}
+
+func (r *GormCalibrationRepository) GetAll() ([]models.Calibration, error) {
+	calibrations, err := db_calls.GetAllCalibrations(r.db)
+	return calibrations, translate(err)
+}
+
+func (r *GormCalibrationRepository) GetById(id string) (models.Calibration, error) {
+	if _, err := parseId(id); err != nil {
+		return models.Calibration{}, ErrNotFound
+	}
+
+	calibration, err := db_calls.GetCalibrationById(r.db, id)
+	return calibration, translate(err)
+}
+
+func (r *GormCalibrationRepository) Create(calibrations []models.Calibration) ([]models.Calibration, error) {
+	calibrations, err := db_calls.CreateCalibration(r.db, calibrations)
+	return calibrations, translate(err)
+}
+
+func (r *GormCalibrationRepository) Delete(calibration models.Calibration) error {
+	return translate(db_calls.DeleteCalibration(r.db, calibration))
+}
+
+func (r *GormQuarantineRepository) WithContext(ctx context.Context) QuarantineRepository {
+	return &GormQuarantineRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *GormQuarantineRepository) GetAll() ([]models.QuarantinedCo2Data, error) {
+	quarantined, err := db_calls.GetQuarantinedCo2Data(r.db)
+	return quarantined, translate(err)
+}
+
+func (r *GormQuarantineRepository) GetById(id string) (models.QuarantinedCo2Data, error) {
+	if _, err := parseId(id); err != nil {
+		return models.QuarantinedCo2Data{}, ErrNotFound
+	}
+
+	quarantined, err := db_calls.GetQuarantinedCo2DataById(r.db, id)
+	return quarantined, translate(err)
+}
+
+func (r *GormQuarantineRepository) Create(quarantined []models.QuarantinedCo2Data) ([]models.QuarantinedCo2Data, error) {
+	quarantined, err := db_calls.CreateQuarantinedCo2Data(r.db, quarantined)
+	return quarantined, translate(err)
+}
+
+func (r *GormQuarantineRepository) Delete(quarantined models.QuarantinedCo2Data) error {
+	return translate(db_calls.DeleteQuarantinedCo2Data(r.db, quarantined))
+}
+
+func (r *GormQuarantineRepository) Restore(quarantined models.QuarantinedCo2Data) error {
+	return translate(db_calls.RestoreQuarantinedCo2Data(r.db, quarantined))
+}
+
+// translate maps the errors of GORM and the drivers to the repository
+// errors. Both drivers translate unique violations when TranslateError is
+// set, foreign key violations are detected here.
+func translate(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	case errors.Is(err, gorm.ErrForeignKeyViolated) || isForeignKeyViolation(err):
+		return fmt.Errorf("%w: %v", ErrInvalidReference, err)
+	}
+
+	return err
+}
+
+// pgForeignKeyViolation is the SQLSTATE of a foreign key violation.
+const pgForeignKeyViolation = "23503"
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgForeignKeyViolation
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+	}
+
+	return false
+}
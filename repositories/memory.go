@@ -0,0 +1,577 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+// MemoryStore keeps locations, co2 data and calibrations in memory. It
+// behaves like the database for the repositories and is meant for tests and
+// for trying out the api without a database.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	lastId       uint
+	locations    []models.Location
+	co2Data      []models.Co2Data
+	calibrations []models.Calibration
+	quarantined  []models.QuarantinedCo2Data
+}
+
+type MemoryLocationRepository struct {
+	store *MemoryStore
+}
+
+type MemoryCo2Repository struct {
+	store *MemoryStore
+}
+
+type MemoryCalibrationRepository struct {
+	store *MemoryStore
+}
+
+type MemoryQuarantineRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Locations() *MemoryLocationRepository {
+	return &MemoryLocationRepository{store: s}
+}
+
+func (s *MemoryStore) Co2Data() *MemoryCo2Repository {
+	return &MemoryCo2Repository{store: s}
+}
+
+func (s *MemoryStore) Calibrations() *MemoryCalibrationRepository {
+	return &MemoryCalibrationRepository{store: s}
+}
+
+func (s *MemoryStore) Quarantine() *MemoryQuarantineRepository {
+	return &MemoryQuarantineRepository{store: s}
+}
+
+// WithContext returns the repository itself, the memory store has no
+// queries to bind.
+func (r *MemoryLocationRepository) WithContext(ctx context.Context) LocationRepository {
+	return r
+}
+
+func (r *MemoryLocationRepository) GetAll() ([]models.Location, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]models.Location{}, r.store.locations...), nil
+}
+
+func (r *MemoryLocationRepository) Search(id string, name string) ([]models.Location, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	parsedId, _ := parseId(id)
+	locations := []models.Location{}
+	for _, location := range r.store.locations {
+		if (parsedId != 0 && location.ID == parsedId) || location.Name == name {
+			locations = append(locations, location)
+		}
+	}
+
+	return locations, nil
+}
+
+func (r *MemoryLocationRepository) GetById(id string) (models.Location, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	parsedId, err := parseId(id)
+	if err != nil {
+		return models.Location{}, ErrNotFound
+	}
+
+	index := r.store.locationIndex(parsedId)
+	if index < 0 {
+		return models.Location{}, ErrNotFound
+	}
+
+	return r.store.locations[index], nil
+}
+
+func (r *MemoryLocationRepository) GetByIdsOrNames(ids []int, names []string) ([]models.Location, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := map[uint]bool{}
+	for _, id := range ids {
+		wanted[uint(id)] = true
+	}
+	wantedNames := map[string]bool{}
+	for _, name := range names {
+		wantedNames[name] = true
+	}
+
+	locations := []models.Location{}
+	for _, location := range r.store.locations {
+		if wanted[location.ID] || wantedNames[location.Name] {
+			locations = append(locations, location)
+		}
+	}
+
+	return locations, nil
+}
+
+func (r *MemoryLocationRepository) Create(locations []models.Location) ([]models.Location, error) {
+	if len(locations) == 0 {
+		return locations, errors.New("Empty list of locations to insert")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	names := map[string]bool{}
+	for _, location := range locations {
+		if names[location.Name] || r.store.nameTaken(location.Name, 0) {
+			return locations, fmt.Errorf("%w: location name %q already exists", ErrDuplicate, location.Name)
+		}
+		names[location.Name] = true
+	}
+
+	now := time.Now()
+	for i := range locations {
+		locations[i].ID = r.store.nextId()
+		locations[i].CreatedAt = defaultTime(locations[i].CreatedAt, now)
+		locations[i].UpdatedAt = defaultTime(locations[i].UpdatedAt, now)
+		r.store.locations = append(r.store.locations, locations[i])
+	}
+
+	return locations, nil
+}
+
+func (r *MemoryLocationRepository) Update(location models.Location) (models.Location, error) {
+	if location.ID == 0 {
+		created, err := r.Create([]models.Location{location})
+		if err != nil {
+			return location, err
+		}
+		return created[0], nil
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.nameTaken(location.Name, location.ID) {
+		return location, fmt.Errorf("%w: location name %q already exists", ErrDuplicate, location.Name)
+	}
+
+	location.UpdatedAt = time.Now()
+	index := r.store.locationIndex(location.ID)
+	if index < 0 {
+		location.CreatedAt = defaultTime(location.CreatedAt, location.UpdatedAt)
+		r.store.locations = append(r.store.locations, location)
+		return location, nil
+	}
+
+	location.CreatedAt = defaultTime(location.CreatedAt, r.store.locations[index].CreatedAt)
+	r.store.locations[index] = location
+
+	return location, nil
+}
+
+func (r *MemoryLocationRepository) Delete(location models.Location) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	index := r.store.locationIndex(location.ID)
+	if index >= 0 {
+		r.store.locations = append(r.store.locations[:index], r.store.locations[index+1:]...)
+	}
+
+	return nil
+}
+
+func (r *MemoryCo2Repository) WithContext(ctx context.Context) Co2Repository {
+	return r
+}
+
+func (r *MemoryCo2Repository) GetByTimeFrame(locationId string, period time.Duration, excludeAnomalies bool) ([]models.Co2Data, error) {
+	since := time.Now().Add(-period)
+	return r.filter(func(data models.Co2Data) bool {
+		return idEquals(locationId, data.LocationID) && data.CreatedAt.After(since) && (!excludeAnomalies || data.Quality == models.QualityOk)
+	}), nil
+}
+
+func (r *MemoryCo2Repository) GetByTimeRange(locationId string, from time.Time, to time.Time, excludeAnomalies bool) ([]models.Co2Data, error) {
+	return r.filter(func(data models.Co2Data) bool {
+		return idEquals(locationId, data.LocationID) && data.CreatedAt.After(from) && !data.CreatedAt.After(to) && (!excludeAnomalies || data.Quality == models.QualityOk)
+	}), nil
+}
+
+func (r *MemoryCo2Repository) GetLatest(locationId string, excludeAnomalies bool) (models.Co2Data, error) {
+	co2Data := r.filter(func(data models.Co2Data) bool {
+		return idEquals(locationId, data.LocationID) && (!excludeAnomalies || data.Quality == models.QualityOk)
+	})
+	if len(co2Data) == 0 {
+		return models.Co2Data{}, ErrNotFound
+	}
+
+	return newest(co2Data), nil
+}
+
+func (r *MemoryCo2Repository) GetLatestPerLocation() ([]models.Co2Data, error) {
+	byLocation := map[int][]models.Co2Data{}
+	for _, data := range r.filter(func(models.Co2Data) bool { return true }) {
+		byLocation[data.LocationID] = append(byLocation[data.LocationID], data)
+	}
+
+	latest := []models.Co2Data{}
+	for _, co2Data := range byLocation {
+		latest = append(latest, newest(co2Data))
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].LocationID < latest[j].LocationID
+	})
+
+	return latest, nil
+}
+
+func (r *MemoryCo2Repository) GetSince(locationIds []int, since time.Time) ([]models.Co2Data, error) {
+	ids := map[int]bool{}
+	for _, id := range locationIds {
+		ids[id] = true
+	}
+
+	return r.filter(func(data models.Co2Data) bool {
+		return ids[data.LocationID] && data.CreatedAt.After(since)
+	}), nil
+}
+
+func (r *MemoryCo2Repository) GetAnomalies(locationId string, period time.Duration) ([]models.Co2Data, error) {
+	since := time.Now().Add(-period)
+	co2Data := r.filter(func(data models.Co2Data) bool {
+		return idEquals(locationId, data.LocationID) && data.CreatedAt.After(since) && data.Quality != models.QualityOk
+	})
+	sort.SliceStable(co2Data, func(i, j int) bool {
+		return co2Data[i].CreatedAt.After(co2Data[j].CreatedAt)
+	})
+
+	return co2Data, nil
+}
+
+func (r *MemoryCo2Repository) GetAggregates(locationId string, interval string, since time.Time, excludeAnomalies bool) ([]models.Co2Aggregate, error) {
+	var bucketSize time.Duration
+	switch interval {
+	case models.AggregateHour:
+		bucketSize = time.Hour
+	case models.AggregateDay:
+		bucketSize = 24 * time.Hour
+	default:
+		return nil, fmt.Errorf("unknown aggregate interval %q", interval)
+	}
+	since = since.UTC().Truncate(bucketSize)
+
+	co2Data := r.filter(func(data models.Co2Data) bool {
+		return idEquals(locationId, data.LocationID) && !data.CreatedAt.Before(since) && (!excludeAnomalies || data.Quality == models.QualityOk)
+	})
+
+	byBucket := map[time.Time]*models.Co2Aggregate{}
+	for _, data := range co2Data {
+		bucket := data.CreatedAt.UTC().Truncate(bucketSize)
+		aggregate, ok := byBucket[bucket]
+		if !ok {
+			aggregate = &models.Co2Aggregate{LocationID: data.LocationID, Bucket: bucket, MinCO2: float64(data.CO2), MaxCO2: float64(data.CO2)}
+			byBucket[bucket] = aggregate
+		}
+		aggregate.AvgCO2 += float64(data.CO2)
+		aggregate.AvgTemp += float64(data.Temp)
+		aggregate.MinCO2 = min(aggregate.MinCO2, float64(data.CO2))
+		aggregate.MaxCO2 = max(aggregate.MaxCO2, float64(data.CO2))
+		aggregate.Count++
+	}
+
+	aggregates := make([]models.Co2Aggregate, 0, len(byBucket))
+	for _, aggregate := range byBucket {
+		aggregate.AvgCO2 /= float64(aggregate.Count)
+		aggregate.AvgTemp /= float64(aggregate.Count)
+		aggregates = append(aggregates, *aggregate)
+	}
+	sort.Slice(aggregates, func(i, j int) bool {
+		return aggregates[i].Bucket.Before(aggregates[j].Bucket)
+	})
+
+	return aggregates, nil
+}
+
+func (r *MemoryCo2Repository) Create(co2Data []models.Co2Data) ([]models.Co2Data, error) {
+	if len(co2Data) == 0 {
+		return co2Data, errors.New("Empty list of co2 data to insert")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, data := range co2Data {
+		if r.store.locationIndex(uint(data.LocationID)) < 0 {
+			return co2Data, fmt.Errorf("%w: location %d of co2 data does not exist", ErrInvalidReference, data.LocationID)
+		}
+	}
+
+	now := time.Now()
+	for i := range co2Data {
+		co2Data[i].ID = r.store.nextId()
+		co2Data[i].CreatedAt = defaultTime(co2Data[i].CreatedAt, now)
+		co2Data[i].UpdatedAt = defaultTime(co2Data[i].UpdatedAt, now)
+		if co2Data[i].Quality == "" {
+			co2Data[i].Quality = models.QualityOk
+		}
+		r.store.co2Data = append(r.store.co2Data, co2Data[i])
+	}
+
+	return co2Data, nil
+}
+
+func (r *MemoryCo2Repository) filter(keep func(data models.Co2Data) bool) []models.Co2Data {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	co2Data := []models.Co2Data{}
+	for _, data := range r.store.co2Data {
+		if keep(data) {
+			co2Data = append(co2Data, data)
+		}
+	}
+
+	return co2Data
+}
+
+func (r *MemoryCalibrationRepository) WithContext(ctx context.Context) CalibrationRepository {
+	return r
+}
+
+func (r *MemoryCalibrationRepository) GetByLocation(locationId string) ([]models.Calibration, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	calibrations := []models.Calibration{}
+	for _, calibration := range r.store.calibrations {
+		if idEquals(locationId, calibration.LocationID) {
+			calibrations = append(calibrations, calibration)
+		}
+	}
+	sort.SliceStable(calibrations, func(i, j int) bool {
+		return calibrations[i].ValidFrom.After(calibrations[j].ValidFrom)
+	})
+
+	return calibrations, nil
+}
+
+func (r *MemoryCalibrationRepository) GetAll() ([]models.Calibration, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]models.Calibration{}, r.store.calibrations...), nil
+}
+
+func (r *MemoryCalibrationRepository) GetById(id string) (models.Calibration, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	parsedId, err := parseId(id)
+	if err != nil {
+		return models.Calibration{}, ErrNotFound
+	}
+
+	for _, calibration := range r.store.calibrations {
+		if calibration.ID == parsedId {
+			return calibration, nil
+		}
+	}
+
+	return models.Calibration{}, ErrNotFound
+}
+
+func (r *MemoryCalibrationRepository) Create(calibrations []models.Calibration) ([]models.Calibration, error) {
+	if len(calibrations) == 0 {
+		return calibrations, errors.New("Empty list of calibrations to insert")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, calibration := range calibrations {
+		if r.store.locationIndex(uint(calibration.LocationID)) < 0 {
+			return calibrations, fmt.Errorf("%w: location %d of calibration does not exist", ErrInvalidReference, calibration.LocationID)
+		}
+	}
+
+	now := time.Now()
+	for i := range calibrations {
+		calibrations[i].ID = r.store.nextId()
+		calibrations[i].CreatedAt = defaultTime(calibrations[i].CreatedAt, now)
+		calibrations[i].UpdatedAt = defaultTime(calibrations[i].UpdatedAt, now)
+		r.store.calibrations = append(r.store.calibrations, calibrations[i])
+	}
+
+	return calibrations, nil
+}
+
+func (r *MemoryCalibrationRepository) Delete(calibration models.Calibration) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range r.store.calibrations {
+		if r.store.calibrations[i].ID == calibration.ID {
+			r.store.calibrations = append(r.store.calibrations[:i], r.store.calibrations[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryQuarantineRepository) WithContext(ctx context.Context) QuarantineRepository {
+	return r
+}
+
+func (r *MemoryQuarantineRepository) GetAll() ([]models.QuarantinedCo2Data, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	quarantined := make([]models.QuarantinedCo2Data, 0, len(r.store.quarantined))
+	for i := len(r.store.quarantined) - 1; i >= 0; i-- {
+		quarantined = append(quarantined, r.store.quarantined[i])
+	}
+
+	return quarantined, nil
+}
+
+func (r *MemoryQuarantineRepository) GetById(id string) (models.QuarantinedCo2Data, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	parsedId, err := parseId(id)
+	if err != nil {
+		return models.QuarantinedCo2Data{}, ErrNotFound
+	}
+
+	for _, quarantined := range r.store.quarantined {
+		if quarantined.ID == parsedId {
+			return quarantined, nil
+		}
+	}
+
+	return models.QuarantinedCo2Data{}, ErrNotFound
+}
+
+func (r *MemoryQuarantineRepository) Create(quarantined []models.QuarantinedCo2Data) ([]models.QuarantinedCo2Data, error) {
+	if len(quarantined) == 0 {
+		return quarantined, errors.New("Empty list of quarantined co2 data to insert")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for i := range quarantined {
+		quarantined[i].ID = r.store.nextId()
+		quarantined[i].CreatedAt = defaultTime(quarantined[i].CreatedAt, now)
+		quarantined[i].UpdatedAt = defaultTime(quarantined[i].UpdatedAt, now)
+		r.store.quarantined = append(r.store.quarantined, quarantined[i])
+	}
+
+	return quarantined, nil
+}
+
+func (r *MemoryQuarantineRepository) Delete(quarantined models.QuarantinedCo2Data) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range r.store.quarantined {
+		if r.store.quarantined[i].ID == quarantined.ID {
+			r.store.quarantined = append(r.store.quarantined[:i], r.store.quarantined[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+// Restore puts the entry back at its place by id, so GetAll keeps returning
+// the newest first.
+func (r *MemoryQuarantineRepository) Restore(quarantined models.QuarantinedCo2Data) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i := 0
+	for i < len(r.store.quarantined) && r.store.quarantined[i].ID < quarantined.ID {
+		i++
+	}
+	if i < len(r.store.quarantined) && r.store.quarantined[i].ID == quarantined.ID {
+		return nil
+	}
+	r.store.quarantined = append(r.store.quarantined[:i], append([]models.QuarantinedCo2Data{quarantined}, r.store.quarantined[i:]...)...)
+
+	return nil
+}
+
+// nextId has to be called with the lock held.
+func (s *MemoryStore) nextId() uint {
+	s.lastId++
+	return s.lastId
+}
+
+func (s *MemoryStore) locationIndex(id uint) int {
+	for i, location := range s.locations {
+		if location.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *MemoryStore) nameTaken(name string, exceptId uint) bool {
+	for _, location := range s.locations {
+		if location.Name == name && location.ID != exceptId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newest(co2Data []models.Co2Data) models.Co2Data {
+	latest := co2Data[0]
+	for _, data := range co2Data[1:] {
+		if data.CreatedAt.After(latest.CreatedAt) {
+			latest = data
+		}
+	}
+
+	return latest
+}
+
+func parseId(id string) (uint, error) {
+	parsed, err := strconv.ParseUint(id, 10, 64)
+	return uint(parsed), err
+}
+
+func idEquals(id string, value int) bool {
+	parsed, err := parseId(id)
+	return err == nil && parsed == uint(value)
+}
+
+func defaultTime(value time.Time, fallback time.Time) time.Time {
+	if value.IsZero() {
+		return fallback
+	}
+
+	return value
+}
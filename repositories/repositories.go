@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested entity does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when an entity violates a unique constraint, e.g. the location name.
+	ErrDuplicate = errors.New("duplicate entity")
+	// ErrInvalidReference is returned when an entity references a location which does not exist.
+	ErrInvalidReference = errors.New("referenced entity does not exist")
+)
+
+type LocationRepository interface {
+	// WithContext returns the repository with its queries bound to the context, e.g. of a request.
+	WithContext(ctx context.Context) LocationRepository
+	GetAll() ([]models.Location, error)
+	// Search returns the locations which have the id or the name.
+	Search(id string, name string) ([]models.Location, error)
+	GetById(id string) (models.Location, error)
+	// GetByIdsOrNames returns the locations which have one of the ids or one
+	// of the names. Deleted locations are left out.
+	GetByIdsOrNames(ids []int, names []string) ([]models.Location, error)
+	Create(locations []models.Location) ([]models.Location, error)
+	Update(location models.Location) (models.Location, error)
+	Delete(location models.Location) error
+}
+
+type Co2Repository interface {
+	WithContext(ctx context.Context) Co2Repository
+	// GetByTimeFrame returns the co2 data of the location measured within the last period in insertion order.
+	GetByTimeFrame(locationId string, period time.Duration, excludeAnomalies bool) ([]models.Co2Data, error)
+	// GetByTimeRange returns the co2 data of the location measured after from and up to and including to in insertion order.
+	GetByTimeRange(locationId string, from time.Time, to time.Time, excludeAnomalies bool) ([]models.Co2Data, error)
+	GetLatest(locationId string, excludeAnomalies bool) (models.Co2Data, error)
+	// GetLatestPerLocation returns the newest reading of every location which has reported at least once.
+	GetLatestPerLocation() ([]models.Co2Data, error)
+	GetSince(locationIds []int, since time.Time) ([]models.Co2Data, error)
+	// GetAnomalies returns the flagged co2 data of the location measured within the last period, newest first.
+	GetAnomalies(locationId string, period time.Duration) ([]models.Co2Data, error)
+	// GetAggregates returns the hourly or daily aggregates since the start of the bucket containing since.
+	GetAggregates(locationId string, interval string, since time.Time, excludeAnomalies bool) ([]models.Co2Aggregate, error)
+	Create(co2Data []models.Co2Data) ([]models.Co2Data, error)
+}
+
+// QuarantineRepository keeps the posted co2 data which was rejected or held back.
+type QuarantineRepository interface {
+	WithContext(ctx context.Context) QuarantineRepository
+	// GetAll returns the quarantined co2 data, the newest first.
+	GetAll() ([]models.QuarantinedCo2Data, error)
+	GetById(id string) (models.QuarantinedCo2Data, error)
+	Create(quarantined []models.QuarantinedCo2Data) ([]models.QuarantinedCo2Data, error)
+	// Delete returns ErrNotFound if the entry is already gone, so only one
+	// of concurrent replays succeeds.
+	Delete(quarantined models.QuarantinedCo2Data) error
+	// Restore brings a deleted entry back, e.g. if its replay failed.
+	Restore(quarantined models.QuarantinedCo2Data) error
+}
+
+type CalibrationRepository interface {
+	WithContext(ctx context.Context) CalibrationRepository
+	// GetByLocation returns the calibrations of the location, the latest valid_from first.
+	GetByLocation(locationId string) ([]models.Calibration, error)
+	GetAll() ([]models.Calibration, error)
+	GetById(id string) (models.Calibration, error)
+	Create(calibrations []models.Calibration) ([]models.Calibration, error)
+	Delete(calibration models.Calibration) error
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func calibrationRoutes(superRoute *gin.RouterGroup) {
+	controllers := newAPIEnv()
+
+	calibrationRouter := superRoute.Group("/calibration")
+	calibrationRouter.Use(rateLimit("calibration", config.Get().RateLimit.Calibration), middleware.RequireApiKey)
+	{
+		calibrationRouter.POST("/new", controllers.CreateCalibration)
+		calibrationRouter.DELETE("/:id", controllers.DeleteCalibration)
+	}
+}
@@ -1,21 +1,21 @@
 package routes
 
 import (
-	"github.com/fminister/co2monitor.api/controllers"
-	"github.com/fminister/co2monitor.api/db"
+	"github.com/fminister/co2monitor.api/config"
 	"github.com/fminister/co2monitor.api/middleware"
 	"github.com/gin-gonic/gin"
 )
 
 func co2DataRoutes(superRoute *gin.RouterGroup) {
-	controllers := &controllers.APIEnv{
-		DB: db.GetDB(),
-	}
+	controllers := newAPIEnv()
 	co2DataRouter := superRoute.Group("/co2data")
-	co2DataRouter.Use(middleware.RequireApiKey)
+	co2DataRouter.Use(rateLimit("co2data", config.Get().RateLimit.Co2Data), middleware.RequireApiKey)
 	{
 		co2DataRouter.GET("/:id/search", controllers.GetCo2DataByTimeFrame)
 		co2DataRouter.GET("/:id/latest", controllers.GetLatestCo2Data)
+		co2DataRouter.GET("/:id/forecast", controllers.GetCo2DataForecast)
+		co2DataRouter.GET("/:id/anomalies", controllers.GetCo2DataAnomalies)
+		co2DataRouter.GET("/:id/aggregate", controllers.GetCo2DataAggregates)
 		co2DataRouter.POST("/new", controllers.CreateCo2Data)
 	}
 
@@ -0,0 +1,12 @@
+package routes
+
+import "github.com/gin-gonic/gin"
+
+// AddHealthRoutes registers the probes on the root router, they are neither
+// part of /api nor protected by an api key.
+func AddHealthRoutes(app *gin.Engine) {
+	controllers := newAPIEnv()
+
+	app.GET("/healthz", controllers.GetLiveness)
+	app.GET("/readyz", controllers.GetReadiness)
+}
@@ -1,8 +1,49 @@
 package routes
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/fminister/co2monitor.api/cache"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/controllers"
+	"github.com/fminister/co2monitor.api/db"
+	"github.com/fminister/co2monitor.api/health"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/ratelimit"
+	"github.com/fminister/co2monitor.api/repositories"
+	"github.com/fminister/co2monitor.api/workers"
+	"github.com/gin-gonic/gin"
+)
 
 func AddRoutes(superRoute *gin.RouterGroup) {
 	co2DataRoutes(superRoute)
 	locationRoutes(superRoute)
+	calibrationRoutes(superRoute)
+	quarantineRoutes(superRoute)
+}
+
+// newAPIEnv wires the controllers to the GORM repositories of the connected
+// database, the latest readings are read through the cache if one is set up.
+func newAPIEnv() *controllers.APIEnv {
+	gormDb := db.GetDB()
+	heartbeat := workers.GetHeartbeatWatcher()
+
+	var locations repositories.LocationRepository = repositories.NewGormLocationRepository(gormDb)
+	var co2Data repositories.Co2Repository = repositories.NewGormCo2Repository(gormDb, db.UsesTimescale())
+	if latest := cache.GetLatestStore(); latest != nil {
+		locations = repositories.NewCachedLocationRepository(locations, latest)
+		co2Data = repositories.NewCachedCo2Repository(co2Data, latest)
+	}
+
+	return &controllers.APIEnv{
+		Locations:    locations,
+		Co2Data:      co2Data,
+		Calibrations: repositories.NewGormCalibrationRepository(gormDb),
+		Quarantine:   repositories.NewGormQuarantineRepository(gormDb),
+		Heartbeat:    heartbeat,
+		Health:       &health.Checker{DB: gormDb, Heartbeat: heartbeat},
+	}
+}
+
+// rateLimit limits the requests to a route group with the global store.
+func rateLimit(group string, rate config.Rate) gin.HandlerFunc {
+	return middleware.RateLimit(ratelimit.GetStore(), group, rate, config.Get().RateLimit.IdentifyBy)
 }
@@ -1,22 +1,22 @@
 package routes
 
 import (
-	"github.com/fminister/co2monitor.api/controllers"
-	"github.com/fminister/co2monitor.api/db"
+	"github.com/fminister/co2monitor.api/config"
 	"github.com/fminister/co2monitor.api/middleware"
 	"github.com/gin-gonic/gin"
 )
 
 func locationRoutes(superRoute *gin.RouterGroup) {
-	controllers := &controllers.APIEnv{
-		DB: db.GetDB(),
-	}
+	controllers := newAPIEnv()
 
 	locationRouter := superRoute.Group("/location")
-	locationRouter.Use(middleware.RequireApiKey)
+	locationRouter.Use(rateLimit("location", config.Get().RateLimit.Location), middleware.RequireApiKey)
 	{
 		locationRouter.GET("/", controllers.GetLocations)
 		locationRouter.GET("/search", controllers.GetLocationBySearch)
+		locationRouter.GET("/status", controllers.GetLocationStatus)
+		locationRouter.GET("/:id/ventilation", controllers.GetVentilationRecommendation)
+		locationRouter.GET("/:id/calibrations", controllers.GetCalibrations)
 		locationRouter.POST("/new", controllers.CreateLocation)
 		locationRouter.PATCH("/:id", controllers.UpdateLocation)
 		locationRouter.DELETE("/:id", controllers.DeleteLocation)
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func quarantineRoutes(superRoute *gin.RouterGroup) {
+	controllers := newAPIEnv()
+
+	quarantineRouter := superRoute.Group("/quarantine")
+	quarantineRouter.Use(rateLimit("quarantine", config.Get().RateLimit.Quarantine), middleware.RequireAdminApiKey)
+	{
+		quarantineRouter.GET("/", controllers.GetQuarantinedCo2Data)
+		quarantineRouter.GET("/:id", controllers.GetQuarantinedCo2DataById)
+		quarantineRouter.POST("/:id/replay", controllers.ReplayQuarantinedCo2Data)
+		quarantineRouter.DELETE("/:id", controllers.DiscardQuarantinedCo2Data)
+	}
+}
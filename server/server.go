@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+)
+
+// StopFunc stops a component after the server stopped accepting requests.
+type StopFunc func(ctx context.Context) error
+
+func New(handler http.Handler, serverConfig config.ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              serverConfig.Addr,
+		Handler:           handler,
+		ReadTimeout:       serverConfig.ReadTimeout,
+		ReadHeaderTimeout: serverConfig.ReadHeaderTimeout,
+		WriteTimeout:      serverConfig.WriteTimeout,
+		IdleTimeout:       serverConfig.IdleTimeout,
+		MaxHeaderBytes:    serverConfig.MaxHeaderBytes,
+	}
+}
+
+// Run listens on the configured address and serves until the context is done.
+func Run(ctx context.Context, srv *http.Server, serverConfig config.ServerConfig, stops ...StopFunc) error {
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	return Serve(ctx, srv, listener, serverConfig, stops...)
+}
+
+// Serve serves on the listener until the context is done. Then it stops
+// accepting connections, waits for in-flight requests to finish and calls
+// the stop functions in the given order, all within the shutdown timeout.
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, serverConfig config.ServerConfig, stops ...StopFunc) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Infof(`Listening on <%s>. TLS: <%t>`, listener.Addr(), serverConfig.TLS())
+		if serverConfig.TLS() {
+			serveErr <- srv.ServeTLS(listener, serverConfig.TLSCertFile, serverConfig.TLSKeyFile)
+		} else {
+			serveErr <- srv.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down, draining in-flight requests.")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverConfig.ShutdownTimeout)
+	defer cancel()
+
+	errs := []error{}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("could not drain requests: %w", err))
+	}
+
+	for _, stop := range stops {
+		if err := stop(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
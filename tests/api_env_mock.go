@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"github.com/fminister/co2monitor.api/controllers"
+	"github.com/fminister/co2monitor.api/health"
+	"github.com/fminister/co2monitor.api/repositories"
+	"gorm.io/gorm"
+)
+
+// NewAPIEnv wires the controllers to the GORM repositories of the test database.
+func NewAPIEnv(db *gorm.DB) *controllers.APIEnv {
+	return &controllers.APIEnv{
+		Locations:    repositories.NewGormLocationRepository(db),
+		Co2Data:      repositories.NewGormCo2Repository(db, false),
+		Calibrations: repositories.NewGormCalibrationRepository(db),
+		Quarantine:   repositories.NewGormQuarantineRepository(db),
+		Health:       &health.Checker{DB: db},
+	}
+}
+
+// NewMemoryAPIEnv wires the controllers to an in-memory store, which is
+// enough for tests that do not care about the database.
+func NewMemoryAPIEnv(store *repositories.MemoryStore) *controllers.APIEnv {
+	return &controllers.APIEnv{
+		Locations:    store.Locations(),
+		Co2Data:      store.Co2Data(),
+		Calibrations: store.Calibrations(),
+		Quarantine:   store.Quarantine(),
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fminister/co2monitor.api/cache"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newRedisStore(t *testing.T, ttl time.Duration) (*cache.RedisLatestStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	store := cache.NewRedisLatestStore(redis.NewClient(&redis.Options{Addr: server.Addr()}), ttl)
+	t.Cleanup(func() { store.Close() })
+
+	return store, server
+}
+
+func TestLatestStores_ShouldSetGetAndDelete(t *testing.T) {
+	redisStore, _ := newRedisStore(t, time.Minute)
+	stores := map[string]cache.LatestStore{
+		"memory": cache.NewMemoryLatestStore(time.Minute),
+		"redis":  redisStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			reading := models.Co2Data{Model: gorm.Model{ID: 7, CreatedAt: time.Now().UTC().Truncate(time.Second)}, CO2: 800, Temp: 21.5, LocationID: 3, Quality: models.QualityOk}
+
+			_, ok, err := store.Get(ctx, 3)
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			require.NoError(t, store.Set(ctx, reading))
+			cached, ok, err := store.Get(ctx, 3)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, reading.ID, cached.ID)
+			assert.Equal(t, reading.CO2, cached.CO2)
+			assert.Equal(t, reading.Temp, cached.Temp)
+			assert.True(t, reading.CreatedAt.Equal(cached.CreatedAt))
+
+			require.NoError(t, store.Delete(ctx, 3))
+			_, ok, err = store.Get(ctx, 3)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestMemoryLatestStore_ShouldExpireEntries(t *testing.T) {
+	now := time.Now()
+	store := cache.NewMemoryLatestStore(time.Minute)
+	store.SetClock(func() time.Time { return now })
+	require.NoError(t, store.Set(context.Background(), models.Co2Data{CO2: 800, LocationID: 1}))
+
+	now = now.Add(59 * time.Second)
+	_, ok, _ := store.Get(context.Background(), 1)
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok, _ = store.Get(context.Background(), 1)
+	assert.False(t, ok)
+}
+
+func TestRedisLatestStore_ShouldExpireEntries(t *testing.T) {
+	store, server := newRedisStore(t, time.Minute)
+	require.NoError(t, store.Set(context.Background(), models.Co2Data{CO2: 800, LocationID: 1}))
+
+	server.FastForward(time.Minute)
+	_, ok, err := store.Get(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRedisLatestStore_ShouldReturnErrorServerDown(t *testing.T) {
+	store, server := newRedisStore(t, time.Minute)
+	server.Close()
+
+	_, _, err := store.Get(context.Background(), 1)
+
+	assert.Error(t, err)
+}
+
+func TestLatestStores_ShouldRejectReadingWithoutLocation(t *testing.T) {
+	store := cache.NewMemoryLatestStore(0)
+
+	assert.Error(t, store.Set(context.Background(), models.Co2Data{CO2: 800}))
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCalibration_ShouldCreateCalibration(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	// add dummy data or creating calibrations will fail because of foreign key constraint
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	requestBody, _ := json.Marshal([]models.CalibrationPostDto{
+		{
+			LocationID: 1,
+			Co2Offset:  -40,
+			Co2Scale:   1.05,
+			ValidFrom:  time.Now().Add(-time.Hour),
+		},
+	})
+	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateCalibration, requestBody)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := []models.CalibrationDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+	expectedInDb := []models.Calibration{}
+	f.Db.Find(&expectedInDb)
+
+	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusCreated, writer.Code, "HTTP request status code error")
+	assert.Equal(t, 1, len(responseData))
+	assert.Equal(t, 1, len(expectedInDb))
+	assert.Equal(t, float32(-40), expectedInDb[0].Co2Offset)
+	assert.Equal(t, float32(1.05), expectedInDb[0].Co2Scale)
+	assert.Equal(t, float32(1), expectedInDb[0].TempScale)
+}
+
+func TestCreateCalibration_ShouldReturnErrorEndBeforeStart(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	validTo := time.Now().Add(-2 * time.Hour)
+	requestBody, _ := json.Marshal([]models.CalibrationPostDto{
+		{
+			LocationID: 1,
+			Co2Offset:  -40,
+			ValidFrom:  time.Now().Add(-time.Hour),
+			ValidTo:    &validTo,
+		},
+	})
+	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateCalibration, requestBody)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := "Could not create calibration. valid_to has to be after valid_from."
+
+	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
+
+func TestCreateCalibration_ShouldReturnErrorLocationIdUnknown(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	requestBody, _ := json.Marshal([]models.CalibrationPostDto{
+		{
+			LocationID: 99,
+			Co2Offset:  -40,
+			ValidFrom:  time.Now(),
+		},
+	})
+	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateCalibration, requestBody)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := "Could not create calibration. The location does not exist."
+
+	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteCalibration_ShouldDeleteCalibration(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	calibrations := []models.Calibration{{LocationID: 1, Co2Offset: 10, ValidFrom: time.Now()}}
+	f.Db.Create(&calibrations)
+	req, writer := tests.SetupRouter(f.Db, http.MethodDelete, "/:id", "/1", api.DeleteCalibration, nil)
+	defer f.Teardown(t)
+
+	expectedInDb := []models.Calibration{}
+	f.Db.Find(&expectedInDb)
+
+	assert.Equal(t, http.MethodDelete, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNoContent, writer.Code, "HTTP request status code error")
+	assert.Equal(t, 0, len(expectedInDb))
+}
+
+func TestDeleteCalibration_ShouldReturnErrorUnknownId(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodDelete, "/:id", "/99", api.DeleteCalibration, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := "Could not find calibration by id."
+
+	assert.Equal(t, http.MethodDelete, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
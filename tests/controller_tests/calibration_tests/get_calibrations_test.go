@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCalibrations_ShouldReturnCalibrationsOfLocation(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	calibrations := []models.Calibration{
+		{LocationID: 1, Co2Offset: 10, ValidFrom: time.Now()},
+		{LocationID: 2, Co2Offset: 20, ValidFrom: time.Now()},
+	}
+	f.Db.Create(&calibrations)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/calibrations", "/1/calibrations", api.GetCalibrations, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := []models.CalibrationDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, 1, len(responseData))
+	assert.Equal(t, calibrations[0].Co2Offset, responseData[0].Co2Offset)
+}
+
+func TestGetCalibrations_ShouldReturnErrorLocationIdUnknown(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/calibrations", "/99/calibrations", api.GetCalibrations, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := "Could not find any location with this id: <99>."
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
@@ -1,15 +1,21 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
-	"github.com/fminister/co2monitor.api/controllers"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/health"
 	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/fminister/co2monitor.api/repositories"
 	"github.com/fminister/co2monitor.api/tests"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateCo2Data_ShouldCreateSingleCo2DataValue(t *testing.T) {
@@ -17,7 +23,7 @@ func TestCreateCo2Data_ShouldCreateSingleCo2DataValue(t *testing.T) {
 	f.Setup(t)
 	// add dummy data or creating newData will fail because of foreign key constraint
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	newCo2Data := []models.Co2Data{
 		{
 			LocationID: 1,
@@ -52,7 +58,7 @@ func TestCreateCo2Data_ShouldCreateMultipleCo2DataValues(t *testing.T) {
 	f.Setup(t)
 	// add dummy data or creating newData will fail because of foreign key constraint
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	newCo2Data := []models.Co2Data{
 		{
 			LocationID: 1,
@@ -89,22 +95,22 @@ func TestCreateCo2Data_ShouldCreateMultipleCo2DataValues(t *testing.T) {
 	assert.Equal(t, newCo2Data[1].CO2, responseData[1].CO2)
 }
 
-func TestCreateCo2Data_ShouldReturnErrorMissingValuesInJSON(t *testing.T) {
+func TestCreateCo2Data_ShouldFlagAnomalies(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	// add dummy data or creating newData will fail because of foreign key constraint
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
 	newCo2Data := []models.Co2Data{
 		{
 			LocationID: 1,
-			CO2:        666,
-		},
-		{
-			Temp: 15,
-			CO2:  777,
+			CO2:        800,
+			Temp:       21.1,
 		},
 		{
 			LocationID: 1,
-			Temp:       10,
+			CO2:        12000,
+			Temp:       21.1,
 		},
 	}
 	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
@@ -114,37 +120,52 @@ func TestCreateCo2Data_ShouldReturnErrorMissingValuesInJSON(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := map[string]map[string][]string{}
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	responseData := []models.Co2DataDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
 		assert.Error(t, err)
 	}
-	expectedErrorMessage := map[string]map[string][]string{
-		"0": {
-			"temp": {
-				"required",
-			},
-		},
-		"1": {
-			"location_id": {
-				"required",
-			},
-		},
-		"2": {
-			"co2": {
-				"required",
-			},
-		},
+	expectedInDb := []models.Co2Data{}
+	f.Db.Where("quality = ?", models.QualityOutOfRange).Find(&expectedInDb)
+
+	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusCreated, writer.Code, "HTTP request status code error")
+	assert.Equal(t, models.QualityOk, responseData[0].Quality)
+	assert.Equal(t, models.QualityOutOfRange, responseData[1].Quality)
+	assert.Equal(t, 1, len(expectedInDb))
+}
+
+func TestCreateCo2Data_ShouldReturnErrorMissingValuesInJSON(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	requestBody := []byte(`[{"location_id": 1, "co2": 666}, {"temp": 15, "co2": 777}, {"location_id": 1, "temp": 10, "co2": null}]`)
+	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateCo2Data, requestBody)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/0/temp", Detail: "required"},
+		{Pointer: "/1/location_id", Detail: "required unless location_name is given"},
+		{Pointer: "/2/co2", Detail: "required"},
 	}
 
 	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, "Some values in the body are invalid.", errorResponse.Detail)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
 }
 
 func TestCreateCo2Data_ShouldReturnErrorCouldNotCreateDataDbError(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	newCo2Data := []models.Co2Data{
 		{
 			LocationID: 99,
@@ -159,21 +180,23 @@ func TestCreateCo2Data_ShouldReturnErrorCouldNotCreateDataDbError(t *testing.T)
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
-	expectedErrorMessage := "Could not create co2 data."
+	expectedErrorMessage := "Could not create co2 data. Some locations do not exist."
 
 	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+	assert.Equal(t, problem.CodeUnknownReference, errorResponse.Code)
+	assert.Equal(t, []models.FieldErrorDto{{Pointer: "/0/location_id", Detail: "Location <99> does not exist."}}, errorResponse.Errors)
 }
 
 func TestCreateCo2Data_ShouldReturnErrorWrongBinding(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	requestBody, _ := json.Marshal(models.Co2Data{
 		LocationID: 99,
 		CO2:        666,
@@ -186,13 +209,411 @@ func TestCreateCo2Data_ShouldReturnErrorWrongBinding(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
 	expectedErrorMessage := "Could not parse co2 data from body."
 
 	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
+
+func TestCreateCo2Data_ShouldReturnErrorUnknownLocationInMemoryStore(t *testing.T) {
+	api := tests.NewMemoryAPIEnv(repositories.NewMemoryStore())
+	newCo2Data := []models.Co2Data{
+		{
+			LocationID: 99,
+			CO2:        666,
+			Temp:       11.1,
+		},
+	}
+	req, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Could not create co2 data. Some locations do not exist.", errorResponse.Detail)
+}
+
+func TestCreateCo2Data_ShouldCreateCo2DataByLocationName(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	newCo2Data := []models.Co2Data{
+		{LocationName: tests.Locations[1].Name, CO2: 666, Temp: 11.1},
+		{LocationID: 1, LocationName: tests.Locations[0].Name, CO2: 777, Temp: 12.2},
+	}
+
+	_, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
+
+	responseData := []models.Co2DataDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &responseData))
+	var stored int64
+	f.Db.Model(&models.Co2Data{}).Where("location_id = ? AND co2 = ?", 2, 666).Count(&stored)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	require.Len(t, responseData, 2)
+	assert.Equal(t, 2, responseData[0].LocationID)
+	assert.Equal(t, 1, responseData[1].LocationID)
+	assert.Equal(t, int64(1), stored)
+}
+
+func TestCreateCo2Data_ShouldReportEveryItemWithUnknownLocation(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	newCo2Data := []models.Co2Data{
+		{LocationID: 1, CO2: 666, Temp: 11.1},
+		{LocationID: 99, CO2: 777, Temp: 12.2},
+		{LocationName: "unknown", CO2: 888, Temp: 13.3},
+		{LocationID: 2, LocationName: tests.Locations[0].Name, CO2: 999, Temp: 14.4},
+	}
+
+	_, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
+
+	errorResponse := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &errorResponse))
+	var stored int64
+	f.Db.Model(&models.Co2Data{}).Count(&stored)
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/1/location_id", Detail: "Location <99> does not exist."},
+		{Pointer: "/2/location_name", Detail: "Location <unknown> does not exist."},
+		{Pointer: "/3/location_name", Detail: "Location <test location 1> does not have the id <2>."},
+	}
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, problem.CodeUnknownReference, errorResponse.Code)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
+	assert.Equal(t, int64(len(tests.CO2)), stored)
+}
+
+func TestCreateCo2Data_ShouldReturnErrorDeletedLocation(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	require.NoError(t, f.Db.Delete(&models.Location{}, 2).Error)
+	newCo2Data := []models.Co2Data{
+		{LocationID: 2, CO2: 666, Temp: 11.1},
+		{LocationName: tests.Locations[1].Name, CO2: 777, Temp: 12.2},
+	}
+
+	_, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
+
+	errorResponse := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &errorResponse))
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/0/location_id", Detail: "Location <2> does not exist."},
+		{Pointer: "/1/location_name", Detail: "Location <test location 2> does not exist."},
+	}
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, problem.CodeUnknownReference, errorResponse.Code)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
+}
+
+func TestCreateCo2Data_ShouldStoreValidItemsInPartialMode(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	requestBody := []byte(`[
+		{"location_id": 1, "co2": 666, "temp": 11.1},
+		{"location_id": 1, "co2": 777},
+		{"location_id": 99, "co2": 888, "temp": 12.2},
+		{"location_id": 2, "co2": 999, "temp": 13.3}
+	]`)
+
+	_, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new?partial=true", api.CreateCo2Data, requestBody)
+
+	response := models.Co2DataBatchDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	var stored int64
+	f.Db.Model(&models.Co2Data{}).Count(&stored)
+
+	assert.Equal(t, http.StatusMultiStatus, writer.Code)
+	assert.Equal(t, int64(len(tests.CO2)+2), stored)
+	require.Len(t, response.Created, 2)
+	assert.Equal(t, 666, response.Created[0].CO2)
+	assert.Equal(t, 999, response.Created[1].CO2)
+	expectedFailed := []models.ItemErrorDto{
+		{
+			Index:  1,
+			Status: http.StatusBadRequest,
+			Code:   problem.CodeValidationFailed,
+			Detail: "Some values are invalid.",
+			Errors: []models.FieldErrorDto{{Pointer: "/1/temp", Detail: "required"}},
+		},
+		{
+			Index:  2,
+			Status: http.StatusBadRequest,
+			Code:   problem.CodeUnknownReference,
+			Detail: "Could not create co2 data. The location does not exist.",
+			Errors: []models.FieldErrorDto{{Pointer: "/2/location_id", Detail: "Location <99> does not exist."}},
+		},
+	}
+	assert.Equal(t, expectedFailed, response.Failed)
+}
+
+func TestCreateCo2Data_ShouldReturnCreatedInPartialModeWithoutFailures(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new?partial=true", api.CreateCo2Data, tests.CO2ToJSON([]models.Co2Data{{LocationID: 1, CO2: 666, Temp: 11.1}}))
+
+	response := models.Co2DataBatchDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	assert.Len(t, response.Created, 1)
+	assert.Empty(t, response.Failed)
+}
+
+func TestCreateCo2Data_ShouldReturnErrorBatchTooLong(t *testing.T) {
+	maxBatch := config.Get().Ingest.MaxBatch
+	config.Get().Ingest.MaxBatch = 2
+	defer func() { config.Get().Ingest.MaxBatch = maxBatch }()
+	api := tests.NewMemoryAPIEnv(repositories.NewMemoryStore())
+	newCo2Data := []models.Co2Data{
+		{LocationID: 1, CO2: 666, Temp: 11.1},
+		{LocationID: 1, CO2: 777, Temp: 11.1},
+		{LocationID: 1, CO2: 888, Temp: 11.1},
+	}
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
+
+	errorResponse := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &errorResponse))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, writer.Code)
+	assert.Equal(t, problem.CodeBatchTooLong, errorResponse.Code)
+	assert.Equal(t, "The body must not contain more than 2 items.", errorResponse.Detail)
+}
+
+func TestCreateCo2Data_ShouldReturnErrorBodyTooLarge(t *testing.T) {
+	maxBodyBytes := config.Get().Ingest.MaxBodyBytes
+	config.Get().Ingest.MaxBodyBytes = 64
+	defer func() { config.Get().Ingest.MaxBodyBytes = maxBodyBytes }()
+	api := tests.NewMemoryAPIEnv(repositories.NewMemoryStore())
+	newCo2Data := []models.Co2Data{
+		{LocationID: 1, CO2: 666, Temp: 11.1},
+		{LocationID: 1, CO2: 777, Temp: 11.1},
+	}
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
+
+	errorResponse := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &errorResponse))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, writer.Code)
+	assert.Equal(t, problem.CodeBodyTooLarge, errorResponse.Code)
+	assert.Equal(t, "The body must not be larger than 64 bytes.", errorResponse.Detail)
+}
+
+func TestCreateCo2Data_ShouldAcceptZeroValues(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, []byte(`[{"location_id": 1, "co2": 0, "temp": 0}]`))
+
+	responseData := []models.Co2DataDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &responseData))
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	require.Len(t, responseData, 1)
+	assert.Equal(t, 0, responseData[0].CO2)
+	assert.Equal(t, float32(0), responseData[0].Temp)
+}
+
+func TestCreateCo2Data_ShouldReturnErrorValuesOutOfRange(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+	newCo2Data := []models.Co2Data{
+		{LocationID: 1, CO2: -5, Temp: 21},
+		{LocationID: 1, CO2: 650, Temp: 900},
+	}
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
+
+	errorResponse := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &errorResponse))
+	stored, err := store.Co2Data().GetLatestPerLocation()
+	require.NoError(t, err)
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/0/co2", Detail: "co2 has to be between 0 and 40000 ppm, got -5."},
+		{Pointer: "/1/temp", Detail: "temp has to be between -40 and 85 °C, got 900."},
+	}
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, problem.CodeValidationFailed, errorResponse.Code)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
+	assert.Empty(t, stored)
+}
+
+func TestCreateCo2Data_ShouldUseConfiguredRanges(t *testing.T) {
+	readings := config.Get().Readings
+	config.Get().Readings.CO2Max = 5000
+	defer func() { config.Get().Readings = readings }()
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON([]models.Co2Data{{LocationID: 1, CO2: 6000, Temp: 21}}))
+
+	errorResponse := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &errorResponse))
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, []models.FieldErrorDto{{Pointer: "/0/co2", Detail: "co2 has to be between 0 and 5000 ppm, got 6000."}}, errorResponse.Errors)
+}
+
+func TestCreateCo2Data_ShouldQuarantineValuesOutOfRange(t *testing.T) {
+	readings := config.Get().Readings
+	config.Get().Readings.OutOfRange = config.OutOfRangeQuarantine
+	defer func() { config.Get().Readings = readings }()
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+	newCo2Data := []models.Co2Data{
+		{LocationID: 1, CO2: 650, Temp: 21},
+		{LocationID: 1, CO2: 650, Temp: 900},
+	}
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, tests.CO2ToJSON(newCo2Data))
+
+	response := models.Co2DataBatchDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	stored, err := store.Co2Data().GetByTimeFrame("1", time.Hour, false)
+	require.NoError(t, err)
+	expectedQuarantined := []models.ItemErrorDto{
+		{
+			Index:  1,
+			Status: http.StatusAccepted,
+			Code:   problem.CodeQuarantined,
+			Detail: "The co2 data was quarantined. Some values are out of range.",
+			Errors: []models.FieldErrorDto{{Pointer: "/1/temp", Detail: "temp has to be between -40 and 85 °C, got 900."}},
+		},
+	}
+
+	assert.Equal(t, http.StatusMultiStatus, writer.Code)
+	require.Len(t, response.Created, 1)
+	assert.Equal(t, float32(21), response.Created[0].Temp)
+	assert.Empty(t, response.Failed)
+	assert.Equal(t, expectedQuarantined, response.Quarantined)
+	assert.Len(t, stored, 1)
+	kept, err := store.Quarantine().GetAll()
+	require.NoError(t, err)
+	require.Len(t, kept, 1)
+	assert.Equal(t, problem.CodeQuarantined, kept[0].Code)
+	assert.Equal(t, []models.FieldErrorDto{{Pointer: "/temp", Detail: "temp has to be between -40 and 85 °C, got 900."}}, kept[0].Errors)
+}
+
+func TestCreateCo2Data_ShouldQuarantineValuesOutOfRangeInPartialMode(t *testing.T) {
+	readings := config.Get().Readings
+	config.Get().Readings.OutOfRange = config.OutOfRangeQuarantine
+	defer func() { config.Get().Readings = readings }()
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+	requestBody := []byte(`[
+		{"location_id": 1, "co2": -5, "temp": 21},
+		{"location_id": 1, "co2": 650},
+		{"location_id": 99, "co2": 650, "temp": 21},
+		{"location_id": 1, "co2": 650, "temp": 21}
+	]`)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new?partial=true", api.CreateCo2Data, requestBody)
+
+	response := models.Co2DataBatchDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+
+	assert.Equal(t, http.StatusMultiStatus, writer.Code)
+	assert.Len(t, response.Created, 1)
+	require.Len(t, response.Failed, 2)
+	assert.Equal(t, 1, response.Failed[0].Index)
+	assert.Equal(t, problem.CodeValidationFailed, response.Failed[0].Code)
+	assert.Equal(t, 2, response.Failed[1].Index)
+	assert.Equal(t, problem.CodeUnknownReference, response.Failed[1].Code)
+	require.Len(t, response.Quarantined, 1)
+	assert.Equal(t, 0, response.Quarantined[0].Index)
+	assert.Equal(t, []models.FieldErrorDto{{Pointer: "/0/co2", Detail: "co2 has to be between 0 and 40000 ppm, got -5."}}, response.Quarantined[0].Errors)
+	kept, err := store.Quarantine().GetAll()
+	require.NoError(t, err)
+	assert.Len(t, kept, 3)
+}
+
+func TestCreateCo2Data_ShouldKeepRejectedItemsWithSource(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+	requestBody := []byte(`[
+		{"location_id": 1, "co2": 650, "temp": 21},
+		{"location_id": 1,   "co2": -5, "temp": 21}
+	]`)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, requestBody)
+
+	kept, err := store.Quarantine().GetAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	require.Len(t, kept, 1)
+	assert.Equal(t, `{"location_id":1,"co2":-5,"temp":21}`, string(kept[0].Payload))
+	assert.Equal(t, problem.CodeValidationFailed, kept[0].Code)
+	assert.Equal(t, "Some values are invalid.", kept[0].Reason)
+	assert.Equal(t, []models.FieldErrorDto{{Pointer: "/co2", Detail: "co2 has to be between 0 and 40000 ppm, got -5."}}, kept[0].Errors)
+	assert.Equal(t, writer.Header().Get("X-Request-ID"), kept[0].RequestID)
+	assert.NotEmpty(t, kept[0].RequestID)
+}
+
+// rejectingCo2Repository fails every create like a location deleted after
+// it was resolved.
+type rejectingCo2Repository struct {
+	repositories.Co2Repository
+}
+
+func (r rejectingCo2Repository) WithContext(ctx context.Context) repositories.Co2Repository {
+	return r
+}
+
+func (r rejectingCo2Repository) Create(co2Data []models.Co2Data) ([]models.Co2Data, error) {
+	return nil, repositories.ErrInvalidReference
+}
+
+func TestCreateCo2Data_ShouldNotDegradeIngestionOnClientErrors(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	api.Co2Data = rejectingCo2Repository{store.Co2Data()}
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+	health.Ingest.Record(nil)
+	defer health.Ingest.Record(nil)
+
+	for i := 0; i < 5; i++ {
+		_, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateCo2Data, []byte(`[{"location_id": 1, "co2": 650, "temp": 21}]`))
+		assert.Equal(t, http.StatusBadRequest, writer.Code)
+	}
+
+	assert.Equal(t, models.HealthOk, health.Ingest.Check().Status)
 }
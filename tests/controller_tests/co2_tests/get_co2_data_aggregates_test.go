@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetCo2DataAggregates_ShouldReturnCalibratedHourlyAggregates(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	bucket := time.Now().UTC().Truncate(time.Hour).Add(-time.Hour)
+	newCo2Data := []models.Co2Data{
+		{Model: gorm.Model{CreatedAt: bucket.Add(5 * time.Minute)}, LocationID: 1, CO2: 600, Temp: 20},
+		{Model: gorm.Model{CreatedAt: bucket.Add(15 * time.Minute)}, LocationID: 1, CO2: 800, Temp: 22},
+		{Model: gorm.Model{CreatedAt: bucket.Add(25 * time.Minute)}, LocationID: 1, CO2: 11000, Temp: 22, Quality: models.QualityOutOfRange},
+	}
+	f.Db.Create(&newCo2Data)
+	f.Db.Create(&models.Calibration{LocationID: 1, Co2Offset: 100, ValidFrom: bucket.Add(-time.Hour)})
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/aggregate", "/1/aggregate?interval=hour&period=3h&exclude_anomalies=true", api.GetCo2DataAggregates, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := []models.Co2AggregateDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, 1, len(responseData))
+	assert.Equal(t, bucket, responseData[0].Bucket)
+	assert.Equal(t, 2, responseData[0].Count)
+	assert.Equal(t, 800.0, responseData[0].AvgCO2)
+	assert.Equal(t, 900.0, responseData[0].MaxCO2)
+	assert.True(t, responseData[0].Calibrated)
+}
+
+func TestGetCo2DataAggregates_ShouldReturnErrorInvalidInterval(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/aggregate", "/1/aggregate?interval=week", api.GetCo2DataAggregates, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Invalid interval: <week>. Use hour or day.", errorResponse.Detail)
+}
+
+func TestGetCo2DataAggregates_ShouldReturnErrorLocationIdUnknown(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/aggregate", "/99/aggregate", api.GetCo2DataAggregates, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Could not find any location with this id: <99>.", errorResponse.Detail)
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetCo2DataAnomalies_ShouldReturnFlaggedCo2Data(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	createdAt := time.Now().Add(-time.Hour)
+	newCo2Data := []models.Co2Data{
+		{
+			Model:      gorm.Model{CreatedAt: createdAt, UpdatedAt: createdAt},
+			LocationID: 1,
+			CO2:        10001,
+			Temp:       20,
+			Quality:    models.QualityOutOfRange,
+		},
+		{
+			Model:      gorm.Model{CreatedAt: createdAt, UpdatedAt: createdAt},
+			LocationID: 1,
+			CO2:        800,
+			Temp:       20,
+			Quality:    models.QualityOk,
+		},
+	}
+	f.Db.Create(&newCo2Data)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/anomalies", "/1/anomalies?period=1d", api.GetCo2DataAnomalies, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := []models.Co2DataDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, 1, len(responseData))
+	assert.Equal(t, newCo2Data[0].CO2, responseData[0].CO2)
+	assert.Equal(t, models.QualityOutOfRange, responseData[0].Quality)
+}
+
+func TestGetCo2DataAnomalies_ShouldReturnErrorLocationIdUnknown(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	locationId := "99"
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/anomalies", fmt.Sprintf(`/%s/anomalies`, locationId), api.GetCo2DataAnomalies, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId)
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
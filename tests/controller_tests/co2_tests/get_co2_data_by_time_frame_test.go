@@ -5,19 +5,21 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
-	"github.com/fminister/co2monitor.api/controllers"
 	"github.com/fminister/co2monitor.api/models"
 	"github.com/fminister/co2monitor.api/tests"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestGetCo2DataByTimeFrame_ShouldReturnListOfCo2Data(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	locationId := "1"
 	searchQuery := "?period=24h"
 	newCo2Data := []models.Co2Data{
@@ -60,7 +62,7 @@ func TestGetCo2DataByTimeFrame_ShouldReturnEmptyList(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	locationId := "1"
 	searchQuery := "?period=1m"
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/search", fmt.Sprintf(`/%s/search%s`, locationId, searchQuery), api.GetCo2DataByTimeFrame, nil)
@@ -84,9 +86,9 @@ func TestGetCo2DataByTimeFrame_ShouldReturnDefaultTimeFrameCo2DataList(t *testin
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	locationId := "1"
-	searchQuery := "?period=asdf"
+	searchQuery := ""
 	newCo2Data := []models.Co2Data{
 		{
 			LocationID: 1,
@@ -119,7 +121,7 @@ func TestGetCo2DataByTimeFrame_ShouldReturnErrorLocationIdUnknown(t *testing.T)
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	locationId := "99"
 	searchQuery := "?period=24h"
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/search", fmt.Sprintf(`/%s/search%s`, locationId, searchQuery), api.GetCo2DataByTimeFrame, nil)
@@ -129,13 +131,144 @@ func TestGetCo2DataByTimeFrame_ShouldReturnErrorLocationIdUnknown(t *testing.T)
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
 	expectedErrorMessage := fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId)
 
 	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
+
+func TestGetCo2DataByTimeFrame_ShouldReturnClosedTimeFrameWithCacheHeaders(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	f.Db.Create(&[]models.Co2Data{{LocationID: 1, CO2: 666, Temp: 11.1}})
+	to := tests.CO2[0].CreatedAt.Add(30 * time.Minute).Format(time.RFC3339)
+	requestRoute := fmt.Sprintf("/1/search?period=1h&to=%s", url.QueryEscape(to))
+
+	writer := tests.SetupGetRouterWithHeaders("/:id/search", requestRoute, api.GetCo2DataByTimeFrame, nil)
+	responseData := []models.Co2Data{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, 2, len(responseData))
+	assert.Equal(t, tests.CO2[0].CO2, responseData[0].CO2)
+	assert.Equal(t, "private, max-age=60", writer.Header().Get("Cache-Control"))
+	assert.Empty(t, writer.Header().Get("Last-Modified"))
+	assert.NotEmpty(t, writer.Header().Get("ETag"))
+}
+
+func TestGetCo2DataByTimeFrame_ShouldReturnNotModified(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	to := url.QueryEscape(tests.CO2[0].CreatedAt.Add(time.Minute).Format(time.RFC3339))
+	requestRoute := "/1/search?period=1h&to=" + to
+
+	first := tests.SetupGetRouterWithHeaders("/:id/search", requestRoute, api.GetCo2DataByTimeFrame, nil)
+	byETag := tests.SetupGetRouterWithHeaders("/:id/search", requestRoute, api.GetCo2DataByTimeFrame, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+	byDate := tests.SetupGetRouterWithHeaders("/:id/search", requestRoute, api.GetCo2DataByTimeFrame, map[string]string{"If-Modified-Since": time.Now().UTC().Format(http.TimeFormat)})
+	changed := tests.SetupGetRouterWithHeaders("/:id/search", "/1/search?period=1m&to="+to, api.GetCo2DataByTimeFrame, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusNotModified, byETag.Code)
+	assert.Empty(t, byETag.Body.String())
+	assert.Equal(t, http.StatusOK, byDate.Code)
+	assert.Equal(t, http.StatusOK, changed.Code)
+}
+
+func TestGetCo2DataByTimeFrame_ShouldRevalidateOpenTimeFrame(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+
+	writer := tests.SetupGetRouterWithHeaders("/:id/search", "/1/search?period=1h", api.GetCo2DataByTimeFrame, nil)
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Equal(t, "private, no-cache", writer.Header().Get("Cache-Control"))
+	assert.Empty(t, writer.Header().Get("Last-Modified"))
+}
+
+func TestGetCo2DataByTimeFrame_ShouldReturnErrorInvalidTo(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+
+	writer := tests.SetupGetRouterWithHeaders("/:id/search", "/1/search?period=1h&to=yesterday", api.GetCo2DataByTimeFrame, nil)
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, "Invalid to: <yesterday>. Use RFC 3339, e.g. 2023-08-01T12:00:00Z.", errorResponse.Detail)
+}
+
+func TestGetCo2DataByTimeFrame_ShouldReturnErrorInvalidPeriod(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+
+	testCases := []struct {
+		period   string
+		expected string
+	}{
+		{"asdf", "Invalid period: <asdf>. Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"-1h", "Invalid period: <-1h>. Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"0h", "Invalid period: <0h>. The period has to be longer than 0."},
+		{"1000d", "Invalid period: <1000d>. The period must not be longer than 90d."},
+		{"P1M", "Invalid period: <P1M>. Years and months are not supported, use weeks or days, e.g. P30D."},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.period, func(t *testing.T) {
+			writer := tests.SetupGetRouterWithHeaders("/:id/search", "/1/search?period="+url.QueryEscape(testCase.period), api.GetCo2DataByTimeFrame, nil)
+			errorResponse := models.ProblemDto{}
+			if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+				assert.Error(t, err)
+			}
+
+			assert.Equal(t, http.StatusBadRequest, writer.Code)
+			assert.Equal(t, testCase.expected, errorResponse.Detail)
+		})
+	}
+}
+
+func TestGetCo2DataByTimeFrame_ShouldAcceptCompoundPeriod(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	f.Db.Create(&[]models.Location{{Name: "Office"}})
+	f.Db.Create(&[]models.Co2Data{
+		{LocationID: 1, CO2: 600, Temp: 20, Model: gorm.Model{CreatedAt: time.Now().Add(-30 * time.Hour)}},
+		{LocationID: 1, CO2: 700, Temp: 20, Model: gorm.Model{CreatedAt: time.Now().Add(-40 * time.Hour)}},
+	})
+
+	for _, period := range []string{"1d12h", "P1DT12H"} {
+		writer := tests.SetupGetRouterWithHeaders("/:id/search", "/1/search?period="+period, api.GetCo2DataByTimeFrame, nil)
+		responseData := []models.Co2DataDto{}
+		if err := json.Unmarshal(writer.Body.Bytes(), &responseData); err != nil {
+			assert.Error(t, err)
+		}
+
+		assert.Equal(t, http.StatusOK, writer.Code)
+		assert.Len(t, responseData, 1, period)
+	}
 }
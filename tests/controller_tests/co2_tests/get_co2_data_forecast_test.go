@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetCo2DataForecast_ShouldReturnForecast(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	newCo2Data := []models.Co2Data{}
+	for i := 0; i < 10; i++ {
+		createdAt := time.Now().Add(time.Duration(i-10) * time.Minute)
+		newCo2Data = append(newCo2Data, models.Co2Data{
+			Model:      gorm.Model{CreatedAt: createdAt, UpdatedAt: createdAt},
+			LocationID: 1,
+			CO2:        800 + i*10,
+			Temp:       21,
+		})
+	}
+	f.Db.Create(&newCo2Data)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/forecast", "/1/forecast?horizon=30m&model=linear", api.GetCo2DataForecast, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := models.ForecastDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, ex.ForecastModelLinear, responseData.Model)
+	assert.Equal(t, 30, responseData.HorizonMinutes)
+	assert.Equal(t, 6, len(responseData.Predictions))
+	assert.InDelta(t, 11, *responseData.MinutesUntilThreshold, 1)
+}
+
+func TestGetCo2DataForecast_ShouldReturnErrorNotEnoughData(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	locationId := "1"
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/forecast", fmt.Sprintf(`/%s/forecast`, locationId), api.GetCo2DataForecast, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := fmt.Sprintf(`Not enough co2 data to forecast for locationId: <%s>.`, locationId)
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
+
+func TestGetCo2DataForecast_ShouldReturnErrorLocationIdUnknown(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	locationId := "99"
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/forecast", fmt.Sprintf(`/%s/forecast`, locationId), api.GetCo2DataForecast, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId)
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
+
+func TestGetCo2DataForecast_ShouldReturnErrorInvalidHorizon(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/forecast", "/1/forecast?horizon=60", api.GetCo2DataForecast, nil)
+	defer f.Teardown(t)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Invalid horizon: <60>. Use a positive duration like 30m, 6h, 1d12h, 2w or P7D.", errorResponse.Detail)
+}
+
+func TestGetCo2DataForecast_ShouldReturnErrorHorizonTooLong(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	_, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/forecast", "/1/forecast?horizon=48h", api.GetCo2DataForecast, nil)
+	defer f.Teardown(t)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, problem.CodeInvalidParameter, errorResponse.Code)
+	assert.Equal(t, "Invalid horizon: <48h>. The period must not be longer than 1d.", errorResponse.Detail)
+}
+
+func TestGetCo2DataForecast_ShouldReturnErrorInvalidThreshold(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	defer f.Teardown(t)
+
+	for _, threshold := range []string{"abc", "0", "-5"} {
+		_, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/forecast", "/1/forecast?threshold="+threshold, api.GetCo2DataForecast, nil)
+
+		errorResponse := models.ProblemDto{}
+		if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+			assert.Error(t, err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+		assert.Equal(t, problem.CodeInvalidParameter, errorResponse.Code)
+		assert.Equal(t, "Invalid threshold: <"+threshold+">. Use a positive number of ppm, e.g. 1000.", errorResponse.Detail)
+	}
+}
+
+func TestGetCo2DataForecast_ShouldReturnErrorUnknownModel(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	_, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/forecast", "/1/forecast?model=lienar", api.GetCo2DataForecast, nil)
+	defer f.Teardown(t)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, problem.CodeInvalidParameter, errorResponse.Code)
+	assert.Equal(t, "Invalid model: <lienar>. Unknown model, use one of: holt, linear.", errorResponse.Detail)
+	assert.Equal(t, []models.FieldErrorDto{{Pointer: "model", Detail: "Unknown model, use one of: holt, linear."}}, errorResponse.Errors)
+}
@@ -5,9 +5,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
-	"github.com/fminister/co2monitor.api/controllers"
 	"github.com/fminister/co2monitor.api/models"
 	"github.com/fminister/co2monitor.api/tests"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +18,7 @@ func TestGetLatestCo2Data_ShouldReturnSingleCo2Data(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/latest", "/1/latest", api.GetLatestCo2Data, nil)
 	defer f.Teardown(t)
 
@@ -37,11 +38,48 @@ func TestGetLatestCo2Data_ShouldReturnSingleCo2Data(t *testing.T) {
 	assert.Equal(t, tests.CO2[0].LocationID, responseData.LocationID)
 }
 
+func TestGetLatestCo2Data_ShouldReturnCalibratedAndRawCo2Data(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	calibrations := []models.Calibration{
+		{
+			LocationID: 1,
+			Co2Offset:  -1,
+			TempOffset: 0.9,
+			ValidFrom:  tests.CO2[0].CreatedAt.Add(-time.Hour),
+		},
+	}
+	f.Db.Create(&calibrations)
+	_, calibratedWriter := tests.SetupRouter(f.Db, http.MethodGet, "/:id/latest", "/1/latest", api.GetLatestCo2Data, nil)
+	_, rawWriter := tests.SetupRouter(f.Db, http.MethodGet, "/:id/latest", "/1/latest?raw=true", api.GetLatestCo2Data, nil)
+	defer f.Teardown(t)
+
+	calibrated := models.Co2DataDto{}
+	if err := json.Unmarshal(calibratedWriter.Body.Bytes(), &calibrated); err != nil {
+		assert.Error(t, err)
+	}
+	raw := models.Co2DataDto{}
+	if err := json.Unmarshal(rawWriter.Body.Bytes(), &raw); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.StatusOK, calibratedWriter.Code, "HTTP request status code error")
+	assert.Equal(t, http.StatusOK, rawWriter.Code, "HTTP request status code error")
+	assert.Equal(t, 1000, calibrated.CO2)
+	assert.InDelta(t, 21, calibrated.Temp, 0.001)
+	assert.True(t, calibrated.Calibrated)
+	assert.Equal(t, tests.CO2[0].CO2, raw.CO2)
+	assert.Equal(t, tests.CO2[0].Temp, raw.Temp)
+	assert.False(t, raw.Calibrated)
+}
+
 func TestGetLatestCo2Data_ShouldReturnErrorLocationIdUnknown(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	locationId := "99"
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/latest", fmt.Sprintf(`/%s/latest`, locationId), api.GetLatestCo2Data, nil)
 	defer f.Teardown(t)
@@ -50,13 +88,73 @@ func TestGetLatestCo2Data_ShouldReturnErrorLocationIdUnknown(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
 	expectedErrorMessage := fmt.Sprintf(`Could not find any co2 data with this locationId: <%s>.`, locationId)
 
 	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
+
+func TestGetLatestCo2Data_ShouldReturnNotModifiedForMatchingETag(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		return tests.SetupGetRouterWithHeaders("/:id/latest", "/1/latest", api.GetLatestCo2Data, map[string]string{"If-None-Match": ifNoneMatch})
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	notModified := get(etag)
+	_, err := api.Co2Data.Create([]models.Co2Data{{LocationID: 1, CO2: 1234, Temp: 20}})
+	assert.NoError(t, err)
+	changed := get(etag)
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	assert.Equal(t, etag, notModified.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+}
+
+func TestGetLatestCo2Data_ShouldBeModifiedByNewerCalibration(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	f.Db.Create(&[]models.Calibration{{LocationID: 1, Co2Offset: -1, ValidFrom: tests.CO2[0].CreatedAt.Add(-time.Hour)}})
+	readingModified := tests.CO2[0].UpdatedAt.UTC().Format(http.TimeFormat)
+
+	writer := tests.SetupGetRouterWithHeaders("/:id/latest", "/1/latest", api.GetLatestCo2Data, map[string]string{"If-Modified-Since": readingModified})
+	lastModified, err := http.ParseTime(writer.Header().Get("Last-Modified"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.True(t, lastModified.After(tests.CO2[0].UpdatedAt))
+}
+
+func TestGetLatestCo2Data_ShouldReturnErrorDbUnavailable(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	f.CloseDb(t)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/latest", "/1/latest", api.GetLatestCo2Data, nil)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusInternalServerError, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Could not get co2 data.", errorResponse.Detail)
 }
@@ -6,17 +6,19 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/fminister/co2monitor.api/controllers"
 	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/fminister/co2monitor.api/repositories"
 	"github.com/fminister/co2monitor.api/tests"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
 func TestCreateLocation_ShouldCreateSingleLocation(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateLocation, tests.LocationsToJSON([]models.Location{tests.Locations[0]}))
 	defer f.Teardown(t)
 
@@ -43,7 +45,7 @@ func TestCreateLocation_ShouldCreateSingleLocation(t *testing.T) {
 func TestCreateLocation_ShouldCreateMultipleLocations(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateLocation, tests.LocationsToJSON([]models.Location{tests.Locations[0], tests.Locations[1]}))
 	defer f.Teardown(t)
 
@@ -72,7 +74,7 @@ func TestCreateLocation_ShouldCreateMultipleLocations(t *testing.T) {
 func TestCreateLocation_ShouldReturnErrorMissingNameInJSON(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	requestBody, _ := json.Marshal([]models.Location{
 		{
 			Name: "",
@@ -85,28 +87,25 @@ func TestCreateLocation_ShouldReturnErrorMissingNameInJSON(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := map[string]map[string][]string{}
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
-	expectedErrorMessage := map[string]map[string][]string{
-		"0": {
-			"name": {
-				"required",
-				"name's length must be higher equal to 3",
-			},
-		},
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/0/name", Detail: "name's length must be higher equal to 3"},
+		{Pointer: "/0/name", Detail: "required"},
 	}
 
 	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, "Some values in the body are invalid.", errorResponse.Detail)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
 }
 
 func TestCreateLocation_ShouldReturnErrorNameToShortInJSON(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	requestBody, _ := json.Marshal([]models.Location{
 		{
 			Name: "a",
@@ -119,28 +118,25 @@ func TestCreateLocation_ShouldReturnErrorNameToShortInJSON(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := map[string]map[string][]string{}
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
-	expectedErrorMessage := map[string]map[string][]string{
-		"0": {
-			"name": {
-				"name's length must be higher equal to 3",
-			},
-		},
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/0/name", Detail: "name's length must be higher equal to 3"},
 	}
 
 	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, "Some values in the body are invalid.", errorResponse.Detail)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
 }
 
 func TestCreateLocation_ShouldReturnErrorNameAlreadyExistsInDb(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateLocation, tests.LocationsToJSON([]models.Location{tests.Locations[0]}))
 	defer f.Teardown(t)
 
@@ -148,21 +144,22 @@ func TestCreateLocation_ShouldReturnErrorNameAlreadyExistsInDb(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
 	expectedErrorMessage := "Could not create location. Name already exists."
 
 	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
-	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, http.StatusConflict, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+	assert.Equal(t, problem.CodeDuplicateName, errorResponse.Code)
 }
 
 func TestCreateLocation_ShouldReturnErrorWrongBinding(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	requestBody, _ := json.Marshal(models.Location{
 		Model: gorm.Model{
 			ID: 99,
@@ -176,13 +173,101 @@ func TestCreateLocation_ShouldReturnErrorWrongBinding(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
 	expectedErrorMessage := "Could not parse location from body."
 
 	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
+
+func TestCreateLocation_ShouldCreateLocationInMemoryStore(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	api := tests.NewMemoryAPIEnv(store)
+	req, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateLocation, tests.LocationsToJSON([]models.Location{tests.Locations[0]}))
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := []models.Location{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+	stored, err := store.Locations().GetAll()
+
+	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusCreated, writer.Code, "HTTP request status code error")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(stored))
+	assert.Equal(t, tests.Locations[0].Name, responseData[0].Name)
+	assert.Equal(t, stored[0].ID, responseData[0].ID)
+}
+
+func TestCreateLocation_ShouldReturnErrorEmptyList(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new", api.CreateLocation, []byte(`[]`))
+	defer f.Teardown(t)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Could not create location. The body contains no locations.", errorResponse.Detail)
+}
+
+func TestCreateLocation_ShouldReturnErrorNameAlreadyExistsInMemoryStore(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	_, err := store.Locations().Create([]models.Location{{Name: "Office"}})
+	assert.NoError(t, err)
+	api := tests.NewMemoryAPIEnv(store)
+	requestBody, _ := json.Marshal([]models.Location{{Name: "Office"}})
+	req, writer := tests.SetupRouter(nil, http.MethodPost, "/new", "/new", api.CreateLocation, requestBody)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodPost, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusConflict, writer.Code, "HTTP request status code error")
+	assert.Equal(t, problem.CodeDuplicateName, errorResponse.Code)
+}
+
+func TestCreateLocation_ShouldCreateValidLocationsInPartialMode(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	newLocations := []models.Location{
+		{Name: "kitchen"},
+		{Name: "ab"},
+		{Name: tests.Locations[0].Name},
+		{Name: "office"},
+	}
+
+	_, writer := tests.SetupRouter(f.Db, http.MethodPost, "/new", "/new?partial=true", api.CreateLocation, tests.LocationsToJSON(newLocations))
+
+	response := models.LocationBatchDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusMultiStatus, writer.Code)
+	require.Len(t, response.Created, 2)
+	assert.Equal(t, "kitchen", response.Created[0].Name)
+	assert.Equal(t, "office", response.Created[1].Name)
+	require.Len(t, response.Failed, 2)
+	assert.Equal(t, 1, response.Failed[0].Index)
+	assert.Equal(t, problem.CodeValidationFailed, response.Failed[0].Code)
+	assert.Equal(t, "/1/name", response.Failed[0].Errors[0].Pointer)
+	assert.Equal(t, 2, response.Failed[1].Index)
+	assert.Equal(t, http.StatusConflict, response.Failed[1].Status)
+	assert.Equal(t, "Could not create location. Name already exists.", response.Failed[1].Detail)
 }
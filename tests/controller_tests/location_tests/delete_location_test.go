@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/fminister/co2monitor.api/controllers"
 	"github.com/fminister/co2monitor.api/models"
 	"github.com/fminister/co2monitor.api/tests"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +15,7 @@ func TestDeleteLocation_ShouldDeleteLocation(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodDelete, "/:id", "/1", api.DeleteLocation, nil)
 	defer f.Teardown(t)
 
@@ -24,15 +23,12 @@ func TestDeleteLocation_ShouldDeleteLocation(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	responseData := ""
-	if err := json.Unmarshal(body, &responseData); err != nil {
-		assert.Error(t, err)
-	}
 	expectedInDb := []models.Location{}
 	f.Db.Find(&expectedInDb)
 
 	assert.Equal(t, http.MethodDelete, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusNoContent, writer.Code, "HTTP request status code error")
+	assert.Empty(t, body)
 	assert.Equal(t, len(expectedInDb), 1)
 	assert.Equal(t, expectedInDb[0].Name, tests.Locations[1].Name)
 }
@@ -41,7 +37,7 @@ func TestDeleteLocation_ShouldReturnErrorUnknownId(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodDelete, "/:id", "/99", api.DeleteLocation, nil)
 	defer f.Teardown(t)
 
@@ -49,8 +45,8 @@ func TestDeleteLocation_ShouldReturnErrorUnknownId(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
 	expectedErrorMessage := "Could not find location by id."
@@ -59,6 +55,40 @@ func TestDeleteLocation_ShouldReturnErrorUnknownId(t *testing.T) {
 
 	assert.Equal(t, http.MethodDelete, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
 	assert.Equal(t, len(expectedInDb), len(tests.Locations))
 }
+
+func TestDeleteLocation_ShouldReturnErrorNonNumericId(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodDelete, "/:id", "/1%20OR%201=1", api.DeleteLocation, nil)
+	defer f.Teardown(t)
+
+	inDb := []models.Location{}
+	f.Db.Find(&inDb)
+
+	assert.Equal(t, http.MethodDelete, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Len(t, inDb, len(tests.Locations))
+}
+
+func TestDeleteLocation_ShouldReturnErrorDbUnavailable(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	f.CloseDb(t)
+	req, writer := tests.SetupRouter(f.Db, http.MethodDelete, "/:id", "/1", api.DeleteLocation, nil)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodDelete, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusInternalServerError, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Could not get location.", errorResponse.Detail)
+}
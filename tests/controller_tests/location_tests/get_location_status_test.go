@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/fminister/co2monitor.api/workers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLocationStatus_ShouldReturnStatusOfAllLocations(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	heartbeat := workers.NewHeartbeatWatcher(f.Db, 15*time.Minute, time.Hour)
+	heartbeat.Start(context.Background())
+	defer heartbeat.Stop()
+	heartbeat.Check()
+	heartbeat.Touch(1, time.Now())
+	api := tests.NewAPIEnv(f.Db)
+	api.Heartbeat = heartbeat
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/status", "/status", api.GetLocationStatus, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := []models.LocationStatusDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, 2, len(responseData))
+	assert.Equal(t, models.StatusOnline, responseData[0].Status)
+	assert.Equal(t, models.StatusOffline, responseData[1].Status)
+}
+
+func TestGetLocationStatus_ShouldReturnErrorWatcherNotRunning(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/status", "/status", api.GetLocationStatus, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := "Location status is not available."
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
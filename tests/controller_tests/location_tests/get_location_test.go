@@ -5,9 +5,10 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
-	"github.com/fminister/co2monitor.api/controllers"
 	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
 	"github.com/fminister/co2monitor.api/tests"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,7 +16,7 @@ import (
 func TestGetLocation_ShouldReturnEmptyList(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/", "/", api.GetLocations, nil)
 	defer f.Teardown(t)
 
@@ -39,7 +40,7 @@ func TestGetLocation_ShouldReturnAllLocations(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/", "/", api.GetLocations, nil)
 	defer f.Teardown(t)
 
@@ -57,3 +58,61 @@ func TestGetLocation_ShouldReturnAllLocations(t *testing.T) {
 	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
 	assert.Equal(t, len(expected), len(responseData))
 }
+
+func TestGetLocation_ShouldReturnNotModifiedUntilLocationChanges(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+
+	first := tests.SetupGetRouterWithHeaders("/", "/", api.GetLocations, nil)
+	notModified := tests.SetupGetRouterWithHeaders("/", "/", api.GetLocations, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+	location, err := api.Locations.GetById("2")
+	assert.NoError(t, err)
+	location.Name = "renamed location"
+	_, err = api.Locations.Update(location)
+	assert.NoError(t, err)
+	changed := tests.SetupGetRouterWithHeaders("/", "/", api.GetLocations, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "private, no-cache", first.Header().Get("Cache-Control"))
+	assert.Empty(t, first.Header().Get("Last-Modified"))
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Equal(t, http.StatusOK, changed.Code)
+}
+
+func TestGetLocation_ShouldIgnoreIfModifiedSinceAfterDelete(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	api := tests.NewAPIEnv(f.Db)
+	location, err := api.Locations.GetById("2")
+	assert.NoError(t, err)
+	assert.NoError(t, api.Locations.Delete(location))
+
+	writer := tests.SetupGetRouterWithHeaders("/", "/", api.GetLocations, map[string]string{"If-Modified-Since": time.Now().UTC().Format(http.TimeFormat)})
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Empty(t, writer.Header().Get("Last-Modified"))
+}
+
+func TestGetLocation_ShouldReturnErrorDbUnavailable(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	f.CloseDb(t)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/", "/", api.GetLocations, nil)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusInternalServerError, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Could not get locations.", errorResponse.Detail)
+	assert.Equal(t, problem.CodeInternal, errorResponse.Code)
+	assert.NotContains(t, writer.Body.String(), "closed")
+}
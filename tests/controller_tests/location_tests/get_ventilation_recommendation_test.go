@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetVentilationRecommendation_ShouldRecommendVentilatingSoon(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	newCo2Data := []models.Co2Data{}
+	for i, value := range []int{700, 720, 740, 760} {
+		createdAt := time.Now().Add(time.Duration(i-3) * time.Minute)
+		newCo2Data = append(newCo2Data, models.Co2Data{
+			Model:      gorm.Model{CreatedAt: createdAt, UpdatedAt: createdAt},
+			LocationID: 1,
+			CO2:        value,
+			Temp:       21,
+		})
+	}
+	f.Db.Create(&newCo2Data)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/ventilation", "/1/ventilation", api.GetVentilationRecommendation, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := models.VentilationDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, ex.VentilateSoon, responseData.Recommendation)
+	assert.Equal(t, ex.SlopeMeasured, responseData.SlopeSource)
+	assert.Equal(t, 760, responseData.LatestCO2)
+	assert.InDelta(t, 12, *responseData.MinutesUntilThreshold, 1)
+}
+
+func TestGetVentilationRecommendation_ShouldFallBackToLatestValue(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	createdAt := time.Now().Add(-time.Hour)
+	f.Db.Create(&models.Co2Data{Model: gorm.Model{CreatedAt: createdAt, UpdatedAt: createdAt}, LocationID: 1, CO2: 1100, Temp: 21})
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/ventilation", "/1/ventilation", api.GetVentilationRecommendation, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := models.VentilationDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, ex.VentilateNow, responseData.Recommendation)
+	assert.Equal(t, 1100, responseData.LatestCO2)
+}
+
+func TestGetVentilationRecommendation_ShouldReturnErrorLatestValueTooOld(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	_, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/ventilation", "/1/ventilation", api.GetVentilationRecommendation, nil)
+	defer f.Teardown(t)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Equal(t, problem.CodeCo2DataNotFound, errorResponse.Code)
+}
+
+func TestGetVentilationRecommendation_ShouldReturnErrorLocationIdUnknown(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	locationId := "99"
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/:id/ventilation", fmt.Sprintf(`/%s/ventilation`, locationId), api.GetVentilationRecommendation, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+	expectedErrorMessage := fmt.Sprintf(`Could not find any location with this id: <%s>.`, locationId)
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
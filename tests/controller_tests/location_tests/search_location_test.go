@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/fminister/co2monitor.api/controllers"
 	"github.com/fminister/co2monitor.api/models"
 	"github.com/fminister/co2monitor.api/tests"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +15,7 @@ func TestGetLocationBySearch_ShouldReturnEmptyList(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/search", "/search?id=999&name=not in database", api.GetLocationBySearch, nil)
 	defer f.Teardown(t)
 
@@ -40,7 +39,7 @@ func TestGetLocationBySearch_ShouldReturnLocationById(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/search", "/search?id=2", api.GetLocationBySearch, nil)
 	defer f.Teardown(t)
 
@@ -64,7 +63,7 @@ func TestGetLocationBySearch_ShouldReturnLocationByName(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/search", "/search?name=test location 2", api.GetLocationBySearch, nil)
 	defer f.Teardown(t)
 
@@ -87,7 +86,7 @@ func TestGetLocationBySearch_ShouldReturnLocationByIdAndName(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/search", "/search?id=1&name=test location 2", api.GetLocationBySearch, nil)
 	defer f.Teardown(t)
 
@@ -107,3 +106,38 @@ func TestGetLocationBySearch_ShouldReturnLocationByIdAndName(t *testing.T) {
 	assert.Equal(t, expected[0].Name, responseData[0].Name)
 	assert.Equal(t, expected[1].Name, responseData[1].Name)
 }
+
+func TestGetLocationBySearch_ShouldReturnEmptyListForNonNumericId(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/search", "/search?id=1%20OR%201=1", api.GetLocationBySearch, nil)
+	defer f.Teardown(t)
+
+	responseData := []models.Location{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Empty(t, responseData)
+}
+
+func TestGetLocationBySearch_ShouldReturnErrorDbUnavailable(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	f.CloseDb(t)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/search", "/search?name=Office", api.GetLocationBySearch, nil)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusInternalServerError, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Could not search locations.", errorResponse.Detail)
+}
@@ -6,8 +6,8 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/fminister/co2monitor.api/controllers"
 	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
 	"github.com/fminister/co2monitor.api/tests"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
@@ -17,7 +17,7 @@ func TestUpdateLocation_ShouldUpdateLocation(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	updatedLocation := models.Location{
 		Model: gorm.Model{
 			ID: 1,
@@ -51,7 +51,7 @@ func TestUpdateLocation_ShouldReturnErrorUnknownId(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	requestBody, _ := json.Marshal(models.Location{
 		Model: gorm.Model{
 			ID: 99,
@@ -65,22 +65,22 @@ func TestUpdateLocation_ShouldReturnErrorUnknownId(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
 	expectedErrorMessage := "Could not find location by id."
 
 	assert.Equal(t, http.MethodPatch, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusNotFound, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
 }
 
 func TestUpdateLocation_ShouldReturnErrorMissingNameInJSON(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	requestBody, _ := json.Marshal(models.Location{
 		Name: "",
 	})
@@ -91,27 +91,26 @@ func TestUpdateLocation_ShouldReturnErrorMissingNameInJSON(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := map[string][]string{}
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
-	expectedErrorMessage := map[string][]string{
-		"name": {
-			"required",
-			"name's length must be higher equal to 3",
-		},
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/name", Detail: "name's length must be higher equal to 3"},
+		{Pointer: "/name", Detail: "required"},
 	}
 
 	assert.Equal(t, http.MethodPatch, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, "Some values in the body are invalid.", errorResponse.Detail)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
 }
 
 func TestUpdateLocation_ShouldReturnErrorNameToShortInJSON(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	requestBody, _ := json.Marshal(models.Location{
 		Name: "a",
 	})
@@ -122,26 +121,25 @@ func TestUpdateLocation_ShouldReturnErrorNameToShortInJSON(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := map[string][]string{}
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
-	expectedErrorMessage := map[string][]string{
-		"name": {
-			"name's length must be higher equal to 3",
-		},
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/name", Detail: "name's length must be higher equal to 3"},
 	}
 
 	assert.Equal(t, http.MethodPatch, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, "Some values in the body are invalid.", errorResponse.Detail)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
 }
 
 func TestUpdateLocation_ShouldReturnErrorWrongBinding(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
 	f.AddDummyData(t)
-	api := &controllers.APIEnv{DB: f.Db}
+	api := tests.NewAPIEnv(f.Db)
 	requestBody, _ := json.Marshal([]models.Location{
 		{
 			Name: "a",
@@ -154,13 +152,127 @@ func TestUpdateLocation_ShouldReturnErrorWrongBinding(t *testing.T) {
 	if err != nil {
 		assert.Error(t, err)
 	}
-	errorMessage := ""
-	if err := json.Unmarshal(body, &errorMessage); err != nil {
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil {
 		assert.Error(t, err)
 	}
 	expectedErrorMessage := "Could not parse location details from body."
 
 	assert.Equal(t, http.MethodPatch, req.Method, "HTTP request method error")
 	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
-	assert.Equal(t, expectedErrorMessage, errorMessage)
+	assert.Equal(t, expectedErrorMessage, errorResponse.Detail)
+}
+
+func TestUpdateLocation_ShouldKeepIdOfPath(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	requestBody, _ := json.Marshal(models.Location{
+		Name: "updated location",
+	})
+	req, writer := tests.SetupRouter(f.Db, http.MethodPatch, "/:id", "/2", api.UpdateLocation, requestBody)
+	defer f.Teardown(t)
+
+	responseData := models.LocationDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &responseData); err != nil {
+		assert.Error(t, err)
+	}
+	inDb := []models.Location{}
+	f.Db.Order("id").Find(&inDb)
+
+	assert.Equal(t, http.MethodPatch, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, uint(2), responseData.ID)
+	assert.Len(t, inDb, len(tests.Locations))
+	assert.Equal(t, "updated location", inDb[1].Name)
+}
+
+func TestUpdateLocation_ShouldReturnErrorNameAlreadyExists(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	requestBody, _ := json.Marshal(models.Location{
+		Name: tests.Locations[1].Name,
+	})
+	req, writer := tests.SetupRouter(f.Db, http.MethodPatch, "/:id", "/1", api.UpdateLocation, requestBody)
+	defer f.Teardown(t)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodPatch, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusConflict, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "Could not update location. Name already exists.", errorResponse.Detail)
+	assert.Equal(t, problem.CodeDuplicateName, errorResponse.Code)
+}
+
+func TestUpdateLocation_ShouldReturnErrorDbUnavailable(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	f.CloseDb(t)
+	requestBody, _ := json.Marshal(models.Location{
+		Name: "updated location",
+	})
+	req, writer := tests.SetupRouter(f.Db, http.MethodPatch, "/:id", "/1", api.UpdateLocation, requestBody)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodPatch, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusInternalServerError, writer.Code, "HTTP request status code error")
+	assert.Equal(t, problem.CodeInternal, errorResponse.Code)
+}
+
+func TestUpdateLocation_ShouldKeepOmittedFields(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	f.Db.Model(&models.Location{}).Where("id = ?", 1).Updates(map[string]interface{}{"room_volume": 40, "occupancy": 3})
+	api := tests.NewAPIEnv(f.Db)
+	_, writer := tests.SetupRouter(f.Db, http.MethodPatch, "/:id", "/1", api.UpdateLocation, []byte(`{"name": "updated location"}`))
+	defer f.Teardown(t)
+
+	responseData := models.LocationDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &responseData); err != nil {
+		assert.Error(t, err)
+	}
+	stored := models.Location{}
+	f.Db.First(&stored, 1)
+
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, "updated location", responseData.Name)
+	assert.Equal(t, float32(40), stored.RoomVolume)
+	assert.Equal(t, 3, stored.Occupancy)
+	assert.Equal(t, 3, responseData.Occupancy)
+}
+
+func TestUpdateLocation_ShouldReturnErrorNegativeRoomVolumeAndOccupancy(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	api := tests.NewAPIEnv(f.Db)
+	_, writer := tests.SetupRouter(f.Db, http.MethodPatch, "/:id", "/1", api.UpdateLocation, []byte(`{"room_volume": -1, "occupancy": -2}`))
+	defer f.Teardown(t)
+
+	errorResponse := models.ProblemDto{}
+	if err := json.Unmarshal(writer.Body.Bytes(), &errorResponse); err != nil {
+		assert.Error(t, err)
+	}
+
+	expectedErrors := []models.FieldErrorDto{
+		{Pointer: "/occupancy", Detail: "must not be negative"},
+		{Pointer: "/room_volume", Detail: "must not be negative"},
+	}
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code, "HTTP request status code error")
+	assert.Equal(t, problem.CodeValidationFailed, errorResponse.Code)
+	assert.Equal(t, expectedErrors, errorResponse.Errors)
 }
@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/fminister/co2monitor.api/repositories"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupQuarantine adds the kitchen and a quarantined co2 data item and
+// returns the route of the item.
+func setupQuarantine(t *testing.T, payload string) (*repositories.MemoryStore, string) {
+	store := repositories.NewMemoryStore()
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+	quarantined, err := store.Quarantine().Create([]models.QuarantinedCo2Data{{
+		Payload:  []byte(payload),
+		Code:     problem.CodeQuarantined,
+		Reason:   "The co2 data was quarantined. Some values are out of range.",
+		Errors:   []models.FieldErrorDto{{Pointer: "/co2", Detail: "co2 has to be between 0 and 40000 ppm, got -5."}},
+		ClientIP: "10.0.0.1",
+	}})
+	require.NoError(t, err)
+
+	return store, fmt.Sprintf("/%d", quarantined[0].ID)
+}
+
+func TestGetQuarantinedCo2Data_ShouldListEntries(t *testing.T) {
+	store, _ := setupQuarantine(t, `{"location_id":1,"co2":-5,"temp":21}`)
+	api := tests.NewMemoryAPIEnv(store)
+
+	_, writer := tests.SetupRouter(nil, http.MethodGet, "/", "/", api.GetQuarantinedCo2Data, nil)
+
+	response := []models.QuarantinedCo2DataDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	require.Len(t, response, 1)
+	assert.JSONEq(t, `{"location_id":1,"co2":-5,"temp":21}`, string(response[0].Payload))
+	assert.Equal(t, problem.CodeQuarantined, response[0].Code)
+	assert.Equal(t, "10.0.0.1", response[0].ClientIP)
+}
+
+func TestGetQuarantinedCo2DataById_ShouldReturnNotFound(t *testing.T) {
+	api := tests.NewMemoryAPIEnv(repositories.NewMemoryStore())
+
+	_, writer := tests.SetupRouter(nil, http.MethodGet, "/:id", "/7", api.GetQuarantinedCo2DataById, nil)
+
+	response := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+
+	assert.Equal(t, http.StatusNotFound, writer.Code)
+	assert.Equal(t, problem.CodeQuarantineNotFound, response.Code)
+}
+
+func TestReplayQuarantinedCo2Data_ShouldStoreFixedPayloadAndRemoveEntry(t *testing.T) {
+	store, route := setupQuarantine(t, `{"location_id":1,"co2":-5,"temp":21}`)
+	api := tests.NewMemoryAPIEnv(store)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/:id/replay", route+"/replay", api.ReplayQuarantinedCo2Data, []byte(`{"location_name":"kitchen","co2":650,"temp":21}`))
+
+	response := models.Co2DataDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	stored, err := store.Co2Data().GetByTimeFrame("1", time.Hour, false)
+	require.NoError(t, err)
+	kept, err := store.Quarantine().GetAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	assert.Equal(t, 650, response.CO2)
+	assert.Len(t, stored, 1)
+	assert.Empty(t, kept)
+}
+
+func TestReplayQuarantinedCo2Data_ShouldRejectStoredPayloadOutOfRange(t *testing.T) {
+	store, route := setupQuarantine(t, `{"location_id":1,"co2":-5,"temp":21}`)
+	api := tests.NewMemoryAPIEnv(store)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/:id/replay", route+"/replay", api.ReplayQuarantinedCo2Data, nil)
+
+	response := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	kept, err := store.Quarantine().GetAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, problem.CodeValidationFailed, response.Code)
+	assert.Equal(t, []models.FieldErrorDto{{Pointer: "/co2", Detail: "co2 has to be between 0 and 40000 ppm, got -5."}}, response.Errors)
+	assert.Len(t, kept, 1)
+}
+
+func TestReplayQuarantinedCo2Data_ShouldReplayStoredPayload(t *testing.T) {
+	store, route := setupQuarantine(t, `{"location_id":1,"co2":650,"temp":21}`)
+	api := tests.NewMemoryAPIEnv(store)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/:id/replay", route+"/replay", api.ReplayQuarantinedCo2Data, nil)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+}
+
+func TestReplayQuarantinedCo2Data_ShouldRejectUnknownLocation(t *testing.T) {
+	store, route := setupQuarantine(t, `{"location_id":99,"co2":650,"temp":21}`)
+	api := tests.NewMemoryAPIEnv(store)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/:id/replay", route+"/replay", api.ReplayQuarantinedCo2Data, nil)
+
+	response := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+
+	assert.Equal(t, http.StatusBadRequest, writer.Code)
+	assert.Equal(t, problem.CodeUnknownReference, response.Code)
+}
+
+func TestReplayQuarantinedCo2Data_ShouldKeepTimeOfIngest(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	_, err := store.Locations().Create([]models.Location{{Name: "kitchen"}})
+	require.NoError(t, err)
+	postedAt := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	quarantined, err := store.Quarantine().Create([]models.QuarantinedCo2Data{{
+		Model:   gorm.Model{CreatedAt: postedAt},
+		Payload: []byte(`{"location_id":1,"co2":650,"temp":21}`),
+		Code:    problem.CodeUnknownReference,
+	}})
+	require.NoError(t, err)
+	api := tests.NewMemoryAPIEnv(store)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/:id/replay", fmt.Sprintf("/%d/replay", quarantined[0].ID), api.ReplayQuarantinedCo2Data, nil)
+
+	response := models.Co2DataDto{}
+	require.NoError(t, json.Unmarshal(writer.Body.Bytes(), &response))
+	stored, err := store.Co2Data().GetLatest("1", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, writer.Code)
+	assert.True(t, postedAt.Equal(response.CreatedAt))
+	assert.True(t, postedAt.Equal(stored.CreatedAt))
+}
+
+func TestReplayQuarantinedCo2Data_ShouldStoreEntryOnlyOnce(t *testing.T) {
+	store, route := setupQuarantine(t, `{"location_id":1,"co2":650,"temp":21}`)
+	api := tests.NewMemoryAPIEnv(store)
+
+	_, first := tests.SetupRouter(nil, http.MethodPost, "/:id/replay", route+"/replay", api.ReplayQuarantinedCo2Data, nil)
+	_, second := tests.SetupRouter(nil, http.MethodPost, "/:id/replay", route+"/replay", api.ReplayQuarantinedCo2Data, nil)
+
+	response := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(second.Body.Bytes(), &response))
+	stored, err := store.Co2Data().GetByTimeFrame("1", time.Hour, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusNotFound, second.Code)
+	assert.Equal(t, problem.CodeQuarantineNotFound, response.Code)
+	assert.Len(t, stored, 1)
+}
+
+func TestReplayQuarantinedCo2Data_ShouldKeepEntryIfStoringFails(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	defer f.Teardown(t)
+	require.NoError(t, f.Db.Create(&models.Location{Name: "kitchen"}).Error)
+	quarantined := models.QuarantinedCo2Data{Payload: []byte(`{"location_id":1,"co2":650,"temp":21}`), Code: problem.CodeQuarantined}
+	require.NoError(t, f.Db.Create(&quarantined).Error)
+	require.NoError(t, f.Db.Migrator().DropTable(&models.Co2Data{}))
+	api := tests.NewAPIEnv(f.Db)
+
+	_, writer := tests.SetupRouter(nil, http.MethodPost, "/:id/replay", fmt.Sprintf("/%d/replay", quarantined.ID), api.ReplayQuarantinedCo2Data, nil)
+
+	kept, err := api.Quarantine.GetAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, writer.Code)
+	assert.Len(t, kept, 1)
+}
+
+func TestDiscardQuarantinedCo2Data_ShouldRemoveEntry(t *testing.T) {
+	store, route := setupQuarantine(t, `{"location_id":1,"co2":-5,"temp":21}`)
+	api := tests.NewMemoryAPIEnv(store)
+
+	_, writer := tests.SetupRouter(nil, http.MethodDelete, "/:id", route, api.DiscardQuarantinedCo2Data, nil)
+
+	kept, err := store.Quarantine().GetAll()
+	require.NoError(t, err)
+	stored, err := store.Co2Data().GetByTimeFrame("1", time.Hour, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, writer.Code)
+	assert.Empty(t, kept)
+	assert.Empty(t, stored)
+}
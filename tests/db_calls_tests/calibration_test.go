@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/db/db_calls"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGetCalibrations_ShouldReturnNewestFirst(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+
+	calibrations := []models.Calibration{
+		{LocationID: 1, Co2Offset: 10, ValidFrom: time.Now().Add(-48 * time.Hour)},
+		{LocationID: 1, Co2Offset: 20, ValidFrom: time.Now().Add(-24 * time.Hour)},
+		{LocationID: 2, Co2Offset: 30, ValidFrom: time.Now().Add(-24 * time.Hour)},
+	}
+	f.Db.Create(&calibrations)
+	result, err := db_calls.GetCalibrations(f.Db, "1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, calibrations[1].Co2Offset, result[0].Co2Offset)
+	assert.Equal(t, calibrations[0].Co2Offset, result[1].Co2Offset)
+	assert.Equal(t, float32(1), result[0].Co2Scale)
+}
+
+func TestCreateCalibration_ShouldNotCreateCalibration(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	defer f.Teardown(t)
+
+	errorMessage := "Empty list of calibrations to insert"
+	result, err := db_calls.CreateCalibration(f.Db, []models.Calibration{})
+
+	assert.Equal(t, errorMessage, err.Error())
+	assert.Equal(t, 0, len(result))
+}
+
+func TestDeleteCalibration_ShouldDeleteCalibration(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+
+	calibrations := []models.Calibration{{LocationID: 1, Co2Offset: 10, ValidFrom: time.Now()}}
+	f.Db.Create(&calibrations)
+	err := db_calls.DeleteCalibration(f.Db, models.Calibration{Model: gorm.Model{ID: calibrations[0].ID}})
+	result, errAfterDelete := db_calls.GetCalibrations(f.Db, "1")
+
+	require.NoError(t, err)
+	require.NoError(t, errAfterDelete)
+	assert.Equal(t, 0, len(result))
+}
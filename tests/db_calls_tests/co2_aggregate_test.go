@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/db/db_calls"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func addAggregateData(t *testing.T, f tests.BaseFixture) time.Time {
+	base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	newData := []models.Co2Data{
+		{Model: gorm.Model{CreatedAt: base.Add(10 * time.Minute)}, CO2: 600, Temp: 20, LocationID: 1},
+		{Model: gorm.Model{CreatedAt: base.Add(20 * time.Minute)}, CO2: 800, Temp: 22, LocationID: 1},
+		{Model: gorm.Model{CreatedAt: base.Add(30 * time.Minute)}, CO2: 12000, Temp: 22, LocationID: 1, Quality: models.QualityOutOfRange},
+		{Model: gorm.Model{CreatedAt: base.Add(70 * time.Minute)}, CO2: 1000, Temp: 23, LocationID: 1},
+		{Model: gorm.Model{CreatedAt: base.Add(70 * time.Minute)}, CO2: 400, Temp: 19, LocationID: 2},
+	}
+	require.NoError(t, f.Db.Create(&newData).Error)
+
+	return base
+}
+
+func TestGetCo2DataAggregates_ShouldReturnHourlyAggregates(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	base := addAggregateData(t, f)
+
+	aggregates, err := db_calls.GetCo2DataAggregates(f.Db, "1", models.AggregateHour, time.Now().Add(-3*time.Hour), false)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, len(aggregates))
+	assert.Equal(t, base, aggregates[0].Bucket)
+	assert.Equal(t, 3, aggregates[0].Count)
+	assert.Equal(t, 600.0, aggregates[0].MinCO2)
+	assert.Equal(t, 12000.0, aggregates[0].MaxCO2)
+	assert.Equal(t, base.Add(time.Hour), aggregates[1].Bucket)
+	assert.Equal(t, 1000.0, aggregates[1].AvgCO2)
+	assert.Equal(t, 23.0, aggregates[1].AvgTemp)
+}
+
+func TestGetCo2DataAggregates_ShouldExcludeAnomalies(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	base := addAggregateData(t, f)
+
+	aggregates, err := db_calls.GetCo2DataAggregates(f.Db.Scopes(db_calls.ExcludeAnomalies), "1", models.AggregateHour, time.Now().Add(-3*time.Hour), false)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, len(aggregates))
+	assert.Equal(t, base, aggregates[0].Bucket)
+	assert.Equal(t, 2, aggregates[0].Count)
+	assert.Equal(t, 700.0, aggregates[0].AvgCO2)
+	assert.Equal(t, 21.0, aggregates[0].AvgTemp)
+	assert.Equal(t, 800.0, aggregates[0].MaxCO2)
+}
+
+func TestGetCo2DataAggregates_ShouldReturnDailyAggregates(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	base := addAggregateData(t, f)
+
+	aggregates, err := db_calls.GetCo2DataAggregates(f.Db.Scopes(db_calls.ExcludeAnomalies), "1", models.AggregateDay, base, false)
+
+	require.NoError(t, err)
+	count := 0
+	for _, aggregate := range aggregates {
+		assert.Equal(t, aggregate.Bucket, aggregate.Bucket.Truncate(24*time.Hour))
+		count += aggregate.Count
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestGetCo2DataAggregates_ShouldReturnErrorUnknownInterval(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	defer f.Teardown(t)
+
+	_, err := db_calls.GetCo2DataAggregates(f.Db, "1", "week", time.Now(), false)
+
+	assert.EqualError(t, err, `unknown aggregate interval "week"`)
+}
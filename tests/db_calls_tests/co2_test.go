@@ -160,3 +160,57 @@ func TestCreateCo2Data_ShouldNotCreateValue(t *testing.T) {
 	assert.Equal(t, 0, len(result))
 
 }
+
+func TestGetAnomalies_ShouldReturnFlaggedValues(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+
+	newData := []models.Co2Data{
+		{
+			Model: gorm.Model{
+				CreatedAt: time.Now().Add(-1 * time.Hour),
+				UpdatedAt: time.Now().Add(-1 * time.Hour),
+			},
+			CO2:        400,
+			Temp:       24.5,
+			LocationID: 1,
+			Quality:    models.QualityStuck,
+		},
+		{
+			Model: gorm.Model{
+				CreatedAt: time.Now().Add(-1 * time.Hour),
+				UpdatedAt: time.Now().Add(-1 * time.Hour),
+			},
+			CO2:        505,
+			Temp:       25.5,
+			LocationID: 1,
+			Quality:    models.QualityOk,
+		},
+	}
+	f.Db.Create(&newData)
+	anomalies, err := db_calls.GetAnomalies(f.Db, "1", 24*time.Hour)
+	withoutAnomalies, errWithout := db_calls.GetCo2DataByTimeFrame(f.Db.Scopes(db_calls.ExcludeAnomalies), "1", 24*time.Hour)
+
+	require.NoError(t, err)
+	require.NoError(t, errWithout)
+	assert.Equal(t, 1, len(anomalies))
+	assert.Equal(t, models.QualityStuck, anomalies[0].Quality)
+	assert.Equal(t, 1, len(withoutAnomalies))
+	assert.Equal(t, newData[1].CO2, withoutAnomalies[0].CO2)
+}
+
+func TestGetLatestCo2DataPerLocation_ShouldReturnLastValueOfEachLocation(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+
+	result, err := db_calls.GetLatestCo2DataPerLocation(f.Db)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, tests.CO2[0].CO2, result[0].CO2)
+	assert.Equal(t, tests.CO2[2].CO2, result[1].CO2)
+}
@@ -77,6 +77,32 @@ func TestGetLocationBySearch_ShouldReturnNoneByIdAndName(t *testing.T) {
 	assert.Equal(t, 0, len(result))
 }
 
+func TestGetLocationsByIdsOrNames_ShouldLeaveOutDeletedLocations(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	require.NoError(t, f.Db.Delete(&models.Location{}, 1).Error)
+
+	result, err := db_calls.GetLocationsByIdsOrNames(f.Db, []int{1, 3}, []string{tests.Locations[0].Name, tests.Locations[1].Name})
+
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result))
+	assert.Equal(t, tests.Locations[1].Name, result[0].Name)
+}
+
+func TestGetLocationsByIdsOrNames_ShouldReturnNoneWithoutIdsAndNames(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+
+	result, err := db_calls.GetLocationsByIdsOrNames(f.Db, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(result))
+}
+
 func TestCreateLocation_ShouldCreateSingleLocation(t *testing.T) {
 	f := tests.BaseFixture{}
 	f.Setup(t)
@@ -7,6 +7,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
+	"github.com/fminister/co2monitor.api/db"
+	"github.com/fminister/co2monitor.api/db/migrations"
 	"github.com/fminister/co2monitor.api/models"
 )
 
@@ -16,13 +18,30 @@ type BaseFixture struct {
 
 func (f *BaseFixture) Setup(t *testing.T) {
 	var err error
-	f.Db, err = gorm.Open(sqlite.Open(":memory:?_pragma=foreign_keys(1)"), &gorm.Config{})
+	f.Db, err = gorm.Open(sqlite.Open(":memory:?_pragma=foreign_keys(1)"), &gorm.Config{TranslateError: true})
+	require.NoError(t, err)
+	require.NoError(t, db.UseUTC(f.Db))
+	migrator, err := migrations.New(f.Db)
+	require.NoError(t, err)
+	_, err = migrator.Up()
 	require.NoError(t, err)
-	f.Db.AutoMigrate(&models.Location{}, &models.Co2Data{})
 }
 
+// Teardown removes the rows of every table, the ones referencing locations
+// first.
 func (f *BaseFixture) Teardown(t *testing.T) {
-	f.Db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Location{}, &models.Co2Data{})
+	session := f.Db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped()
+	for _, model := range []interface{}{&models.Co2Data{}, &models.Calibration{}, &models.QuarantinedCo2Data{}, &models.Location{}} {
+		session.Delete(model)
+	}
+}
+
+// CloseDb closes the connection, so every query fails like it would with an
+// unreachable database.
+func (f *BaseFixture) CloseDb(t *testing.T) {
+	sqlDb, err := f.Db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDb.Close())
 }
 
 func (f *BaseFixture) AddDummyData(t *testing.T) {
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/db"
+	"github.com/fminister/co2monitor.api/db/db_calls"
+	"github.com/fminister/co2monitor.api/db/migrations"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func connectToSqlite(t *testing.T) *gorm.DB {
+	previous := config.Get()
+	cfg := config.Default()
+	cfg.Database.Driver = config.DriverSqlite
+	cfg.Database.SqlitePath = filepath.Join(t.TempDir(), "data", "co2monitor.db")
+	config.Set(cfg)
+
+	db.ConnectToDb()
+	t.Cleanup(func() {
+		db.CloseDb()
+		config.Set(previous)
+	})
+
+	migrator, err := migrations.New(db.GetDB())
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	return db.GetDB()
+}
+
+func TestConnectToDb_ShouldOpenSqliteInWalModeWithoutSchemaPrefix(t *testing.T) {
+	sqliteDb := connectToSqlite(t)
+
+	journalMode := ""
+	require.NoError(t, sqliteDb.Raw("PRAGMA journal_mode").Scan(&journalMode).Error)
+	foreignKeys := 0
+	require.NoError(t, sqliteDb.Raw("PRAGMA foreign_keys").Scan(&foreignKeys).Error)
+
+	assert.Equal(t, "sqlite", sqliteDb.Dialector.Name())
+	assert.Equal(t, "wal", journalMode)
+	assert.Equal(t, 1, foreignKeys)
+	assert.True(t, sqliteDb.Migrator().HasTable("co2_data"))
+	assert.True(t, sqliteDb.Migrator().HasTable("locations"))
+}
+
+func TestConnectToDb_ShouldCompareTimesWithDifferentOffsetsOnSqlite(t *testing.T) {
+	sqliteDb := connectToSqlite(t)
+	location := models.Location{Name: "Raspberry Pi"}
+	require.NoError(t, sqliteDb.Create(&location).Error)
+	ahead := time.FixedZone("UTC+5", 5*60*60)
+	co2Data := []models.Co2Data{
+		{Model: gorm.Model{CreatedAt: time.Now().Add(-30 * time.Minute).In(ahead)}, CO2: 600, Temp: 21, LocationID: int(location.ID)},
+		{Model: gorm.Model{CreatedAt: time.Now().Add(-3 * time.Hour).In(ahead)}, CO2: 650, Temp: 21, LocationID: int(location.ID)},
+	}
+	_, err := db_calls.CreateCo2Data(sqliteDb, co2Data)
+	require.NoError(t, err)
+
+	lastHour, err := db_calls.GetCo2DataByTimeFrame(sqliteDb, "1", time.Hour)
+	require.NoError(t, err)
+	latest, err := db_calls.GetLatestCo2DataPerLocation(sqliteDb)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, len(lastHour))
+	assert.Equal(t, 600, lastHour[0].CO2)
+	assert.Equal(t, 1, len(latest))
+	assert.Equal(t, 600, latest[0].CO2)
+}
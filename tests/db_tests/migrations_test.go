@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/fminister/co2monitor.api/db/migrations"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func openEmptySqlite(t *testing.T) *gorm.DB {
+	emptyDb, err := gorm.Open(sqlite.Open(":memory:?_pragma=foreign_keys(1)"), &gorm.Config{})
+	require.NoError(t, err)
+	return emptyDb
+}
+
+func TestMigrator_ShouldApplyAllMigrationsOnce(t *testing.T) {
+	emptyDb := openEmptySqlite(t)
+	migrator, err := migrations.New(emptyDb)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	appliedAgain, err := migrator.Up()
+	require.NoError(t, err)
+	pending, err := migrator.Pending()
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, len(applied))
+	assert.Equal(t, 0, len(appliedAgain))
+	assert.Equal(t, 0, len(pending))
+	assert.True(t, emptyDb.Migrator().HasTable(&models.Calibration{}))
+	assert.True(t, emptyDb.Migrator().HasTable(&models.QuarantinedCo2Data{}))
+	assert.True(t, emptyDb.Migrator().HasIndex(&models.Co2Data{}, "idx_co2_data_location_id_created_at"))
+}
+
+func TestMigrator_ShouldRevertNewestMigrationsFirst(t *testing.T) {
+	emptyDb := openEmptySqlite(t)
+	migrator, err := migrations.New(emptyDb)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	reverted, err := migrator.Down(1)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(reverted))
+	assert.Equal(t, 3, reverted[0].Version)
+	assert.False(t, emptyDb.Migrator().HasTable(&models.QuarantinedCo2Data{}))
+
+	reverted, err = migrator.Down(1)
+	require.NoError(t, err)
+	status, err := migrator.Status()
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(reverted))
+	assert.Equal(t, 2, reverted[0].Version)
+	assert.False(t, emptyDb.Migrator().HasIndex(&models.Co2Data{}, "idx_co2_data_location_id_created_at"))
+	assert.True(t, emptyDb.Migrator().HasTable(&models.Co2Data{}))
+	assert.True(t, status[0].Applied)
+	assert.NotNil(t, status[0].AppliedAt)
+	assert.False(t, status[1].Applied)
+	assert.False(t, status[2].Applied)
+
+	reverted, err = migrator.Down(5)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, len(reverted))
+	assert.False(t, emptyDb.Migrator().HasTable(&models.Co2Data{}))
+	assert.False(t, emptyDb.Migrator().HasTable(&models.Location{}))
+}
+
+func TestMigrator_ShouldKeepDataOfDatabaseCreatedByAutoMigrate(t *testing.T) {
+	emptyDb := openEmptySqlite(t)
+	require.NoError(t, emptyDb.AutoMigrate(&models.Location{}, &models.Co2Data{}, &models.Calibration{}))
+	require.NoError(t, emptyDb.Create(&models.Location{Name: "Kitchen"}).Error)
+	migrator, err := migrations.New(emptyDb)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	var locations []models.Location
+	require.NoError(t, emptyDb.Find(&locations).Error)
+
+	assert.Equal(t, 3, len(applied))
+	assert.Equal(t, 1, len(locations))
+	assert.True(t, emptyDb.Migrator().HasIndex(&models.Co2Data{}, "idx_co2_data_location_id_created_at"))
+}
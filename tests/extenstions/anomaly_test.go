@@ -0,0 +1,81 @@
+package extensions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+func reading(locationId int, createdAt time.Time, co2 int, temp float32) models.Co2Data {
+	return models.Co2Data{
+		Model:      gorm.Model{CreatedAt: createdAt},
+		LocationID: locationId,
+		CO2:        co2,
+		Temp:       temp,
+		Quality:    models.QualityOk,
+	}
+}
+
+func flatHistory(locationId int, end time.Time, duration time.Duration, co2 int) []models.Co2Data {
+	history := []models.Co2Data{}
+	for createdAt := end.Add(-duration); createdAt.Before(end); createdAt = createdAt.Add(30 * time.Minute) {
+		history = append(history, reading(locationId, createdAt, co2, 21))
+	}
+
+	return history
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	rules := ex.DefaultAnomalyRules
+	recent := []models.Co2Data{reading(1, now.Add(-time.Minute), 800, 21)}
+
+	tests := []struct {
+		name     string
+		history  []models.Co2Data
+		reading  models.Co2Data
+		expected string
+	}{
+		{"plausible reading", recent, reading(1, now, 820, 21), models.QualityOk},
+		{"co2 too high", recent, reading(1, now, 12000, 21), models.QualityOutOfRange},
+		{"co2 too low", recent, reading(1, now, 100, 21), models.QualityOutOfRange},
+		{"temperature too high", recent, reading(1, now, 800, 900), models.QualityOutOfRange},
+		{"jump", recent, reading(1, now, 3500, 21), models.QualityJump},
+		{"large change after a gap", []models.Co2Data{reading(1, now.Add(-time.Hour), 800, 21)}, reading(1, now, 3500, 21), models.QualityOk},
+		{"jump of another location", []models.Co2Data{reading(2, now.Add(-time.Minute), 800, 21)}, reading(1, now, 3500, 21), models.QualityOk},
+		{"stuck", flatHistory(1, now, 7*time.Hour, 600), reading(1, now, 600, 21), models.QualityStuck},
+		{"flat for a short time", flatHistory(1, now, 2*time.Hour, 600), reading(1, now, 600, 21), models.QualityOk},
+		{"below outdoor baseline", recent, reading(1, now, 300, 21), models.QualityDrift},
+		{"never reaches outdoor baseline", flatHistory(1, now, 24*time.Hour, 700), reading(1, now, 720, 21), models.QualityDrift},
+		{"reaches outdoor baseline", append(flatHistory(1, now, 24*time.Hour, 700), reading(1, now.Add(-3*time.Hour), 430, 21)), reading(1, now, 720, 21), models.QualityOk},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := ex.DetectAnomalies(test.history, []models.Co2Data{test.reading}, rules)
+
+			assert.Equal(t, test.expected, result[0].Quality)
+		})
+	}
+}
+
+func TestDetectAnomalies_ShouldKeepOrderAndCompareWithinBatch(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	co2Data := []models.Co2Data{
+		reading(1, now.Add(time.Minute), 5000, 21),
+		reading(1, now, 800, 21),
+		reading(1, now.Add(2*time.Minute), 810, 21),
+	}
+
+	result := ex.DetectAnomalies(nil, co2Data, ex.DefaultAnomalyRules)
+
+	assert.Equal(t, 5000, result[0].CO2)
+	assert.Equal(t, models.QualityJump, result[0].Quality)
+	assert.Equal(t, models.QualityOk, result[1].Quality)
+	assert.Equal(t, models.QualityOk, result[2].Quality)
+}
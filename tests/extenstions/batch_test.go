@@ -0,0 +1,44 @@
+package extensions_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+func TestDecodeBatch_ShouldDecodeItems(t *testing.T) {
+	items, err := ex.DecodeBatch[models.Location](strings.NewReader(` [{"name": "kitchen"}, {"name": "office"}] `), 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kitchen", "office"}, []string{items[0].Name, items[1].Name})
+}
+
+func TestDecodeBatch_ShouldStopAtMaxItems(t *testing.T) {
+	// the third item is broken, it must not be read
+	_, err := ex.DecodeBatch[models.Location](strings.NewReader(`[{"name": "a"}, {"name": "b"}, {"name": `), 2)
+
+	var tooLong *ex.BatchTooLongError
+	require.ErrorAs(t, err, &tooLong)
+	assert.Equal(t, 2, tooLong.Max)
+}
+
+func TestDecodeBatch_ShouldReturnErrorInvalidBody(t *testing.T) {
+	for name, body := range map[string]string{
+		"object":       `{"name": "kitchen"}`,
+		"broken item":  `[{"name": 1}]`,
+		"unterminated": `[{"name": "kitchen"}`,
+		"trailing":     `[] []`,
+		"empty":        ``,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ex.DecodeBatch[models.Location](strings.NewReader(body), 0)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package extensions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+func TestApplyCalibrations(t *testing.T) {
+	start := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	calibrations := []models.Calibration{
+		{LocationID: 1, Co2Offset: -50, Co2Scale: 1.1, TempOffset: -0.5, TempScale: 1, ValidFrom: start, ValidTo: &end},
+		{LocationID: 1, Co2Offset: 20, ValidFrom: end},
+		{LocationID: 1, Co2Offset: 30, ValidFrom: end.Add(time.Hour)},
+		{LocationID: 2, Co2Offset: 1000, ValidFrom: start},
+	}
+
+	tests := []struct {
+		name               string
+		reading            models.Co2Data
+		expectedCO2        int
+		expectedTemp       float32
+		expectedCalibrated bool
+	}{
+		{"before any calibration", reading(1, start.Add(-time.Hour), 500, 20), 500, 20, false},
+		{"offset and scale", reading(1, start.Add(time.Hour), 500, 20), 500, 19.5, true},
+		{"missing scale", reading(1, end.Add(30*time.Minute), 500, 20), 520, 20, true},
+		{"newest calibration wins", reading(1, end.Add(2*time.Hour), 500, 20), 530, 20, true},
+		{"other location", reading(3, start.Add(time.Hour), 500, 20), 500, 20, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := ex.ApplyCalibrations([]models.Co2Data{test.reading}, calibrations)
+
+			assert.Equal(t, test.expectedCO2, result[0].CO2)
+			assert.InDelta(t, test.expectedTemp, result[0].Temp, 0.001)
+			assert.Equal(t, test.expectedCalibrated, result[0].Calibrated)
+		})
+	}
+}
+
+func TestApplyCalibrationsToAggregates(t *testing.T) {
+	start := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	calibrations := []models.Calibration{
+		{LocationID: 1, Co2Offset: -50, Co2Scale: 2, TempOffset: 1, ValidFrom: start},
+	}
+	aggregates := []models.Co2Aggregate{
+		{LocationID: 1, Bucket: start.Add(-time.Hour), AvgCO2: 500, MinCO2: 400, MaxCO2: 600, AvgTemp: 20},
+		{LocationID: 1, Bucket: start, AvgCO2: 500, MinCO2: 400, MaxCO2: 600, AvgTemp: 20},
+	}
+
+	result := ex.ApplyCalibrationsToAggregates(aggregates, calibrations)
+
+	assert.False(t, result[0].Calibrated)
+	assert.Equal(t, 500.0, result[0].AvgCO2)
+	assert.True(t, result[1].Calibrated)
+	assert.Equal(t, 950.0, result[1].AvgCO2)
+	assert.Equal(t, 750.0, result[1].MinCO2)
+	assert.Equal(t, 1150.0, result[1].MaxCO2)
+	assert.Equal(t, 21.0, result[1].AvgTemp)
+}
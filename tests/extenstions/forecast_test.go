@@ -0,0 +1,99 @@
+package extensions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+func risingReadings(start time.Time, count int, base int, slopePerMinute int) []models.Co2Data {
+	values := make([]int, count)
+	for i := range values {
+		values[i] = base + i*slopePerMinute
+	}
+
+	return readings(start, time.Minute, values...)
+}
+
+func TestForecastCo2_ShouldFollowLinearRise(t *testing.T) {
+	start := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, model := range []string{ex.ForecastModelHolt, ex.ForecastModelLinear} {
+		t.Run(model, func(t *testing.T) {
+			co2Data := risingReadings(start, 30, 500, 10)
+			latest := co2Data[len(co2Data)-1]
+
+			forecast, ok := ex.ForecastCo2(co2Data, model, time.Hour, 1000)
+
+			assert.True(t, ok)
+			assert.Equal(t, model, forecast.Model)
+			assert.Equal(t, 60, forecast.HorizonMinutes)
+			assert.InDelta(t, 10, forecast.SlopePerMinute, 0.01)
+			assert.Equal(t, 12, len(forecast.Predictions))
+			assert.Equal(t, latest.CreatedAt.Add(5*time.Minute), forecast.Predictions[0].Time)
+			assert.InDelta(t, 840, forecast.Predictions[0].CO2, 1)
+			assert.Equal(t, latest.CreatedAt.Add(time.Hour), forecast.Predictions[11].Time)
+			assert.InDelta(t, 1390, forecast.Predictions[11].CO2, 1)
+			assert.Equal(t, 21, *forecast.MinutesUntilThreshold)
+			assert.Equal(t, latest.CreatedAt.Add(21*time.Minute), *forecast.ThresholdCrossingAt)
+		})
+	}
+}
+
+func TestForecastCo2_ShouldNotCrossThresholdWhenFlat(t *testing.T) {
+	co2Data := risingReadings(time.Now(), 20, 600, 0)
+
+	forecast, ok := ex.ForecastCo2(co2Data, ex.ForecastModelHolt, 2*time.Hour, 1000)
+
+	assert.True(t, ok)
+	assert.Equal(t, 24, len(forecast.Predictions))
+	assert.Nil(t, forecast.MinutesUntilThreshold)
+	assert.Nil(t, forecast.ThresholdCrossingAt)
+	for _, prediction := range forecast.Predictions {
+		assert.Equal(t, 600, prediction.CO2)
+	}
+}
+
+func TestForecastCo2_ShouldNotCrossThresholdBeyondHorizon(t *testing.T) {
+	co2Data := risingReadings(time.Now(), 20, 500, 1)
+
+	forecast, ok := ex.ForecastCo2(co2Data, ex.ForecastModelLinear, 30*time.Minute, 1000)
+
+	assert.True(t, ok)
+	assert.Equal(t, 6, len(forecast.Predictions))
+	assert.Nil(t, forecast.MinutesUntilThreshold)
+}
+
+func TestForecastCo2_ShouldNotPredictNegativeValues(t *testing.T) {
+	co2Data := risingReadings(time.Now(), 10, 600, -50)
+
+	forecast, ok := ex.ForecastCo2(co2Data, ex.ForecastModelLinear, time.Hour, 1000)
+
+	assert.True(t, ok)
+	assert.Equal(t, 0, forecast.Predictions[len(forecast.Predictions)-1].CO2)
+}
+
+func TestForecastCo2_ShouldSmoothNoisyReadings(t *testing.T) {
+	co2Data := readings(time.Now(), time.Minute, 500, 530, 510, 540, 520, 550, 530, 560, 540, 570)
+
+	forecast, ok := ex.ForecastCo2(co2Data, ex.ForecastModelHolt, 10*time.Minute, 1000)
+
+	assert.True(t, ok)
+	assert.InDelta(t, 6, forecast.SlopePerMinute, 4)
+}
+
+func TestForecastCo2_ShouldFailWithUnknownModel(t *testing.T) {
+	_, ok := ex.ForecastCo2(risingReadings(time.Now(), 5, 500, 1), "lienar", time.Hour, 1000)
+
+	assert.False(t, ok)
+}
+
+func TestForecastCo2_ShouldFailWithSingleReading(t *testing.T) {
+	_, ok := ex.ForecastCo2(risingReadings(time.Now(), 1, 500, 0), ex.ForecastModelHolt, time.Hour, 1000)
+
+	assert.False(t, ok)
+}
@@ -0,0 +1,92 @@
+package extensions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+func TestETag(t *testing.T) {
+	first, err := ex.ETag(models.Co2DataDto{ID: 1, CO2: 800})
+	require.NoError(t, err)
+	same, err := ex.ETag(models.Co2DataDto{ID: 1, CO2: 800})
+	require.NoError(t, err)
+	other, err := ex.ETag(models.Co2DataDto{ID: 1, CO2: 801})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, same)
+	assert.NotEqual(t, first, other)
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, first)
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`"xyz"`, false},
+		{"*", true},
+		{"abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ifNoneMatch, func(t *testing.T) {
+			assert.Equal(t, tt.want, ex.ETagMatches(tt.ifNoneMatch, etag))
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2023, 8, 1, 12, 30, 0, 500, time.UTC)
+	tests := []struct {
+		name            string
+		ifNoneMatch     string
+		ifModifiedSince string
+		want            bool
+	}{
+		{"no conditional headers", "", "", false},
+		{"matching etag", `"abc"`, "", true},
+		{"etag wins over date", `"xyz"`, "Tue, 01 Aug 2023 12:30:00 GMT", false},
+		{"not modified since", "", "Tue, 01 Aug 2023 12:30:00 GMT", true},
+		{"modified since", "", "Tue, 01 Aug 2023 12:29:59 GMT", false},
+		{"invalid date", "", "yesterday", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ex.NotModified(tt.ifNoneMatch, tt.ifModifiedSince, `"abc"`, lastModified))
+		})
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	start := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	co2Data := []models.Co2Data{
+		{Model: gorm.Model{UpdatedAt: start}},
+		{Model: gorm.Model{UpdatedAt: start.Add(time.Hour)}},
+		{Model: gorm.Model{UpdatedAt: start.Add(time.Minute)}},
+	}
+	updatedAt := func(data models.Co2Data) time.Time { return data.UpdatedAt }
+
+	assert.Equal(t, start.Add(time.Hour), ex.LastModified(co2Data, updatedAt))
+	assert.True(t, ex.LastModified([]models.Co2Data{}, updatedAt).IsZero())
+}
+
+func TestWindowCacheControl(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, ex.LiveCacheControl, ex.WindowCacheControl(now, now))
+	assert.Equal(t, ex.LiveCacheControl, ex.WindowCacheControl(now.Add(-time.Minute), now))
+	assert.Equal(t, "private, max-age=60", ex.WindowCacheControl(now.Add(-time.Hour), now))
+}
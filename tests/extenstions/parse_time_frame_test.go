@@ -9,27 +9,83 @@ import (
 	ex "github.com/fminister/co2monitor.api/extensions"
 )
 
-func TestValidateTimeDuration(t *testing.T) {
+func TestParsePeriod(t *testing.T) {
 	tests := []struct {
-		input        string
-		expectedDur  time.Duration
-		expectErrMsg bool
+		input       string
+		expectedDur time.Duration
 	}{
-		{"10m", 10 * time.Minute, false},
-		{"5h", 5 * time.Hour, false},
-		{"3d", 3 * 24 * time.Hour, false},
-		{"abc", 6 * time.Hour, true},
-		{"2w", 6 * time.Hour, true},
-		{"2 h", 6 * time.Hour, true},
-		{"20", 6 * time.Hour, true},
+		{"30s", 30 * time.Second},
+		{"10m", 10 * time.Minute},
+		{"5h", 5 * time.Hour},
+		{"3d", 3 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"1w2d3h4m5s", 9*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second},
+		{"P7D", 7 * 24 * time.Hour},
+		{"P2W", 14 * 24 * time.Hour},
+		{"PT1H30M", 90 * time.Minute},
+		{"P1DT12H", 36 * time.Hour},
 	}
 
 	for _, test := range tests {
 		t.Run(test.input, func(t *testing.T) {
-			dur := ex.ValidateTimeDuration(test.input)
+			dur, err := ex.ParsePeriod(test.input, 0)
 
+			assert.NoError(t, err)
 			assert.Equal(t, test.expectedDur, dur)
-			assert.Equal(t, test.expectErrMsg, dur == 6*time.Hour)
 		})
 	}
 }
+
+func TestParsePeriod_ShouldRejectInvalidPeriods(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+	}{
+		{"", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"abc", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"2 h", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"20", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"-1h", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"1h1d", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"1h1h", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"1y", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"99999999999999w", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"P", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"PT", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"P1DT", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"p7d", "Use a positive duration like 30m, 6h, 1d12h, 2w or P7D."},
+		{"P1Y", "Years and months are not supported, use weeks or days, e.g. P30D."},
+		{"P1M", "Years and months are not supported, use weeks or days, e.g. P30D."},
+		{"0m", "The period has to be longer than 0."},
+		{"PT0S", "The period has to be longer than 0."},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, err := ex.ParsePeriod(test.input, 0)
+
+			var periodErr *ex.PeriodError
+			if assert.ErrorAs(t, err, &periodErr) {
+				assert.Equal(t, test.reason, periodErr.Reason)
+			}
+		})
+	}
+}
+
+func TestParsePeriod_ShouldEnforceMax(t *testing.T) {
+	_, err := ex.ParsePeriod("7d", 7*24*time.Hour)
+	assert.NoError(t, err)
+
+	_, err = ex.ParsePeriod("7d1s", 7*24*time.Hour)
+	var periodErr *ex.PeriodError
+	if assert.ErrorAs(t, err, &periodErr) {
+		assert.Equal(t, "The period must not be longer than 7d.", periodErr.Reason)
+	}
+}
+
+func TestFormatPeriod(t *testing.T) {
+	assert.Equal(t, "90d", ex.FormatPeriod(2160*time.Hour))
+	assert.Equal(t, "1d12h", ex.FormatPeriod(36*time.Hour))
+	assert.Equal(t, "1h30m15s", ex.FormatPeriod(90*time.Minute+15*time.Second))
+}
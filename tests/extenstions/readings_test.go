@@ -0,0 +1,48 @@
+package extensions_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+var readingRules = ex.ReadingRules{
+	CO2:  ex.ValueRange{Min: 0, Max: 40000, Unit: "ppm"},
+	Temp: ex.ValueRange{Min: -40, Max: 85, Unit: "°C"},
+}
+
+func TestReadingRules_ShouldAcceptValuesWithinRange(t *testing.T) {
+	assert.Empty(t, readingRules.Check(models.Co2Data{CO2: 0, Temp: 0}))
+	assert.Empty(t, readingRules.Check(models.Co2Data{CO2: 40000, Temp: -40}))
+	assert.Empty(t, readingRules.Check(models.Co2Data{CO2: 650, Temp: 85}))
+}
+
+func TestReadingRules_ShouldReturnErrorForValuesOutOfRange(t *testing.T) {
+	expected := []models.FieldErrorDto{
+		{Pointer: "/co2", Detail: "co2 has to be between 0 and 40000 ppm, got -5."},
+		{Pointer: "/temp", Detail: "temp has to be between -40 and 85 °C, got 900.5."},
+	}
+
+	assert.Equal(t, expected, readingRules.Check(models.Co2Data{CO2: -5, Temp: 900.5}))
+}
+
+func TestMissingFields_ShouldAcceptZeroValues(t *testing.T) {
+	assert.Empty(t, ex.MissingFields(json.RawMessage(`{"co2": 0, "temp": 0}`), "co2", "temp"))
+}
+
+func TestMissingFields_ShouldReturnErrorForMissingAndNullFields(t *testing.T) {
+	expected := []models.FieldErrorDto{
+		{Pointer: "/co2", Detail: "required"},
+		{Pointer: "/temp", Detail: "required"},
+	}
+
+	assert.Equal(t, expected, ex.MissingFields(json.RawMessage(`{"temp": null}`), "co2", "temp"))
+}
+
+func TestMissingFields_ShouldMatchKeysCaseInsensitively(t *testing.T) {
+	assert.Empty(t, ex.MissingFields(json.RawMessage(`{"Co2": 650, "TEMP": 21}`), "co2", "temp"))
+}
@@ -0,0 +1,35 @@
+package extensions_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+func TestValidationErrors_ShouldFlattenToSortedPointers(t *testing.T) {
+	result := map[string]interface{}{
+		"1": map[string]interface{}{
+			"location_id": []string{"required"},
+		},
+		"0": map[string]interface{}{
+			"name": []string{"required", "name's length must be higher equal to 3"},
+			"a/b":  []string{"required"},
+		},
+	}
+
+	expected := []models.FieldErrorDto{
+		{Pointer: "/0/a~1b", Detail: "required"},
+		{Pointer: "/0/name", Detail: "name's length must be higher equal to 3"},
+		{Pointer: "/0/name", Detail: "required"},
+		{Pointer: "/1/location_id", Detail: "required"},
+	}
+
+	assert.Equal(t, expected, ex.ValidationErrors(result))
+}
+
+func TestValidationErrors_ShouldReturnEmptyForUnknownResult(t *testing.T) {
+	assert.Empty(t, ex.ValidationErrors(nil))
+}
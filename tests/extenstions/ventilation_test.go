@@ -0,0 +1,78 @@
+package extensions_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	ex "github.com/fminister/co2monitor.api/extensions"
+	"github.com/fminister/co2monitor.api/models"
+)
+
+func readings(start time.Time, step time.Duration, values ...int) []models.Co2Data {
+	co2Data := make([]models.Co2Data, 0, len(values))
+	for i, value := range values {
+		co2Data = append(co2Data, models.Co2Data{
+			Model: gorm.Model{CreatedAt: start.Add(time.Duration(i) * step)},
+			CO2:   value,
+		})
+	}
+
+	return co2Data
+}
+
+func TestRecommendVentilation(t *testing.T) {
+	start := time.Date(2023, 8, 1, 12, 0, 0, 0, time.Local)
+	office := models.Location{Name: "office", RoomVolume: 60, Occupancy: 2}
+
+	tests := []struct {
+		name                  string
+		location              models.Location
+		co2Data               []models.Co2Data
+		expectedAdvice        string
+		expectedSource        string
+		expectedMinutesToNull bool
+		expectedMinutes       int
+	}{
+		{"above threshold", office, readings(start, time.Minute, 1100, 1120, 1140), ex.VentilateNow, ex.SlopeMeasured, false, 0},
+		{"threshold reached in 3 minutes", office, readings(start, time.Minute, 900, 920, 940), ex.VentilateNow, ex.SlopeMeasured, false, 3},
+		{"threshold reached in 30 minutes", office, readings(start, time.Minute, 600, 610, 620, 630, 640), ex.VentilateSoon, ex.SlopeMeasured, false, 36},
+		{"threshold reached in 120 minutes", office, readings(start, time.Minute, 600, 602, 604), ex.VentilationSufficient, ex.SlopeMeasured, false, 198},
+		{"falling co2", office, readings(start, time.Minute, 900, 850, 800), ex.VentilationSufficient, ex.SlopeMeasured, true, 0},
+		{"estimated from occupancy", office, readings(start, time.Minute, 800), ex.VentilateSoon, ex.SlopeEstimated, false, 20},
+		{"unknown slope", models.Location{Name: "hall"}, readings(start, time.Minute, 800), ex.VentilationSufficient, ex.SlopeUnknown, true, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recommendation := ex.RecommendVentilation(test.location, test.co2Data)
+
+			assert.Equal(t, test.expectedAdvice, recommendation.Recommendation)
+			assert.Equal(t, test.expectedSource, recommendation.SlopeSource)
+			assert.Equal(t, ex.VentilationThreshold, recommendation.Threshold)
+			if test.expectedMinutesToNull {
+				assert.Nil(t, recommendation.MinutesUntilThreshold)
+			} else {
+				assert.Equal(t, test.expectedMinutes, *recommendation.MinutesUntilThreshold)
+			}
+		})
+	}
+}
+
+func TestCo2Trend_ShouldFitLinearSlope(t *testing.T) {
+	co2Data := readings(time.Now(), 2*time.Minute, 500, 520, 540, 560)
+
+	slope, fitted, ok := ex.Co2Trend(co2Data)
+
+	assert.True(t, ok)
+	assert.InDelta(t, 10, slope, 0.0001)
+	assert.InDelta(t, 560, fitted, 0.0001)
+}
+
+func TestCo2Trend_ShouldFailWithSingleReading(t *testing.T) {
+	_, _, ok := ex.Co2Trend(readings(time.Now(), time.Minute, 500))
+
+	assert.False(t, ok)
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/controllers"
+	"github.com/fminister/co2monitor.api/db/migrations"
+	"github.com/fminister/co2monitor.api/health"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/fminister/co2monitor.api/workers"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGetLiveness_ShouldReturnOk(t *testing.T) {
+	api := &controllers.APIEnv{}
+	req, writer := tests.SetupRouter(nil, http.MethodGet, "/healthz", "/healthz", api.GetLiveness, nil)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := models.HealthDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, models.HealthOk, responseData.Status)
+	assert.NotEmpty(t, responseData.Uptime)
+}
+
+func TestGetReadiness_ShouldReturnOk(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	heartbeat := workers.NewHeartbeatWatcher(f.Db, 15*time.Minute, time.Hour)
+	heartbeat.Start(context.Background())
+	defer heartbeat.Stop()
+	api := &controllers.APIEnv{Health: &health.Checker{DB: f.Db, Heartbeat: heartbeat}}
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/readyz", "/readyz", api.GetReadiness, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := models.HealthDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, models.HealthOk, responseData.Status)
+	for _, name := range []string{"database", "migrations", "heartbeat_watcher", "ingestion"} {
+		assert.Equal(t, models.HealthOk, responseData.Checks[name].Status, name)
+	}
+}
+
+func TestGetReadiness_ShouldReturnDegradedWhenIngestionFails(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	heartbeat := workers.NewHeartbeatWatcher(f.Db, 15*time.Minute, time.Hour)
+	heartbeat.Start(context.Background())
+	defer heartbeat.Stop()
+	for i := 0; i < 3; i++ {
+		health.Ingest.Record(errors.New("database is locked"))
+	}
+	defer health.Ingest.Record(nil)
+	api := &controllers.APIEnv{Health: &health.Checker{DB: f.Db, Heartbeat: heartbeat}}
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/readyz", "/readyz", api.GetReadiness, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := models.HealthDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusOK, writer.Code, "HTTP request status code error")
+	assert.Equal(t, models.HealthDegraded, responseData.Status)
+	assert.Equal(t, models.HealthDegraded, responseData.Checks["ingestion"].Status)
+}
+
+func TestGetReadiness_ShouldReturnUnavailableWhenWatcherNotRunning(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/readyz", "/readyz", api.GetReadiness, nil)
+	defer f.Teardown(t)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := models.HealthDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code, "HTTP request status code error")
+	assert.Equal(t, models.HealthUnavailable, responseData.Status)
+	assert.Equal(t, models.HealthUnavailable, responseData.Checks["heartbeat_watcher"].Status)
+}
+
+func TestGetReadiness_ShouldReturnUnavailableWhenDatabaseIsClosed(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	sqlDb, _ := f.Db.DB()
+	sqlDb.Close()
+	api := tests.NewAPIEnv(f.Db)
+	req, writer := tests.SetupRouter(f.Db, http.MethodGet, "/readyz", "/readyz", api.GetReadiness, nil)
+
+	body, err := io.ReadAll(writer.Body)
+	if err != nil {
+		assert.Error(t, err)
+	}
+	responseData := models.HealthDto{}
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, http.MethodGet, req.Method, "HTTP request method error")
+	assert.Equal(t, http.StatusServiceUnavailable, writer.Code, "HTTP request status code error")
+	assert.Equal(t, models.HealthUnavailable, responseData.Checks["database"].Status)
+	assert.Equal(t, "database unreachable", responseData.Checks["database"].Detail)
+	assert.Equal(t, "database unreachable", responseData.Checks["migrations"].Detail)
+}
+
+func TestCheckMigrations_ShouldReturnUnavailableWhenMigrationsArePending(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	defer f.Teardown(t)
+	migrator, err := migrations.New(f.Db)
+	require.NoError(t, err)
+	_, err = migrator.Down(1)
+	require.NoError(t, err)
+
+	check := health.CheckMigrations(f.Db, log.Default())
+
+	assert.Equal(t, models.HealthUnavailable, check.Status)
+	assert.Equal(t, "1 migrations are pending, the first is 0003_quarantined_co2_data", check.Detail)
+}
+
+func TestCheckMigrations_ShouldNotCreateMigrationsTable(t *testing.T) {
+	emptyDb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	check := health.CheckMigrations(emptyDb, log.Default())
+
+	assert.Equal(t, models.HealthUnavailable, check.Status)
+	assert.Equal(t, "3 migrations are pending, the first is 0001_initial_schema", check.Detail)
+	assert.False(t, emptyDb.Migrator().HasTable("schema_migrations"))
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fminister/co2monitor.api/health"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIngestTracker_ShouldBeOkWithoutIngests(t *testing.T) {
+	tracker := &health.IngestTracker{}
+
+	assert.Equal(t, models.HealthOk, tracker.Check().Status)
+}
+
+func TestIngestTracker_ShouldBeDegradedAfterConsecutiveFailures(t *testing.T) {
+	tracker := &health.IngestTracker{}
+	tracker.Record(nil)
+	tracker.Record(errors.New("database is locked"))
+	tracker.Record(errors.New("database is locked"))
+	assert.Equal(t, models.HealthOk, tracker.Check().Status)
+
+	tracker.Record(errors.New("database is locked"))
+	check := tracker.Check()
+
+	assert.Equal(t, models.HealthDegraded, check.Status)
+	assert.Contains(t, check.Detail, "3 ingests failed in a row")
+	assert.NotContains(t, check.Detail, "database is locked")
+}
+
+func TestIngestTracker_ShouldRecoverAfterSuccess(t *testing.T) {
+	tracker := &health.IngestTracker{}
+	for i := 0; i < 5; i++ {
+		tracker.Record(errors.New("database is locked"))
+	}
+
+	tracker.Record(nil)
+
+	assert.Equal(t, models.HealthOk, tracker.Check().Status)
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure_ShouldWriteJSONWithCaller(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := log.New(buffer)
+	logging.Configure(logger, buffer, config.LogConfig{Level: "info", Format: config.LogFormatJSON})
+
+	logger.Info("Request handled.", "status", 200)
+
+	line := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &line))
+	assert.Equal(t, "info", line["lvl"])
+	assert.Equal(t, "Request handled.", line["msg"])
+	assert.Equal(t, float64(200), line["status"])
+	assert.Contains(t, line["caller"], "logging_test.go")
+	assert.NotEmpty(t, line["ts"])
+}
+
+func TestConfigure_ShouldDropLinesBelowLevel(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := log.New(buffer)
+	logging.Configure(logger, buffer, config.LogConfig{Level: "warn", Format: config.LogFormatJSON})
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	assert.NotContains(t, buffer.String(), "dropped")
+	assert.Contains(t, buffer.String(), "kept")
+}
+
+func TestRedactKey_ShouldHideKey(t *testing.T) {
+	redacted := logging.RedactKey("super-secret-admin-key")
+
+	assert.False(t, strings.Contains(redacted, "secret"))
+	assert.Regexp(t, `^sha256:[0-9a-f]{8}$`, redacted)
+	assert.Equal(t, redacted, logging.RedactKey("super-secret-admin-key"))
+	assert.NotEqual(t, redacted, logging.RedactKey("other-key"))
+	assert.Empty(t, logging.RedactKey(""))
+}
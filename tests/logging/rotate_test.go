@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_ShouldAppendToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gin.log")
+	require.NoError(t, os.WriteFile(path, []byte("previous run\n"), 0o644))
+
+	file, err := logging.NewRotatingFile(path, 1024, time.Hour, 3)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("this run\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous run\nthis run\n", string(content))
+}
+
+func TestRotatingFile_ShouldRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gin.log")
+	file, err := logging.NewRotatingFile(path, 10, 0, 0)
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("12345678\n"))
+	require.NoError(t, err)
+	_, err = file.Write([]byte("abc\n"))
+	require.NoError(t, err)
+
+	backups, err := file.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	rotated, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "12345678\n", string(rotated))
+	assert.Equal(t, "abc\n", string(current))
+}
+
+func TestRotatingFile_ShouldRotateByInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gin.log")
+	file, err := logging.NewRotatingFile(path, 0, time.Hour, 0)
+	require.NoError(t, err)
+	defer file.Close()
+	now := time.Now()
+	file.SetClock(func() time.Time { return now })
+
+	_, err = file.Write([]byte("first\n"))
+	require.NoError(t, err)
+	now = now.Add(30 * time.Minute)
+	_, err = file.Write([]byte("second\n"))
+	require.NoError(t, err)
+	backups, err := file.Backups()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	now = now.Add(2 * time.Hour)
+	_, err = file.Write([]byte("third\n"))
+	require.NoError(t, err)
+	backups, err = file.Backups()
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestRotatingFile_ShouldKeepWritingWhenRenameFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gin.log")
+	file, err := logging.NewRotatingFile(path, 10, 0, 0)
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("12345678\n"))
+	require.NoError(t, err)
+	// the open file is gone, so it cannot be renamed
+	require.NoError(t, os.Remove(path))
+	_, err = file.Write([]byte("abc\n"))
+	require.NoError(t, err)
+	_, err = file.Write([]byte("def\n"))
+	require.NoError(t, err)
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "abc\ndef\n", string(current))
+}
+
+func TestRotatingFile_ShouldKeepMaxBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gin.log")
+	file, err := logging.NewRotatingFile(path, 5, 0, 2)
+	require.NoError(t, err)
+	defer file.Close()
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		_, err = file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	backups, err := file.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	newest, err := os.ReadFile(backups[1])
+	require.NoError(t, err)
+	assert.Equal(t, "four\n", string(newest))
+}
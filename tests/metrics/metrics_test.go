@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fminister/co2monitor.api/metrics"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_ShouldExposeLatestReadingsPerLocation(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	registry, err := metrics.NewRegistry(f.Db)
+	require.NoError(t, err)
+
+	writer := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	metrics.HandlerFor(registry).ServeHTTP(writer, req)
+	body := writer.Body.String()
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Contains(t, body, fmt.Sprintf(`co2monitor_location_co2_ppm{location="%s"} %d`, tests.Locations[0].Name, tests.CO2[0].CO2))
+	assert.Contains(t, body, fmt.Sprintf(`co2monitor_location_co2_ppm{location="%s"} %d`, tests.Locations[1].Name, tests.CO2[2].CO2))
+	assert.Contains(t, body, fmt.Sprintf(`co2monitor_location_temperature_celsius{location="%s"}`, tests.Locations[0].Name))
+	assert.Contains(t, body, `co2monitor_location_last_seen_timestamp_seconds`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="co2monitor"}`)
+}
+
+func TestMetrics_ShouldCountRequestsAndIngestBatches(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	registry, err := metrics.NewRegistry(f.Db)
+	require.NoError(t, err)
+	api := tests.NewAPIEnv(f.Db)
+	router := gin.New()
+	router.Use(middleware.Metrics)
+	router.POST("/co2data/new", api.CreateCo2Data)
+	router.GET("/metrics", gin.WrapH(metrics.HandlerFor(registry)))
+
+	ingest := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/co2data/new", bytes.NewBuffer(tests.CO2ToJSON([]models.Co2Data{{LocationID: 1, CO2: 600, Temp: 21}})))
+	router.ServeHTTP(ingest, req)
+	writer := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+	router.ServeHTTP(writer, req)
+	body := writer.Body.String()
+
+	assert.Equal(t, http.StatusCreated, ingest.Code)
+	assert.Contains(t, body, `co2monitor_http_requests_total{method="POST",route="/co2data/new",status="201"} 1`)
+	assert.Contains(t, body, `co2monitor_http_request_duration_seconds_count{method="POST",route="/co2data/new"} 1`)
+	assert.Contains(t, body, `co2monitor_ingest_batch_size_bucket{le="1"}`)
+	assert.Contains(t, body, `co2monitor_ingested_readings_total{quality="ok"}`)
+}
+
+func TestMetrics_ShouldExposeOneReadingPerLocationAfterBatch(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	registry, err := metrics.NewRegistry(f.Db)
+	require.NoError(t, err)
+	api := tests.NewAPIEnv(f.Db)
+	router := gin.New()
+	router.POST("/co2data/new", api.CreateCo2Data)
+	router.GET("/metrics", gin.WrapH(metrics.HandlerFor(registry)))
+
+	ingest := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/co2data/new", bytes.NewBuffer(tests.CO2ToJSON([]models.Co2Data{{LocationID: 1, CO2: 600, Temp: 21}, {LocationID: 1, CO2: 610, Temp: 21}})))
+	router.ServeHTTP(ingest, req)
+	writer := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, http.StatusCreated, ingest.Code)
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Contains(t, writer.Body.String(), fmt.Sprintf(`co2monitor_location_co2_ppm{location="%s"} 610`, tests.Locations[0].Name))
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/logging"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs writes the default logger as JSON into the returned buffer
+// until the test ends.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	previous := log.Default()
+	logger := log.New(buffer)
+	logging.Configure(logger, buffer, config.LogConfig{Level: "debug", Format: config.LogFormatJSON})
+	log.SetDefault(logger)
+	t.Cleanup(func() { log.SetDefault(previous) })
+
+	return buffer
+}
+
+func logLines(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	lines := []map[string]interface{}{}
+	for _, raw := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		line := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(raw), &line))
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func TestAccessLog_ShouldLogRequestWithRequestId(t *testing.T) {
+	buffer := captureLogs(t)
+	router := gin.New()
+	router.Use(middleware.RequestID, middleware.AccessLog)
+	router.GET("/location/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "/location/1", nil)
+	require.NoError(t, err)
+	req.Header.Set(middleware.RequestIDHeader, "request-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := logLines(t, buffer)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "info", lines[0]["lvl"])
+	assert.Equal(t, "request-1", lines[0]["request_id"])
+	assert.Equal(t, "/location/:id", lines[0]["route"])
+	assert.Equal(t, "/location/1", lines[0]["path"])
+	assert.Equal(t, float64(http.StatusNoContent), lines[0]["status"])
+	assert.Contains(t, lines[0], "latency_ms")
+	assert.Contains(t, lines[0]["caller"], "access_log.go")
+}
+
+func TestRequestID_ShouldPutLoggerIntoRequestContext(t *testing.T) {
+	buffer := captureLogs(t)
+	router := gin.New()
+	router.Use(middleware.RequestID)
+	router.GET("/", func(c *gin.Context) {
+		log.FromContext(c.Request.Context()).Error("Could not cache the latest co2 data.")
+		c.Status(http.StatusNoContent)
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	req.Header.Set(middleware.RequestIDHeader, "request-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := logLines(t, buffer)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "request-1", lines[0]["request_id"])
+}
+
+func TestRequireApiKey_ShouldNotLogApiKey(t *testing.T) {
+	buffer := captureLogs(t)
+	router := tests.SetupMiddlewareRouter()
+
+	req, err := http.NewRequest(http.MethodPost, "/", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-API-KEY", "very-secret-key")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NotContains(t, buffer.String(), "very-secret-key")
+	assert.Contains(t, buffer.String(), logging.RedactKey("very-secret-key"))
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupErrorsRouter() *gin.Engine {
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.Use(middleware.Global(config.TracingConfig{})...)
+	router.NoRoute(middleware.NoRoute)
+	router.NoMethod(middleware.NoMethod)
+
+	router.GET("/problem", func(c *gin.Context) {
+		c.Error(problem.NotFound(problem.CodeLocationNotFound, "Could not find location by id.").WithCause(errors.New("record not found")))
+	})
+	router.GET("/error", func(c *gin.Context) {
+		c.Error(errors.New("connection refused"))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	return router
+}
+
+func serveProblem(t *testing.T, router *gin.Engine, method, path string, headers map[string]string) (*httptest.ResponseRecorder, models.ProblemDto) {
+	req, err := http.NewRequest(method, path, nil)
+	require.NoError(t, err)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	response := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	return w, response
+}
+
+func TestErrors_ShouldRenderProblem(t *testing.T) {
+	w, response := serveProblem(t, setupErrorsRouter(), http.MethodGet, "/problem", nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "about:blank", response.Type)
+	assert.Equal(t, "Not Found", response.Title)
+	assert.Equal(t, http.StatusNotFound, response.Status)
+	assert.Equal(t, "Could not find location by id.", response.Detail)
+	assert.Equal(t, "/problem", response.Instance)
+	assert.Equal(t, problem.CodeLocationNotFound, response.Code)
+	assert.Equal(t, w.Header().Get(middleware.RequestIDHeader), response.RequestID)
+	assert.NotContains(t, w.Body.String(), "record not found")
+}
+
+func TestErrors_ShouldHideUnknownErrors(t *testing.T) {
+	w, response := serveProblem(t, setupErrorsRouter(), http.MethodGet, "/error", nil)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, problem.CodeInternal, response.Code)
+	assert.Equal(t, "An unexpected error occurred.", response.Detail)
+	assert.NotContains(t, w.Body.String(), "connection refused")
+}
+
+func TestRecovery_ShouldRenderProblemOnPanic(t *testing.T) {
+	w, response := serveProblem(t, setupErrorsRouter(), http.MethodGet, "/panic", nil)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, problem.CodeInternal, response.Code)
+}
+
+func TestNoRoute_ShouldRenderProblem(t *testing.T) {
+	w, response := serveProblem(t, setupErrorsRouter(), http.MethodGet, "/unknown", nil)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problem.CodeRouteNotFound, response.Code)
+}
+
+func TestNoMethod_ShouldRenderProblem(t *testing.T) {
+	w, response := serveProblem(t, setupErrorsRouter(), http.MethodDelete, "/problem", nil)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, problem.CodeMethodNotAllowed, response.Code)
+}
+
+func TestRequestID_ShouldKeepValidIncomingId(t *testing.T) {
+	w, response := serveProblem(t, setupErrorsRouter(), http.MethodGet, "/problem", map[string]string{middleware.RequestIDHeader: "proxy-1234"})
+
+	assert.Equal(t, "proxy-1234", w.Header().Get(middleware.RequestIDHeader))
+	assert.Equal(t, "proxy-1234", response.RequestID)
+}
+
+func TestRequestID_ShouldReplaceInvalidIncomingId(t *testing.T) {
+	w, _ := serveProblem(t, setupErrorsRouter(), http.MethodGet, "/problem", map[string]string{middleware.RequestIDHeader: "<script>"})
+
+	assert.Regexp(t, `^[0-9a-f]{32}$`, w.Header().Get(middleware.RequestIDHeader))
+}
+
+func TestErrors_ShouldRenderCompressedProblem(t *testing.T) {
+	for _, path := range []string{"/problem", "/panic"} {
+		t.Run(path, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			setupErrorsRouter().ServeHTTP(w, req)
+
+			reader, err := gzip.NewReader(w.Body)
+			require.NoError(t, err)
+			response := models.ProblemDto{}
+			require.NoError(t, json.NewDecoder(reader).Decode(&response))
+
+			assert.NotEqual(t, http.StatusOK, w.Code)
+			assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, w.Code, response.Status)
+		})
+	}
+}
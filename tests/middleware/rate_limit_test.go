@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
+	"github.com/fminister/co2monitor.api/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, rate config.Rate) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func (failingStore) Close() error { return nil }
+
+func setupRateLimitRouter(store ratelimit.Store, identifyBy string) *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.RequestID, middleware.Errors)
+	router.POST("/co2data/new", middleware.RateLimit(store, "co2data", config.Rate{Requests: 2, Period: time.Minute}, identifyBy), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	return router
+}
+
+func postWithKey(router *gin.Engine, key string, ip string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/co2data/new", nil)
+	req.RemoteAddr = ip + ":40000"
+	if key != "" {
+		req.Header.Set("X-API-KEY", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestRateLimit_ShouldRejectWithRetryAfterWhenBucketIsEmpty(t *testing.T) {
+	router := setupRateLimitRouter(ratelimit.NewMemoryStore(), config.IdentifyByKey)
+
+	first := postWithKey(router, "sensor-key", "10.0.0.1")
+	postWithKey(router, "sensor-key", "10.0.0.1")
+	w := postWithKey(router, "sensor-key", "10.0.0.1")
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", first.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", first.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	errorResponse := models.ProblemDto{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, problem.CodeRateLimited, errorResponse.Code)
+	assert.Equal(t, "Too many requests. Retry in 30 seconds.", errorResponse.Detail)
+}
+
+// useKeys configures the keys of the tests as the api keys.
+func useKeys(t *testing.T) {
+	auth := config.Get().Auth
+	config.Get().Auth.APIKey = "sensor-key"
+	config.Get().Auth.AdminAPIKey = "dashboard-key"
+	t.Cleanup(func() { config.Get().Auth = auth })
+}
+
+func TestRateLimit_ShouldLimitEveryKeyOnItsOwn(t *testing.T) {
+	useKeys(t)
+	router := setupRateLimitRouter(ratelimit.NewMemoryStore(), config.IdentifyByKey)
+
+	postWithKey(router, "sensor-key", "10.0.0.1")
+	postWithKey(router, "sensor-key", "10.0.0.1")
+	limited := postWithKey(router, "sensor-key", "10.0.0.1")
+	otherKey := postWithKey(router, "dashboard-key", "10.0.0.1")
+	withoutKey := postWithKey(router, "", "10.0.0.1")
+
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, http.StatusCreated, otherKey.Code)
+	assert.Equal(t, http.StatusCreated, withoutKey.Code)
+}
+
+func TestRateLimit_ShouldLimitByIp(t *testing.T) {
+	router := setupRateLimitRouter(ratelimit.NewMemoryStore(), config.IdentifyByIP)
+
+	postWithKey(router, "sensor-key", "10.0.0.1")
+	postWithKey(router, "dashboard-key", "10.0.0.1")
+	limited := postWithKey(router, "other-key", "10.0.0.1")
+	otherIp := postWithKey(router, "sensor-key", "10.0.0.2")
+
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, http.StatusCreated, otherIp.Code)
+}
+
+func TestRateLimit_ShouldLetRequestsThroughWhenStoreFails(t *testing.T) {
+	router := setupRateLimitRouter(failingStore{}, config.IdentifyByKey)
+
+	w := postWithKey(router, "sensor-key", "10.0.0.1")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimit_ShouldLimitUnknownKeysByIp(t *testing.T) {
+	useKeys(t)
+	router := setupRateLimitRouter(ratelimit.NewMemoryStore(), config.IdentifyByKey)
+
+	postWithKey(router, "guess-1", "10.0.0.1")
+	postWithKey(router, "guess-2", "10.0.0.1")
+	limited := postWithKey(router, "guess-3", "10.0.0.1")
+	knownKey := postWithKey(router, "sensor-key", "10.0.0.1")
+
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, http.StatusCreated, knownKey.Code)
+}
+
+func TestRateLimit_ShouldLimitRequestsWithWrongKeyBeforeAuthentication(t *testing.T) {
+	useKeys(t)
+	router := gin.New()
+	router.Use(middleware.RequestID, middleware.Errors)
+	router.POST("/co2data/new", middleware.RateLimit(ratelimit.NewMemoryStore(), "co2data", config.Rate{Requests: 2, Period: time.Minute}, config.IdentifyByKey), middleware.RequireApiKey, func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	first := postWithKey(router, "wrong-key", "10.0.0.1")
+	postWithKey(router, "wrong-key", "10.0.0.1")
+	limited := postWithKey(router, "wrong-key", "10.0.0.1")
+
+	assert.Equal(t, http.StatusUnauthorized, first.Code)
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+}
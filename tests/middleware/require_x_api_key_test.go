@@ -1,11 +1,14 @@
 package tests
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/problem"
 	"github.com/fminister/co2monitor.api/tests"
 
 	"github.com/stretchr/testify/assert"
@@ -25,7 +28,7 @@ func TestRequireApiKey_UnauthorizedWithoutApiKey(t *testing.T) {
 func TestRequireApiKey_AuthorizedWithNormalApiKey(t *testing.T) {
 	router := tests.SetupMiddlewareRouter()
 	normalAPIKey := "YOUR_NORMAL_API_KEY"
-	os.Setenv("X_API_KEY", normalAPIKey)
+	config.Get().Auth.APIKey = normalAPIKey
 
 	req, err := http.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("X-API-KEY", normalAPIKey)
@@ -51,7 +54,7 @@ func TestRequireApiKey_UnauthorizedWithWrongApiKey(t *testing.T) {
 func TestRequireApiKey_AuthorizedWithAdminApiKeyForPostMethod(t *testing.T) {
 	router := tests.SetupMiddlewareRouter()
 	adminAPIKey := "YOUR_ADMIN_API_KEY"
-	os.Setenv("X_API_KEY_ADMIN", adminAPIKey)
+	config.Get().Auth.AdminAPIKey = adminAPIKey
 
 	req, err := http.NewRequest(http.MethodPost, "/", nil)
 	req.Header.Set("X-API-KEY", adminAPIKey)
@@ -65,7 +68,7 @@ func TestRequireApiKey_AuthorizedWithAdminApiKeyForPostMethod(t *testing.T) {
 func TestRequireApiKey_UnauthorizedWithNormalApiKeyForPostMethod(t *testing.T) {
 	router := tests.SetupMiddlewareRouter()
 	normalAPIKey := "YOUR_NORMAL_API_KEY"
-	os.Setenv("X_API_KEY", normalAPIKey)
+	config.Get().Auth.APIKey = normalAPIKey
 
 	req, err := http.NewRequest(http.MethodPost, "/", nil)
 	req.Header.Set("X-API-KEY", normalAPIKey)
@@ -79,7 +82,7 @@ func TestRequireApiKey_UnauthorizedWithNormalApiKeyForPostMethod(t *testing.T) {
 func TestRequireApiKey_AuthorizedWithAdminApiKeyForPatchMethod(t *testing.T) {
 	router := tests.SetupMiddlewareRouter()
 	adminAPIKey := "YOUR_ADMIN_API_KEY"
-	os.Setenv("X_API_KEY_ADMIN", adminAPIKey)
+	config.Get().Auth.AdminAPIKey = adminAPIKey
 
 	req, err := http.NewRequest(http.MethodPatch, "/", nil)
 	req.Header.Set("X-API-KEY", adminAPIKey)
@@ -93,7 +96,7 @@ func TestRequireApiKey_AuthorizedWithAdminApiKeyForPatchMethod(t *testing.T) {
 func TestRequireApiKey_UnauthorizedWithNormalApiKeyForPatchMethod(t *testing.T) {
 	router := tests.SetupMiddlewareRouter()
 	normalAPIKey := "YOUR_NORMAL_API_KEY"
-	os.Setenv("X_API_KEY", normalAPIKey)
+	config.Get().Auth.APIKey = normalAPIKey
 
 	req, err := http.NewRequest(http.MethodPatch, "/", nil)
 	req.Header.Set("X-API-KEY", normalAPIKey)
@@ -107,7 +110,7 @@ func TestRequireApiKey_UnauthorizedWithNormalApiKeyForPatchMethod(t *testing.T)
 func TestRequireApiKey_AuthorizedWithAdminApiKeyForDeleteMethod(t *testing.T) {
 	router := tests.SetupMiddlewareRouter()
 	adminAPIKey := "YOUR_ADMIN_API_KEY"
-	os.Setenv("X_API_KEY_ADMIN", adminAPIKey)
+	config.Get().Auth.AdminAPIKey = adminAPIKey
 
 	req, err := http.NewRequest(http.MethodDelete, "/", nil)
 	req.Header.Set("X-API-KEY", adminAPIKey)
@@ -121,7 +124,7 @@ func TestRequireApiKey_AuthorizedWithAdminApiKeyForDeleteMethod(t *testing.T) {
 func TestRequireApiKey_UnauthorizedWithNormalApiKeyForDeleteMethod(t *testing.T) {
 	router := tests.SetupMiddlewareRouter()
 	normalAPIKey := "YOUR_NORMAL_API_KEY"
-	os.Setenv("X_API_KEY", normalAPIKey)
+	config.Get().Auth.APIKey = normalAPIKey
 
 	req, err := http.NewRequest(http.MethodDelete, "/", nil)
 	req.Header.Set("X-API-KEY", normalAPIKey)
@@ -131,3 +134,45 @@ func TestRequireApiKey_UnauthorizedWithNormalApiKeyForDeleteMethod(t *testing.T)
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestRequireAdminApiKey_UnauthorizedWithNormalApiKeyForGetMethod(t *testing.T) {
+	router := tests.SetupMiddlewareRouter()
+	normalAPIKey := "YOUR_NORMAL_API_KEY"
+	config.Get().Auth.APIKey = normalAPIKey
+
+	req, err := http.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("X-API-KEY", normalAPIKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRequireAdminApiKey_AuthorizedWithAdminApiKeyForGetMethod(t *testing.T) {
+	router := tests.SetupMiddlewareRouter()
+	adminAPIKey := "YOUR_ADMIN_API_KEY"
+	config.Get().Auth.AdminAPIKey = adminAPIKey
+
+	req, err := http.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("X-API-KEY", adminAPIKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireApiKey_ShouldReturnProblem(t *testing.T) {
+	router := tests.SetupMiddlewareRouter()
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	response := models.ProblemDto{}
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, problem.CodeUnauthorized, response.Code)
+}
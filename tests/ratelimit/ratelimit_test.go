@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clockedStore interface {
+	ratelimit.Store
+	SetClock(now func() time.Time)
+}
+
+func newStores(t *testing.T) map[string]clockedStore {
+	server := miniredis.RunT(t)
+	redisStore := ratelimit.NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	t.Cleanup(func() { redisStore.Close() })
+
+	return map[string]clockedStore{
+		"memory": ratelimit.NewMemoryStore(),
+		"redis":  redisStore,
+	}
+}
+
+func TestStores_ShouldAllowBurstAndRefill(t *testing.T) {
+	rate := config.Rate{Requests: 3, Period: time.Minute}
+
+	for name, store := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+			store.SetClock(func() time.Time { return now })
+
+			for remaining := 2; remaining >= 0; remaining-- {
+				result, err := store.Take(ctx, "sensor", rate)
+				require.NoError(t, err)
+				assert.True(t, result.Allowed)
+				assert.Equal(t, remaining, result.Remaining)
+				assert.Equal(t, 3, result.Limit)
+			}
+
+			result, err := store.Take(ctx, "sensor", rate)
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Equal(t, 0, result.Remaining)
+			assert.Equal(t, 20*time.Second, result.RetryAfter)
+			assert.Equal(t, time.Minute, result.ResetAfter)
+
+			other, err := store.Take(ctx, "other", rate)
+			require.NoError(t, err)
+			assert.True(t, other.Allowed)
+
+			now = now.Add(20 * time.Second)
+			result, err = store.Take(ctx, "sensor", rate)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 0, result.Remaining)
+
+			now = now.Add(time.Hour)
+			result, err = store.Take(ctx, "sensor", rate)
+			require.NoError(t, err)
+			assert.Equal(t, 2, result.Remaining)
+		})
+	}
+}
+
+func TestMemoryStore_ShouldDropFullBuckets(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	store.SetClock(func() time.Time { return now })
+	rate := config.Rate{Requests: 10, Period: time.Second}
+
+	_, err := store.Take(context.Background(), "a", rate)
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = store.Take(context.Background(), "b", rate)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestRedisStore_ShouldReturnErrorServerDown(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := ratelimit.NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	defer store.Close()
+	server.Close()
+
+	_, err := store.Take(context.Background(), "sensor", config.Rate{Requests: 1, Period: time.Second})
+
+	assert.Error(t, err)
+}
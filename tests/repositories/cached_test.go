@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/cache"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newCachedRepositories(t *testing.T) (*repositories.MemoryStore, repositories.LocationRepository, repositories.Co2Repository, *cache.MemoryLatestStore) {
+	store := repositories.NewMemoryStore()
+	latest := cache.NewMemoryLatestStore(time.Minute)
+	_, err := store.Locations().Create([]models.Location{{Name: "Office"}})
+	require.NoError(t, err)
+
+	return store, repositories.NewCachedLocationRepository(store.Locations(), latest), repositories.NewCachedCo2Repository(store.Co2Data(), latest), latest
+}
+
+func TestCachedCo2Repository_ShouldReadThroughCache(t *testing.T) {
+	store, _, co2Data, latest := newCachedRepositories(t)
+	_, err := store.Co2Data().Create([]models.Co2Data{{LocationID: 1, CO2: 600}})
+	require.NoError(t, err)
+
+	reading, err := co2Data.GetLatest("1", false)
+	require.NoError(t, err)
+	assert.Equal(t, 600, reading.CO2)
+
+	cached, ok, err := latest.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, reading.ID, cached.ID)
+}
+
+func TestCachedCo2Repository_ShouldUpdateCacheOnIngest(t *testing.T) {
+	_, _, co2Data, _ := newCachedRepositories(t)
+	now := time.Now()
+	_, err := co2Data.Create([]models.Co2Data{{Model: gorm.Model{CreatedAt: now.Add(-time.Minute)}, LocationID: 1, CO2: 600}})
+	require.NoError(t, err)
+	_, err = co2Data.GetLatest("1", false)
+	require.NoError(t, err)
+
+	_, err = co2Data.Create([]models.Co2Data{
+		{Model: gorm.Model{CreatedAt: now}, LocationID: 1, CO2: 900},
+		{Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}, LocationID: 1, CO2: 400},
+	})
+	require.NoError(t, err)
+	reading, err := co2Data.GetLatest("1", false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 900, reading.CO2)
+}
+
+func TestCachedCo2Repository_ShouldKeepNewerCachedReading(t *testing.T) {
+	_, _, co2Data, _ := newCachedRepositories(t)
+	_, err := co2Data.Create([]models.Co2Data{{LocationID: 1, CO2: 600}})
+	require.NoError(t, err)
+	_, err = co2Data.GetLatest("1", false)
+	require.NoError(t, err)
+
+	_, err = co2Data.Create([]models.Co2Data{{Model: gorm.Model{CreatedAt: time.Now().Add(-time.Hour)}, LocationID: 1, CO2: 400}})
+	require.NoError(t, err)
+	reading, err := co2Data.GetLatest("1", false)
+
+	require.NoError(t, err)
+	assert.Equal(t, 600, reading.CO2)
+}
+
+func TestCachedCo2Repository_ShouldNotCacheReadingWithoutAnomalies(t *testing.T) {
+	_, _, co2Data, latest := newCachedRepositories(t)
+	_, err := co2Data.Create([]models.Co2Data{{LocationID: 1, CO2: 600}})
+	require.NoError(t, err)
+
+	_, err = co2Data.GetLatest("1", true)
+	require.NoError(t, err)
+	_, ok, err := latest.Get(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCachedLocationRepository_ShouldInvalidateCacheOnDelete(t *testing.T) {
+	_, locations, co2Data, latest := newCachedRepositories(t)
+	_, err := co2Data.Create([]models.Co2Data{{LocationID: 1, CO2: 600}})
+	require.NoError(t, err)
+	_, err = co2Data.GetLatest("1", false)
+	require.NoError(t, err)
+	location, err := locations.GetById("1")
+	require.NoError(t, err)
+
+	require.NoError(t, locations.Delete(location))
+	_, ok, err := latest.Get(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,295 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/repositories"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type repositorySet struct {
+	locations    repositories.LocationRepository
+	co2Data      repositories.Co2Repository
+	calibrations repositories.CalibrationRepository
+	quarantine   repositories.QuarantineRepository
+}
+
+// forEachRepository runs the test against the GORM and the in-memory
+// repositories, so both behave the same.
+func forEachRepository(t *testing.T, test func(t *testing.T, repos repositorySet)) {
+	t.Run("gorm", func(t *testing.T) {
+		f := tests.BaseFixture{}
+		f.Setup(t)
+		defer f.Teardown(t)
+
+		test(t, repositorySet{
+			locations:    repositories.NewGormLocationRepository(f.Db),
+			co2Data:      repositories.NewGormCo2Repository(f.Db, false),
+			calibrations: repositories.NewGormCalibrationRepository(f.Db),
+			quarantine:   repositories.NewGormQuarantineRepository(f.Db),
+		})
+	})
+	t.Run("memory", func(t *testing.T) {
+		store := repositories.NewMemoryStore()
+
+		test(t, repositorySet{
+			locations:    store.Locations(),
+			co2Data:      store.Co2Data(),
+			calibrations: store.Calibrations(),
+			quarantine:   store.Quarantine(),
+		})
+	})
+}
+
+func addLocations(t *testing.T, repos repositorySet) {
+	_, err := repos.locations.Create([]models.Location{{Name: "Office"}, {Name: "Kitchen"}})
+	require.NoError(t, err)
+}
+
+func TestLocationRepository_ShouldCreateAndFindLocations(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		addLocations(t, repos)
+
+		location, err := repos.locations.GetById("2")
+		require.NoError(t, err)
+		assert.Equal(t, "Kitchen", location.Name)
+
+		locations, err := repos.locations.Search("1", "Kitchen")
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(locations))
+
+		locations, err = repos.locations.GetAll()
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(locations))
+	})
+}
+
+func TestLocationRepository_ShouldReturnErrNotFound(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		_, err := repos.locations.GetById("99")
+		assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+		_, err = repos.locations.GetById("1 OR 1=1")
+		assert.ErrorIs(t, err, repositories.ErrNotFound)
+	})
+}
+
+func TestLocationRepository_ShouldRejectDuplicateName(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		addLocations(t, repos)
+
+		_, err := repos.locations.Create([]models.Location{{Name: "Office"}})
+		assert.ErrorIs(t, err, repositories.ErrDuplicate)
+
+		location, err := repos.locations.GetById("2")
+		require.NoError(t, err)
+		location.Name = "Office"
+		_, err = repos.locations.Update(location)
+		assert.ErrorIs(t, err, repositories.ErrDuplicate)
+	})
+}
+
+func TestLocationRepository_ShouldUpdateAndDeleteLocation(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		addLocations(t, repos)
+		location, err := repos.locations.GetById("1")
+		require.NoError(t, err)
+
+		location.Name = "Meeting Room"
+		_, err = repos.locations.Update(location)
+		require.NoError(t, err)
+		updated, err := repos.locations.GetById("1")
+		require.NoError(t, err)
+		assert.Equal(t, "Meeting Room", updated.Name)
+
+		require.NoError(t, repos.locations.Delete(updated))
+		_, err = repos.locations.GetById("1")
+		assert.ErrorIs(t, err, repositories.ErrNotFound)
+	})
+}
+
+func TestLocationRepository_ShouldGetByIdsOrNamesWithoutDeleted(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		addLocations(t, repos)
+		_, err := repos.locations.Create([]models.Location{{Name: "Basement"}})
+		require.NoError(t, err)
+		basement, err := repos.locations.GetById("3")
+		require.NoError(t, err)
+		require.NoError(t, repos.locations.Delete(basement))
+
+		locations, err := repos.locations.GetByIdsOrNames([]int{1, 3, 99}, []string{"Kitchen", "Basement"})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(locations))
+		assert.Equal(t, "Office", locations[0].Name)
+		assert.Equal(t, "Kitchen", locations[1].Name)
+	})
+}
+
+func TestCo2Repository_ShouldRejectUnknownLocation(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		_, err := repos.co2Data.Create([]models.Co2Data{{LocationID: 99, CO2: 600, Temp: 20}})
+
+		assert.ErrorIs(t, err, repositories.ErrInvalidReference)
+	})
+}
+
+func TestCalibrationRepository_ShouldRejectUnknownLocation(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		_, err := repos.calibrations.Create([]models.Calibration{{LocationID: 99, Co2Scale: 1, TempScale: 1, ValidFrom: time.Now()}})
+
+		assert.ErrorIs(t, err, repositories.ErrInvalidReference)
+	})
+}
+
+func TestCo2Repository_ShouldQueryCo2Data(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		addLocations(t, repos)
+		now := time.Now().UTC()
+		_, err := repos.co2Data.Create([]models.Co2Data{
+			{Model: gorm.Model{CreatedAt: now.Add(-3 * time.Hour)}, LocationID: 1, CO2: 500, Temp: 20},
+			{Model: gorm.Model{CreatedAt: now.Add(-20 * time.Minute)}, LocationID: 1, CO2: 600, Temp: 20},
+			{Model: gorm.Model{CreatedAt: now.Add(-10 * time.Minute)}, LocationID: 1, CO2: 12000, Temp: 20, Quality: models.QualityOutOfRange},
+			{Model: gorm.Model{CreatedAt: now.Add(-5 * time.Minute)}, LocationID: 2, CO2: 700, Temp: 21},
+		})
+		require.NoError(t, err)
+
+		co2Data, err := repos.co2Data.GetByTimeFrame("1", time.Hour, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(co2Data))
+		assert.Equal(t, 600, co2Data[0].CO2)
+
+		co2Data, err = repos.co2Data.GetByTimeFrame("1", time.Hour, true)
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(co2Data))
+
+		co2Data, err = repos.co2Data.GetByTimeRange("1", now.Add(-4*time.Hour), now.Add(-15*time.Minute), false)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(co2Data))
+		assert.Equal(t, 500, co2Data[0].CO2)
+
+		latest, err := repos.co2Data.GetLatest("1", false)
+		require.NoError(t, err)
+		assert.Equal(t, 12000, latest.CO2)
+
+		latest, err = repos.co2Data.GetLatest("1", true)
+		require.NoError(t, err)
+		assert.Equal(t, 600, latest.CO2)
+
+		perLocation, err := repos.co2Data.GetLatestPerLocation()
+		require.NoError(t, err)
+		require.Equal(t, 2, len(perLocation))
+		assert.Equal(t, 12000, perLocation[0].CO2)
+		assert.Equal(t, 700, perLocation[1].CO2)
+
+		anomalies, err := repos.co2Data.GetAnomalies("1", time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(anomalies))
+		assert.Equal(t, models.QualityOutOfRange, anomalies[0].Quality)
+
+		since, err := repos.co2Data.GetSince([]int{1, 2}, now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 3, len(since))
+
+		_, err = repos.co2Data.GetLatest("3", false)
+		assert.ErrorIs(t, err, repositories.ErrNotFound)
+	})
+}
+
+func TestCo2Repository_ShouldAggregateCo2Data(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		addLocations(t, repos)
+		base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+		_, err := repos.co2Data.Create([]models.Co2Data{
+			{Model: gorm.Model{CreatedAt: base.Add(10 * time.Minute)}, LocationID: 1, CO2: 600, Temp: 20},
+			{Model: gorm.Model{CreatedAt: base.Add(20 * time.Minute)}, LocationID: 1, CO2: 800, Temp: 22},
+			{Model: gorm.Model{CreatedAt: base.Add(70 * time.Minute)}, LocationID: 1, CO2: 1000, Temp: 23},
+		})
+		require.NoError(t, err)
+
+		aggregates, err := repos.co2Data.GetAggregates("1", models.AggregateHour, base.Add(30*time.Minute), false)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, len(aggregates))
+		assert.Equal(t, base, aggregates[0].Bucket)
+		assert.Equal(t, 700.0, aggregates[0].AvgCO2)
+		assert.Equal(t, 21.0, aggregates[0].AvgTemp)
+		assert.Equal(t, 2, aggregates[0].Count)
+		assert.Equal(t, 1000.0, aggregates[1].MaxCO2)
+
+		_, err = repos.co2Data.GetAggregates("1", "week", base, false)
+		assert.EqualError(t, err, `unknown aggregate interval "week"`)
+	})
+}
+
+func TestCalibrationRepository_ShouldReturnLatestValidFromFirst(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		addLocations(t, repos)
+		now := time.Now().UTC()
+		_, err := repos.calibrations.Create([]models.Calibration{
+			{LocationID: 1, Co2Offset: 10, Co2Scale: 1, TempScale: 1, ValidFrom: now.Add(-48 * time.Hour)},
+			{LocationID: 1, Co2Offset: 20, Co2Scale: 1, TempScale: 1, ValidFrom: now.Add(-time.Hour)},
+		})
+		require.NoError(t, err)
+
+		calibrations, err := repos.calibrations.GetByLocation("1")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(calibrations))
+		assert.Equal(t, float32(20), calibrations[0].Co2Offset)
+
+		require.NoError(t, repos.calibrations.Delete(calibrations[0]))
+		_, err = repos.calibrations.GetById("2")
+		assert.ErrorIs(t, err, repositories.ErrNotFound)
+	})
+}
+
+func TestQuarantineRepository_ShouldKeepPayloadAndReturnNewestFirst(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		_, err := repos.quarantine.Create([]models.QuarantinedCo2Data{
+			{Payload: []byte(`{"location_id":1,"co2":-5,"temp":21}`), Code: "quarantined", Errors: []models.FieldErrorDto{{Pointer: "/co2", Detail: "out of range"}}},
+			{Payload: []byte(`{"location_id":99,"co2":650,"temp":21}`), Code: "unknown_reference", ClientIP: "10.0.0.1"},
+		})
+		require.NoError(t, err)
+
+		quarantined, err := repos.quarantine.GetAll()
+		require.NoError(t, err)
+		require.Equal(t, 2, len(quarantined))
+		assert.Equal(t, "unknown_reference", quarantined[0].Code)
+		assert.Equal(t, "10.0.0.1", quarantined[0].ClientIP)
+
+		entry, err := repos.quarantine.GetById("1")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"location_id":1,"co2":-5,"temp":21}`, string(entry.Payload))
+		assert.Equal(t, []models.FieldErrorDto{{Pointer: "/co2", Detail: "out of range"}}, entry.Errors)
+
+		require.NoError(t, repos.quarantine.Delete(entry))
+		_, err = repos.quarantine.GetById("1")
+		assert.ErrorIs(t, err, repositories.ErrNotFound)
+	})
+}
+
+func TestQuarantineRepository_ShouldClaimEntryOnceAndRestoreIt(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repos repositorySet) {
+		_, err := repos.quarantine.Create([]models.QuarantinedCo2Data{
+			{Payload: []byte(`{"location_id":1,"co2":-5,"temp":21}`), Code: "quarantined"},
+			{Payload: []byte(`{"location_id":99,"co2":650,"temp":21}`), Code: "unknown_reference"},
+		})
+		require.NoError(t, err)
+		entry, err := repos.quarantine.GetById("1")
+		require.NoError(t, err)
+
+		require.NoError(t, repos.quarantine.Delete(entry))
+		assert.ErrorIs(t, repos.quarantine.Delete(entry), repositories.ErrNotFound)
+
+		require.NoError(t, repos.quarantine.Restore(entry))
+		quarantined, err := repos.quarantine.GetAll()
+		require.NoError(t, err)
+		require.Equal(t, 2, len(quarantined))
+		assert.Equal(t, "unknown_reference", quarantined[0].Code)
+		assert.Equal(t, "quarantined", quarantined[1].Code)
+	})
+}
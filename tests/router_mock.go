@@ -12,6 +12,7 @@ import (
 
 func SetupRouter(db *gorm.DB, method, route string, requestRoute string, handler gin.HandlerFunc, requestBody []byte) (*http.Request, *httptest.ResponseRecorder) {
 	router := gin.Default()
+	router.Use(middleware.RequestID, middleware.Errors)
 
 	switch method {
 	case http.MethodGet:
@@ -36,8 +37,29 @@ func SetupRouter(db *gorm.DB, method, route string, requestRoute string, handler
 	return req, writer
 }
 
+// SetupGetRouterWithHeaders sends a GET request with the headers, e.g. the
+// conditional ones, to a router which only serves the handler.
+func SetupGetRouterWithHeaders(route string, requestRoute string, handler gin.HandlerFunc, headers map[string]string) *httptest.ResponseRecorder {
+	router := gin.Default()
+	router.Use(middleware.RequestID, middleware.Errors)
+	router.GET(route, handler)
+
+	writer := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, requestRoute, nil)
+	if err != nil {
+		panic(err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	router.ServeHTTP(writer, req)
+
+	return writer
+}
+
 func SetupMiddlewareRouter() *gin.Engine {
 	router := gin.Default()
+	router.Use(middleware.RequestID, middleware.Errors)
 
 	router.GET("/", middleware.RequireApiKey, func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "Authorized through middleware"})
@@ -52,5 +74,9 @@ func SetupMiddlewareRouter() *gin.Engine {
 		c.JSON(http.StatusOK, gin.H{"message": "Authorized through middleware"})
 	})
 
+	router.GET("/admin", middleware.RequireAdminApiKey, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "Authorized through middleware"})
+	})
+
 	return router
 }
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() config.ServerConfig {
+	return config.ServerConfig{
+		ReadTimeout:     time.Second,
+		WriteTimeout:    5 * time.Second,
+		IdleTimeout:     time.Second,
+		ShutdownTimeout: 5 * time.Second,
+		MaxHeaderBytes:  1 << 20,
+	}
+}
+
+func TestServe_ShouldDrainInFlightRequestsBeforeStopping(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("stored"))
+	})
+	config := testConfig()
+	srv := server.New(handler, config)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := []string{}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(ctx, srv, listener, config,
+			func(ctx context.Context) error {
+				stopped = append(stopped, "workers")
+				return nil
+			},
+			func(ctx context.Context) error {
+				stopped = append(stopped, "database")
+				return nil
+			},
+		)
+	}()
+
+	responseBody := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responseBody <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		responseBody <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, "stored", <-responseBody)
+	assert.NoError(t, <-serveErr)
+	assert.Equal(t, []string{"workers", "database"}, stopped)
+
+	_, err = http.Get("http://" + listener.Addr().String())
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fminister/co2monitor.api/db"
+	"github.com/fminister/co2monitor.api/middleware"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/fminister/co2monitor.api/tracing"
+	"github.com/fminister/co2monitor.api/workers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	traceId      = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanId = "00f067aa0ba902b7"
+)
+
+// record installs a provider which keeps the finished spans in memory.
+func record(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.Install(tracing.NewProvider("co2monitor.api", sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { tracing.Install(noop.NewTracerProvider()) })
+
+	return exporter
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "no span named %s in %v", name, spans.Snapshots())
+
+	return tracetest.SpanStub{}
+}
+
+func attributeValue(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestTracing_ShouldContinueTraceOfRequestWithQuerySpans(t *testing.T) {
+	exporter := record(t)
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	require.NoError(t, f.Db.Use(&db.TracingPlugin{}))
+	router := gin.New()
+	router.Use(middleware.Tracing("co2monitor.api"), middleware.RequestID, middleware.Errors)
+	router.GET("/api/location/", tests.NewAPIEnv(f.Db).GetLocations)
+
+	writer := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/location/", nil)
+	req.Header.Set("traceparent", "00-"+traceId+"-"+parentSpanId+"-01")
+	router.ServeHTTP(writer, req)
+
+	require.Equal(t, http.StatusOK, writer.Code)
+	spans := exporter.GetSpans()
+	request := findSpan(t, spans, "/api/location/")
+	assert.Equal(t, traceId, request.SpanContext.TraceID().String())
+	assert.Equal(t, parentSpanId, request.Parent.SpanID().String())
+	assert.True(t, request.Parent.IsRemote())
+	query := findSpan(t, spans, "gorm.query")
+	assert.Equal(t, traceId, query.SpanContext.TraceID().String())
+	assert.Equal(t, request.SpanContext.SpanID(), query.Parent.SpanID())
+	assert.Equal(t, "locations", attributeValue(query, "db.sql.table").AsString())
+	assert.Contains(t, attributeValue(query, "db.statement").AsString(), "SELECT * FROM `locations`")
+}
+
+func TestTracing_ShouldSkipProbes(t *testing.T) {
+	exporter := record(t)
+	router := gin.New()
+	router.Use(middleware.Tracing("co2monitor.api"))
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	writer := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+	router.ServeHTTP(writer, req)
+
+	assert.Equal(t, http.StatusOK, writer.Code)
+	assert.Empty(t, exporter.GetSpans())
+}
+
+func TestTracing_ShouldRecordFailedQueries(t *testing.T) {
+	exporter := record(t)
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	require.NoError(t, f.Db.Use(&db.TracingPlugin{}))
+	f.CloseDb(t)
+
+	err := f.Db.Exec("SELECT 1").Error
+
+	require.Error(t, err)
+	span := findSpan(t, exporter.GetSpans(), "gorm.raw")
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Len(t, span.Events, 1)
+}
+
+func TestTracing_ShouldTraceHeartbeatCheck(t *testing.T) {
+	exporter := record(t)
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	require.NoError(t, f.Db.Use(&db.TracingPlugin{}))
+	watcher := workers.NewHeartbeatWatcher(f.Db, 0, 0)
+
+	require.NoError(t, watcher.Check())
+
+	spans := exporter.GetSpans()
+	check := findSpan(t, spans, "heartbeat.check")
+	assert.False(t, check.Parent.IsValid())
+	assert.Equal(t, int64(2), attributeValue(check, "heartbeat.locations").AsInt64())
+	queries := 0
+	for _, span := range spans {
+		if span.Name != check.Name {
+			assert.Equal(t, check.SpanContext.SpanID(), span.Parent.SpanID(), span.Name)
+			queries++
+		}
+	}
+	assert.GreaterOrEqual(t, queries, 2)
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tests"
+	"github.com/fminister/co2monitor.api/workers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func addRecentReading(f *tests.BaseFixture, locationId int, age time.Duration) {
+	createdAt := time.Now().Add(-age)
+	f.Db.Create(&models.Co2Data{
+		Model:      gorm.Model{CreatedAt: createdAt, UpdatedAt: createdAt},
+		LocationID: locationId,
+		CO2:        600,
+		Temp:       21,
+	})
+}
+
+func TestHeartbeatWatcher_ShouldMarkSilentLocationsOffline(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	addRecentReading(&f, 1, time.Minute)
+	watcher := workers.NewHeartbeatWatcher(f.Db, 15*time.Minute, time.Minute)
+	events := watcher.Subscribe()
+
+	err := watcher.Check()
+	statuses := watcher.Statuses()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(statuses))
+	assert.Equal(t, models.StatusOnline, statuses[0].Status)
+	assert.Equal(t, tests.Locations[0].Name, statuses[0].Name)
+	assert.Equal(t, models.StatusOffline, statuses[1].Status)
+	assert.Equal(t, tests.CO2[2].CreatedAt.Unix(), statuses[1].LastSeen.Unix())
+	event := <-events
+	assert.Equal(t, uint(2), event.LocationID)
+	assert.Equal(t, models.StatusOffline, event.Status)
+	assert.Equal(t, 0, len(events))
+}
+
+func TestHeartbeatWatcher_ShouldRaiseEventWhenLocationComesBackOnline(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	watcher := workers.NewHeartbeatWatcher(f.Db, 15*time.Minute, time.Minute)
+	require.NoError(t, watcher.Check())
+	events := watcher.Subscribe()
+
+	watcher.Touch(1, time.Now())
+	statuses := watcher.Statuses()
+
+	assert.Equal(t, models.StatusOnline, statuses[0].Status)
+	event := <-events
+	assert.Equal(t, uint(1), event.LocationID)
+	assert.Equal(t, models.StatusOnline, event.Status)
+
+	require.NoError(t, watcher.Check())
+	assert.Equal(t, models.StatusOnline, watcher.Statuses()[0].Status)
+	assert.Equal(t, 0, len(events))
+}
+
+func TestHeartbeatWatcher_ShouldRaiseEventWhenLocationGoesOffline(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	addRecentReading(&f, 1, 10*time.Minute)
+	watcher := workers.NewHeartbeatWatcher(f.Db, 15*time.Minute, time.Minute)
+	require.NoError(t, watcher.Check())
+	events := watcher.Subscribe()
+
+	silentWatcher := workers.NewHeartbeatWatcher(f.Db, 5*time.Minute, time.Minute)
+	require.NoError(t, silentWatcher.Check())
+
+	assert.Equal(t, models.StatusOnline, watcher.Statuses()[0].Status)
+	assert.Equal(t, models.StatusOffline, silentWatcher.Statuses()[0].Status)
+	assert.Equal(t, 0, len(events))
+}
+
+func TestHeartbeatWatcher_ShouldRunUntilStopped(t *testing.T) {
+	f := tests.BaseFixture{}
+	f.Setup(t)
+	f.AddDummyData(t)
+	defer f.Teardown(t)
+	watcher := workers.NewHeartbeatWatcher(f.Db, 15*time.Minute, 10*time.Millisecond)
+	events := watcher.Subscribe()
+
+	watcher.Start(context.Background())
+	<-events
+	running := watcher.Running()
+	watcher.Stop()
+
+	assert.True(t, running)
+	assert.False(t, watcher.Running())
+	assert.Equal(t, 2, len(watcher.Statuses()))
+}
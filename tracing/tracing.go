@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/fminister/co2monitor.api/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans of this module.
+const ScopeName = "github.com/fminister/co2monitor.api"
+
+// Propagator reads and writes the W3C trace context and baggage headers.
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Setup exports the spans to the OTLP/HTTP collector if tracing is enabled.
+// Without tracing the global tracer provider is kept, which does not record
+// anything. The returned function flushes the remaining spans on shutdown.
+//
+// The exporter also reads the standard OTEL_EXPORTER_OTLP_* variables, e.g.
+// for headers, and the sampler is set with OTEL_TRACES_SAMPLER.
+func Setup(ctx context.Context, tracingConfig config.TracingConfig) (func(context.Context) error, error) {
+	if !tracingConfig.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracehttp.Option{}
+	if tracingConfig.Endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpointURL(tracingConfig.Endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(tracingConfig.ServiceName, sdktrace.WithBatcher(exporter))
+	Install(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider for the service. Tests pass an
+// in-memory exporter with sdktrace.WithSyncer.
+func NewProvider(serviceName string, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	service := resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))
+
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(service)}, options...)...)
+}
+
+// Install makes the provider and the propagator global, the middleware, the
+// database plugin and the workers pick them up from there.
+func Install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator)
+}
+
+// Tracer returns the tracer of this module from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(ScopeName)
+}
@@ -0,0 +1,275 @@
+package workers
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm"
+
+	"github.com/fminister/co2monitor.api/config"
+	"github.com/fminister/co2monitor.api/db/db_calls"
+	"github.com/fminister/co2monitor.api/models"
+	"github.com/fminister/co2monitor.api/tracing"
+)
+
+const (
+	subscriberChannelSize = 16
+)
+
+// StatusEvent is published whenever a location goes online or offline.
+type StatusEvent struct {
+	LocationID uint
+	Name       string
+	Status     string
+	LastSeen   *time.Time
+	At         time.Time
+}
+
+// HeartbeatWatcher tracks the last reading of every location and marks
+// locations as offline if they have been silent for longer than the silence
+// window.
+type HeartbeatWatcher struct {
+	db            *gorm.DB
+	silenceWindow time.Duration
+	interval      time.Duration
+
+	mu          sync.RWMutex
+	statuses    map[uint]models.LocationStatusDto
+	subscribers []chan StatusEvent
+
+	running atomic.Bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+var heartbeat *HeartbeatWatcher
+
+func NewHeartbeatWatcher(db *gorm.DB, silenceWindow time.Duration, interval time.Duration) *HeartbeatWatcher {
+	return &HeartbeatWatcher{
+		db:            db,
+		silenceWindow: silenceWindow,
+		interval:      interval,
+		statuses:      map[uint]models.LocationStatusDto{},
+	}
+}
+
+// StartHeartbeatWatcher starts the global watcher with the configured silence
+// window and check interval.
+func StartHeartbeatWatcher(ctx context.Context, db *gorm.DB) {
+	heartbeat = NewHeartbeatWatcher(
+		db,
+		config.Get().Heartbeat.SilenceWindow,
+		config.Get().Heartbeat.CheckInterval,
+	)
+	heartbeat.Start(ctx)
+}
+
+func GetHeartbeatWatcher() *HeartbeatWatcher {
+	return heartbeat
+}
+
+// Start checks the locations in the background until Stop is called or the context is done.
+func (w *HeartbeatWatcher) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	w.running.Store(true)
+
+	go func() {
+		defer close(w.done)
+		defer w.running.Store(false)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.Check(); err != nil {
+				log.Errorf(`Could not check heartbeat of locations. Error: <%s>`, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Infof(`Started heartbeat watcher. Silence window: <%s>; Interval: <%s>`, w.silenceWindow, w.interval)
+}
+
+// Stop stops the background checks and waits until the last check is done.
+func (w *HeartbeatWatcher) Stop() {
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	<-w.done
+	w.closeSubscribers()
+	log.Info("Stopped heartbeat watcher.")
+}
+
+func (w *HeartbeatWatcher) Running() bool {
+	return w.running.Load()
+}
+
+// Check loads the last reading of every location and updates their status.
+// Every check is traced as a root span with its queries as children.
+func (w *HeartbeatWatcher) Check() (err error) {
+	ctx, span := tracing.Tracer().Start(context.Background(), "heartbeat.check")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	locations, err := db_calls.GetLocation(w.db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	latest, err := db_calls.GetLatestCo2DataPerLocation(w.db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	lastSeen := map[uint]time.Time{}
+	for _, data := range latest {
+		lastSeen[uint(data.LocationID)] = data.CreatedAt
+	}
+
+	now := time.Now()
+	statuses := map[uint]models.LocationStatusDto{}
+	events := []StatusEvent{}
+
+	w.mu.Lock()
+	for _, location := range locations {
+		previous, known := w.statuses[location.ID]
+
+		status := models.LocationStatusDto{
+			LocationID: location.ID,
+			Name:       location.Name,
+			Status:     models.StatusOffline,
+		}
+		if seen, ok := lastSeen[location.ID]; ok {
+			status.LastSeen = &seen
+		}
+		// readings reported by Touch can be newer than the ones in the db
+		if known && previous.LastSeen != nil && (status.LastSeen == nil || previous.LastSeen.After(*status.LastSeen)) {
+			status.LastSeen = previous.LastSeen
+		}
+		if status.LastSeen != nil && now.Sub(*status.LastSeen) <= w.silenceWindow {
+			status.Status = models.StatusOnline
+		}
+
+		if (known && previous.Status != status.Status) || (!known && status.Status == models.StatusOffline) {
+			events = append(events, newStatusEvent(status, now))
+		}
+		statuses[location.ID] = status
+	}
+	w.statuses = statuses
+	w.mu.Unlock()
+
+	span.SetAttributes(attribute.Int("heartbeat.locations", len(locations)), attribute.Int("heartbeat.status_changes", len(events)))
+	w.publish(events)
+
+	return nil
+}
+
+// Touch marks a location as online right away when it reports a reading.
+func (w *HeartbeatWatcher) Touch(locationId uint, at time.Time) {
+	now := time.Now()
+	if now.Sub(at) > w.silenceWindow {
+		return
+	}
+
+	w.mu.Lock()
+	status, known := w.statuses[locationId]
+	if known && status.LastSeen != nil && status.LastSeen.After(at) {
+		w.mu.Unlock()
+		return
+	}
+	wasOnline := status.Status == models.StatusOnline
+	status.LocationID = locationId
+	status.LastSeen = &at
+	status.Status = models.StatusOnline
+	w.statuses[locationId] = status
+	w.mu.Unlock()
+
+	if known && !wasOnline {
+		w.publish([]StatusEvent{newStatusEvent(status, now)})
+	}
+}
+
+// Statuses returns the status of all locations ordered by their id.
+func (w *HeartbeatWatcher) Statuses() []models.LocationStatusDto {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	statuses := make([]models.LocationStatusDto, 0, len(w.statuses))
+	for _, status := range w.statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].LocationID < statuses[j].LocationID
+	})
+
+	return statuses
+}
+
+// Subscribe returns a channel which receives every status change. Events are
+// dropped if the consumer does not keep up. The channel is closed on Stop.
+func (w *HeartbeatWatcher) Subscribe() <-chan StatusEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	subscriber := make(chan StatusEvent, subscriberChannelSize)
+	w.subscribers = append(w.subscribers, subscriber)
+
+	return subscriber
+}
+
+func (w *HeartbeatWatcher) publish(events []StatusEvent) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for _, event := range events {
+		log.Warnf(`Location changed status. LocationId: <%d>; Name: <%s>; Status: <%s>`, event.LocationID, event.Name, event.Status)
+
+		for _, subscriber := range w.subscribers {
+			select {
+			case subscriber <- event:
+			default:
+				log.Errorf(`Dropped heartbeat event, subscriber is too slow. LocationId: <%d>; Status: <%s>`, event.LocationID, event.Status)
+			}
+		}
+	}
+}
+
+func (w *HeartbeatWatcher) closeSubscribers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, subscriber := range w.subscribers {
+		close(subscriber)
+	}
+	w.subscribers = nil
+}
+
+func newStatusEvent(status models.LocationStatusDto, at time.Time) StatusEvent {
+	return StatusEvent{
+		LocationID: status.LocationID,
+		Name:       status.Name,
+		Status:     status.Status,
+		LastSeen:   status.LastSeen,
+		At:         at,
+	}
+}